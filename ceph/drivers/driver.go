@@ -2,24 +2,37 @@ package drivers
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/teran/ceph-chaos-monkey/ceph"
 )
 
+// ErrNotFound is returned when the requested entity doesn't exist
+var ErrNotFound = errors.New("not found")
+
 type Cluster interface {
 	GetHealth(ctx context.Context) (ceph.Health, error)
+	GetFSID(ctx context.Context) (string, error)
 
 	RemoveMonitor(ctx context.Context, name string) error
 
 	GetOSDs(ctx context.Context) ([]ceph.OSD, error)
 	GetOSDIDs(ctx context.Context) ([]uint64, error)
+	GetOSDTree(ctx context.Context) ([]ceph.OSDTreeNode, error)
 	GetMons(ctx context.Context) ([]ceph.Mon, error)
+	GetMonQuorum(ctx context.Context) ([]string, error)
 
 	DestroyOSD(ctx context.Context, id uint64) error
 	StopOSDDaemon(ctx context.Context, id uint64) error
+	SetOSDReweight(ctx context.Context, id uint64, weight float64) error
+	SetPrimaryAffinity(ctx context.Context, id uint64, weight float64) error
+	SetOSDCrushWeight(ctx context.Context, id uint64, weight float64) error
+	InjectDataError(ctx context.Context, id uint64, pool, objectName string) error
 
 	SetFlag(ctx context.Context, flag ceph.Flag) error
 	UnsetFlag(ctx context.Context, flag ceph.Flag) error
+	GetFlags(ctx context.Context) ([]ceph.Flag, error)
 	SetGroupFlag(ctx context.Context, flag ceph.Flag, group ...string) error
 	UnsetGroupFlag(ctx context.Context, flag ceph.Flag, group ...string) error
 
@@ -27,10 +40,17 @@ type Cluster interface {
 	CreateDefaultPool(ctx context.Context, name string) error
 	ResizePool(ctx context.Context, name string, size uint64) error
 	ChangePoolPGNum(ctx context.Context, name string, pgs uint64) error
+	EnablePoolApplication(ctx context.Context, name, app string) error
+	DeletePool(ctx context.Context, name string) error
+
+	ListCrushRules(ctx context.Context) ([]string, error)
+	RemoveCrushRule(ctx context.Context, name string) error
 	ReweightByUtilization(ctx context.Context) error
 
 	CreateRADOSObject(ctx context.Context, pool, objectName string, data []byte) error
 	ReadRADOSObject(ctx context.Context, pool, objectName string) ([]byte, error)
+	DeleteRADOSObject(ctx context.Context, pool, objectName string) error
+	MapObject(ctx context.Context, pool, objectName string) (ceph.ObjectMapping, error)
 	ListRADOSObjects(ctx context.Context, pool string) ([]string, error)
 
 	SetNearFullRatio(ctx context.Context, value float64) error
@@ -42,4 +62,67 @@ type Cluster interface {
 
 	ListPGs(ctx context.Context) ([]ceph.PGStat, error)
 	DeepScrubPG(ctx context.Context, target string) error
+
+	GetPGUpmapItems(ctx context.Context) ([]ceph.PGUpmapItems, error)
+	SetPGUpmapItems(ctx context.Context, pgid string, mappings ...ceph.UpmapMapping) error
+	RemovePGUpmapItems(ctx context.Context, pgid string) error
+
+	GetBalancerStatus(ctx context.Context) (ceph.BalancerStatus, error)
+	SetBalancerMode(ctx context.Context, mode ceph.BalancerMode) error
+	EnableBalancer(ctx context.Context) error
+	DisableBalancer(ctx context.Context) error
+
+	GetFilesystems(ctx context.Context) ([]ceph.Filesystem, error)
+	GetMDSDaemons(ctx context.Context) ([]ceph.MDS, error)
+	FailMDS(ctx context.Context, name string) error
+	SetMaxMDS(ctx context.Context, fs string, n uint64) error
+	SetFilesystemJoinable(ctx context.Context, fs string, joinable bool) error
+	SetFilesystemDown(ctx context.Context, fs string, down bool) error
+
+	ListRGWDaemons(ctx context.Context) ([]ceph.Daemon, error)
+	StopDaemon(ctx context.Context, name string) error
+	StartDaemon(ctx context.Context, name string) error
+	RestartDaemon(ctx context.Context, name string) error
+	ListBuckets(ctx context.Context) ([]string, error)
+	SetBucketQuota(ctx context.Context, bucket string, maxObjects, maxSizeBytes int64) error
+	ListZones(ctx context.Context) ([]string, error)
+	SetZoneReadOnly(ctx context.Context, zone string, readOnly bool) error
+
+	ListRBDImages(ctx context.Context, pool string) ([]string, error)
+	GetRBDImage(ctx context.Context, pool, image string) (ceph.RBDImage, error)
+	CreateRBDImage(ctx context.Context, pool, image string, sizeMiB uint64) error
+	ResizeRBDImage(ctx context.Context, pool, image string, sizeMiB uint64) error
+	FlattenRBDImage(ctx context.Context, pool, image string) error
+	CloneRBDImage(ctx context.Context, pool, image, snapshot, clone string) error
+	WriteRBDImage(ctx context.Context, pool, image string, offset uint64, data []byte) error
+	ExportRBDImage(ctx context.Context, pool, image string) ([]byte, error)
+	ListRBDSnapshots(ctx context.Context, pool, image string) ([]ceph.RBDSnapshot, error)
+	CreateRBDSnapshot(ctx context.Context, pool, image, snapshot string) error
+	ProtectRBDSnapshot(ctx context.Context, pool, image, snapshot string) error
+	RemoveRBDSnapshot(ctx context.Context, pool, image, snapshot string) error
+	LockRBDImage(ctx context.Context, pool, image, lockID string) error
+	UnlockRBDImage(ctx context.Context, pool, image, lockID string) error
+	ListRBDWatchers(ctx context.Context, pool, image string) ([]ceph.RBDWatcher, error)
+
+	GetConfig(ctx context.Context, who, name string) (string, error)
+	SetConfig(ctx context.Context, who, name, value string) error
+	RemoveConfig(ctx context.Context, who, name string) error
+	DumpConfig(ctx context.Context) ([]ceph.ConfigOption, error)
+	GetConfigKey(ctx context.Context, key string) (string, error)
+
+	ListClientSessions(ctx context.Context) ([]ceph.ClientSession, error)
+	BlocklistAdd(ctx context.Context, addr string, expire time.Duration) error
+	BlocklistRemove(ctx context.Context, addr string) error
+	ListBlocklist(ctx context.Context) ([]ceph.BlocklistEntry, error)
+
+	GetAuth(ctx context.Context, entity string) (ceph.AuthEntity, error)
+	ListAuth(ctx context.Context) ([]ceph.AuthEntity, error)
+	CreateAuth(ctx context.Context, entity string, caps map[string]string) (ceph.AuthEntity, error)
+	DeleteAuth(ctx context.Context, entity string) error
+	SetAuthCaps(ctx context.Context, entity string, caps map[string]string) error
+
+	// AsClient returns the Cluster instance performing all the operations on
+	// behalf of the specified client and the function removing the client
+	// credentials stored for it
+	AsClient(ctx context.Context, entity ceph.AuthEntity) (Cluster, func(ctx context.Context) error, error)
 }
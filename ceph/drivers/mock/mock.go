@@ -2,6 +2,7 @@ package mock
 
 import (
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/teran/ceph-chaos-monkey/ceph"
@@ -23,6 +24,11 @@ func (m *Mock) GetHealth(context.Context) (ceph.Health, error) {
 	return args.Get(0).(ceph.Health), args.Error(1)
 }
 
+func (m *Mock) GetFSID(context.Context) (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
 func (m *Mock) GetOSDs(context.Context) ([]ceph.OSD, error) {
 	args := m.Called()
 	return args.Get(0).([]ceph.OSD), args.Error(1)
@@ -33,11 +39,21 @@ func (m *Mock) GetOSDIDs(ctx context.Context) ([]uint64, error) {
 	return args.Get(0).([]uint64), args.Error(1)
 }
 
+func (m *Mock) GetOSDTree(context.Context) ([]ceph.OSDTreeNode, error) {
+	args := m.Called()
+	return args.Get(0).([]ceph.OSDTreeNode), args.Error(1)
+}
+
 func (m *Mock) GetMons(context.Context) ([]ceph.Mon, error) {
 	args := m.Called()
 	return args.Get(0).([]ceph.Mon), args.Error(1)
 }
 
+func (m *Mock) GetMonQuorum(context.Context) ([]string, error) {
+	args := m.Called()
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func (m *Mock) DestroyOSD(_ context.Context, id uint64) error {
 	args := m.Called(id)
 	return args.Error(0)
@@ -48,6 +64,26 @@ func (m *Mock) StopOSDDaemon(_ context.Context, id uint64) error {
 	return args.Error(0)
 }
 
+func (m *Mock) SetOSDReweight(_ context.Context, id uint64, weight float64) error {
+	args := m.Called(id, weight)
+	return args.Error(0)
+}
+
+func (m *Mock) SetPrimaryAffinity(_ context.Context, id uint64, weight float64) error {
+	args := m.Called(id, weight)
+	return args.Error(0)
+}
+
+func (m *Mock) SetOSDCrushWeight(_ context.Context, id uint64, weight float64) error {
+	args := m.Called(id, weight)
+	return args.Error(0)
+}
+
+func (m *Mock) InjectDataError(_ context.Context, id uint64, pool, objectName string) error {
+	args := m.Called(id, pool, objectName)
+	return args.Error(0)
+}
+
 func (m *Mock) SetFlag(_ context.Context, flag ceph.Flag) error {
 	args := m.Called(flag)
 	return args.Error(0)
@@ -58,6 +94,11 @@ func (m *Mock) UnsetFlag(_ context.Context, flag ceph.Flag) error {
 	return args.Error(0)
 }
 
+func (m *Mock) GetFlags(context.Context) ([]ceph.Flag, error) {
+	args := m.Called()
+	return args.Get(0).([]ceph.Flag), args.Error(1)
+}
+
 func (m *Mock) SetGroupFlag(_ context.Context, flag ceph.Flag, group ...string) error {
 	args := m.Called(flag, group)
 	return args.Error(0)
@@ -93,6 +134,26 @@ func (m *Mock) CreateDefaultPool(ctx context.Context, name string) error {
 	return args.Error(0)
 }
 
+func (m *Mock) EnablePoolApplication(_ context.Context, name, app string) error {
+	args := m.Called(name, app)
+	return args.Error(0)
+}
+
+func (m *Mock) DeletePool(_ context.Context, name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *Mock) ListCrushRules(context.Context) ([]string, error) {
+	args := m.Called()
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *Mock) RemoveCrushRule(_ context.Context, name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
 func (m *Mock) CreateRADOSObject(ctx context.Context, pool, objectName string, data []byte) error {
 	args := m.Called(pool, objectName, data)
 	return args.Error(0)
@@ -103,6 +164,16 @@ func (m *Mock) ReadRADOSObject(_ context.Context, pool, objectName string) ([]by
 	return args.Get(0).([]byte), args.Error(1)
 }
 
+func (m *Mock) DeleteRADOSObject(_ context.Context, pool, objectName string) error {
+	args := m.Called(pool, objectName)
+	return args.Error(0)
+}
+
+func (m *Mock) MapObject(_ context.Context, pool, objectName string) (ceph.ObjectMapping, error) {
+	args := m.Called(pool, objectName)
+	return args.Get(0).(ceph.ObjectMapping), args.Error(1)
+}
+
 func (m *Mock) ListRADOSObjects(_ context.Context, pool string) ([]string, error) {
 	args := m.Called(pool)
 	return args.Get(0).([]string), args.Error(1)
@@ -147,3 +218,258 @@ func (m *Mock) DeepScrubPG(_ context.Context, target string) error {
 	args := m.Called(target)
 	return args.Error(0)
 }
+
+func (m *Mock) GetPGUpmapItems(context.Context) ([]ceph.PGUpmapItems, error) {
+	args := m.Called()
+	return args.Get(0).([]ceph.PGUpmapItems), args.Error(1)
+}
+
+func (m *Mock) SetPGUpmapItems(_ context.Context, pgid string, mappings ...ceph.UpmapMapping) error {
+	args := m.Called(pgid, mappings)
+	return args.Error(0)
+}
+
+func (m *Mock) RemovePGUpmapItems(_ context.Context, pgid string) error {
+	args := m.Called(pgid)
+	return args.Error(0)
+}
+
+func (m *Mock) GetBalancerStatus(context.Context) (ceph.BalancerStatus, error) {
+	args := m.Called()
+	return args.Get(0).(ceph.BalancerStatus), args.Error(1)
+}
+
+func (m *Mock) SetBalancerMode(_ context.Context, mode ceph.BalancerMode) error {
+	args := m.Called(mode)
+	return args.Error(0)
+}
+
+func (m *Mock) EnableBalancer(context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *Mock) DisableBalancer(context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *Mock) GetFilesystems(context.Context) ([]ceph.Filesystem, error) {
+	args := m.Called()
+	return args.Get(0).([]ceph.Filesystem), args.Error(1)
+}
+
+func (m *Mock) GetMDSDaemons(context.Context) ([]ceph.MDS, error) {
+	args := m.Called()
+	return args.Get(0).([]ceph.MDS), args.Error(1)
+}
+
+func (m *Mock) FailMDS(_ context.Context, name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *Mock) SetMaxMDS(_ context.Context, fs string, n uint64) error {
+	args := m.Called(fs, n)
+	return args.Error(0)
+}
+
+func (m *Mock) SetFilesystemJoinable(_ context.Context, fs string, joinable bool) error {
+	args := m.Called(fs, joinable)
+	return args.Error(0)
+}
+
+func (m *Mock) SetFilesystemDown(_ context.Context, fs string, down bool) error {
+	args := m.Called(fs, down)
+	return args.Error(0)
+}
+
+func (m *Mock) ListRGWDaemons(context.Context) ([]ceph.Daemon, error) {
+	args := m.Called()
+	return args.Get(0).([]ceph.Daemon), args.Error(1)
+}
+
+func (m *Mock) StopDaemon(_ context.Context, name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *Mock) StartDaemon(_ context.Context, name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *Mock) RestartDaemon(_ context.Context, name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *Mock) ListBuckets(context.Context) ([]string, error) {
+	args := m.Called()
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *Mock) SetBucketQuota(_ context.Context, bucket string, maxObjects, maxSizeBytes int64) error {
+	args := m.Called(bucket, maxObjects, maxSizeBytes)
+	return args.Error(0)
+}
+
+func (m *Mock) ListZones(context.Context) ([]string, error) {
+	args := m.Called()
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *Mock) SetZoneReadOnly(_ context.Context, zone string, readOnly bool) error {
+	args := m.Called(zone, readOnly)
+	return args.Error(0)
+}
+
+func (m *Mock) ListRBDImages(_ context.Context, pool string) ([]string, error) {
+	args := m.Called(pool)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *Mock) GetRBDImage(_ context.Context, pool, image string) (ceph.RBDImage, error) {
+	args := m.Called(pool, image)
+	return args.Get(0).(ceph.RBDImage), args.Error(1)
+}
+
+func (m *Mock) CreateRBDImage(_ context.Context, pool, image string, sizeMiB uint64) error {
+	args := m.Called(pool, image, sizeMiB)
+	return args.Error(0)
+}
+
+func (m *Mock) ResizeRBDImage(_ context.Context, pool, image string, sizeMiB uint64) error {
+	args := m.Called(pool, image, sizeMiB)
+	return args.Error(0)
+}
+
+func (m *Mock) FlattenRBDImage(_ context.Context, pool, image string) error {
+	args := m.Called(pool, image)
+	return args.Error(0)
+}
+
+func (m *Mock) CloneRBDImage(_ context.Context, pool, image, snapshot, clone string) error {
+	args := m.Called(pool, image, snapshot, clone)
+	return args.Error(0)
+}
+
+func (m *Mock) WriteRBDImage(_ context.Context, pool, image string, offset uint64, data []byte) error {
+	args := m.Called(pool, image, offset, data)
+	return args.Error(0)
+}
+
+func (m *Mock) ExportRBDImage(_ context.Context, pool, image string) ([]byte, error) {
+	args := m.Called(pool, image)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *Mock) ListRBDSnapshots(_ context.Context, pool, image string) ([]ceph.RBDSnapshot, error) {
+	args := m.Called(pool, image)
+	return args.Get(0).([]ceph.RBDSnapshot), args.Error(1)
+}
+
+func (m *Mock) CreateRBDSnapshot(_ context.Context, pool, image, snapshot string) error {
+	args := m.Called(pool, image, snapshot)
+	return args.Error(0)
+}
+
+func (m *Mock) ProtectRBDSnapshot(_ context.Context, pool, image, snapshot string) error {
+	args := m.Called(pool, image, snapshot)
+	return args.Error(0)
+}
+
+func (m *Mock) RemoveRBDSnapshot(_ context.Context, pool, image, snapshot string) error {
+	args := m.Called(pool, image, snapshot)
+	return args.Error(0)
+}
+
+func (m *Mock) LockRBDImage(_ context.Context, pool, image, lockID string) error {
+	args := m.Called(pool, image, lockID)
+	return args.Error(0)
+}
+
+func (m *Mock) UnlockRBDImage(_ context.Context, pool, image, lockID string) error {
+	args := m.Called(pool, image, lockID)
+	return args.Error(0)
+}
+
+func (m *Mock) ListRBDWatchers(_ context.Context, pool, image string) ([]ceph.RBDWatcher, error) {
+	args := m.Called(pool, image)
+	return args.Get(0).([]ceph.RBDWatcher), args.Error(1)
+}
+
+func (m *Mock) GetConfig(_ context.Context, who, name string) (string, error) {
+	args := m.Called(who, name)
+	return args.String(0), args.Error(1)
+}
+
+func (m *Mock) SetConfig(_ context.Context, who, name, value string) error {
+	args := m.Called(who, name, value)
+	return args.Error(0)
+}
+
+func (m *Mock) RemoveConfig(_ context.Context, who, name string) error {
+	args := m.Called(who, name)
+	return args.Error(0)
+}
+
+func (m *Mock) DumpConfig(context.Context) ([]ceph.ConfigOption, error) {
+	args := m.Called()
+	return args.Get(0).([]ceph.ConfigOption), args.Error(1)
+}
+
+func (m *Mock) GetConfigKey(_ context.Context, key string) (string, error) {
+	args := m.Called(key)
+	return args.String(0), args.Error(1)
+}
+
+func (m *Mock) ListClientSessions(context.Context) ([]ceph.ClientSession, error) {
+	args := m.Called()
+	return args.Get(0).([]ceph.ClientSession), args.Error(1)
+}
+
+func (m *Mock) BlocklistAdd(_ context.Context, addr string, expire time.Duration) error {
+	args := m.Called(addr, expire)
+	return args.Error(0)
+}
+
+func (m *Mock) BlocklistRemove(_ context.Context, addr string) error {
+	args := m.Called(addr)
+	return args.Error(0)
+}
+
+func (m *Mock) ListBlocklist(context.Context) ([]ceph.BlocklistEntry, error) {
+	args := m.Called()
+	return args.Get(0).([]ceph.BlocklistEntry), args.Error(1)
+}
+
+func (m *Mock) GetAuth(_ context.Context, entity string) (ceph.AuthEntity, error) {
+	args := m.Called(entity)
+	return args.Get(0).(ceph.AuthEntity), args.Error(1)
+}
+
+func (m *Mock) ListAuth(context.Context) ([]ceph.AuthEntity, error) {
+	args := m.Called()
+	return args.Get(0).([]ceph.AuthEntity), args.Error(1)
+}
+
+func (m *Mock) CreateAuth(_ context.Context, entity string, caps map[string]string) (ceph.AuthEntity, error) {
+	args := m.Called(entity, caps)
+	return args.Get(0).(ceph.AuthEntity), args.Error(1)
+}
+
+func (m *Mock) DeleteAuth(_ context.Context, entity string) error {
+	args := m.Called(entity)
+	return args.Error(0)
+}
+
+func (m *Mock) SetAuthCaps(_ context.Context, entity string, caps map[string]string) error {
+	args := m.Called(entity, caps)
+	return args.Error(0)
+}
+
+func (m *Mock) AsClient(_ context.Context, entity ceph.AuthEntity) (drivers.Cluster, func(context.Context) error, error) {
+	args := m.Called(entity)
+	return args.Get(0).(drivers.Cluster), args.Get(1).(func(context.Context) error), args.Error(2)
+}
@@ -3,7 +3,11 @@ package shell
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"os"
 	"os/exec"
+	"slices"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -14,17 +18,28 @@ var _ Runner = (*runner)(nil)
 type Runner interface {
 	RunCephBinary(ctx context.Context, stdin []byte, args ...string) (stdoutContents []byte, stderrContents []byte, err error)
 	RunRadosBinary(ctx context.Context, stdin []byte, args ...string) (stdoutContents []byte, stderrContents []byte, err error)
+	RunRadosGWAdminBinary(ctx context.Context, stdin []byte, args ...string) (stdoutContents []byte, stderrContents []byte, err error)
+	RunRBDBinary(ctx context.Context, stdin []byte, args ...string) (stdoutContents []byte, stderrContents []byte, err error)
+
+	// WriteTempFile stores the data in the temporary file readable by the
+	// owner only on the host the binaries are run on and returns its path
+	// and the function removing it
+	WriteTempFile(ctx context.Context, data []byte) (path string, remove func(ctx context.Context) error, err error)
 }
 
 type runner struct {
-	cephBinaryPath  string
-	radosBinaryPath string
+	cephBinaryPath         string
+	radosBinaryPath        string
+	radosGWAdminBinaryPath string
+	rbdBinaryPath          string
 }
 
-func NewRunner(cephBinaryPath, radosBinaryPath string) Runner {
+func NewRunner(cephBinaryPath, radosBinaryPath, radosGWAdminBinaryPath, rbdBinaryPath string) Runner {
 	return &runner{
-		cephBinaryPath:  cephBinaryPath,
-		radosBinaryPath: radosBinaryPath,
+		cephBinaryPath:         cephBinaryPath,
+		radosBinaryPath:        radosBinaryPath,
+		radosGWAdminBinaryPath: radosGWAdminBinaryPath,
+		rbdBinaryPath:          rbdBinaryPath,
 	}
 }
 
@@ -36,6 +51,33 @@ func (r *runner) RunCephBinary(ctx context.Context, stdin []byte, args ...string
 	return run(ctx, stdin, r.cephBinaryPath, args...)
 }
 
+func (r *runner) RunRadosGWAdminBinary(ctx context.Context, stdin []byte, args ...string) (stdoutContents []byte, stderrContents []byte, err error) {
+	return run(ctx, stdin, r.radosGWAdminBinaryPath, args...)
+}
+
+func (r *runner) RunRBDBinary(ctx context.Context, stdin []byte, args ...string) (stdoutContents []byte, stderrContents []byte, err error) {
+	return run(ctx, stdin, r.rbdBinaryPath, args...)
+}
+
+func (r *runner) WriteTempFile(_ context.Context, data []byte) (string, func(context.Context) error, error) {
+	fp, err := os.CreateTemp("", "ceph-chaos-monkey-*")
+	if err != nil {
+		return "", nil, err
+	}
+	defer func() { _ = fp.Close() }()
+
+	remove := func(context.Context) error {
+		return os.Remove(fp.Name())
+	}
+
+	if _, err := fp.Write(data); err != nil {
+		_ = remove(context.Background())
+		return "", nil, err
+	}
+
+	return fp.Name(), remove, nil
+}
+
 func run(ctx context.Context, stdin []byte, cmd string, args ...string) (stdoutContents []byte, stderrContents []byte, err error) {
 	log.Tracef("preparing command: %s %#v", cmd, args)
 	c := exec.CommandContext(ctx, cmd, args...)
@@ -54,7 +96,10 @@ func run(ctx context.Context, stdin []byte, cmd string, args ...string) (stdoutC
 	}
 
 	if err := c.Wait(); err != nil {
-		return nil, nil, err
+		// stderr is returned so the callers could tell the error kind
+		outStderr := stderr.Bytes()
+		log.Debugf("command failed: %s: %s", err, string(outStderr))
+		return nil, outStderr, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(outStderr)))
 	}
 
 	outStdout := stdout.Bytes()
@@ -62,11 +107,11 @@ func run(ctx context.Context, stdin []byte, cmd string, args ...string) (stdoutC
 
 	isBinaryGetOp := false
 	for _, arg := range args {
-		if arg == "get" {
+		if (strings.HasSuffix(cmd, "rados") && arg == "get") || (strings.HasSuffix(cmd, "rbd") && arg == "export") {
 			isBinaryGetOp = true
 		}
 	}
-	if !strings.HasSuffix(cmd, "rados") || !isBinaryGetOp {
+	if !isBinaryGetOp {
 		log.Debugf("data received [stdout]: %s\n", string(outStdout))
 	}
 
@@ -75,3 +120,129 @@ func run(ctx context.Context, stdin []byte, cmd string, args ...string) (stdoutC
 
 	return outStdout, outStderr, nil
 }
+
+type sshRunner struct {
+	sshBinaryPath string
+	host          string
+	runner        *runner
+}
+
+// NewSSHRunner runs the binaries on the remote host with ssh. The binary paths
+// are the paths on the remote host.
+func NewSSHRunner(sshBinaryPath, host, cephBinaryPath, radosBinaryPath, radosGWAdminBinaryPath, rbdBinaryPath string) Runner {
+	return &sshRunner{
+		sshBinaryPath: sshBinaryPath,
+		host:          host,
+		runner: &runner{
+			cephBinaryPath:         cephBinaryPath,
+			radosBinaryPath:        radosBinaryPath,
+			radosGWAdminBinaryPath: radosGWAdminBinaryPath,
+			rbdBinaryPath:          rbdBinaryPath,
+		},
+	}
+}
+
+func (r *sshRunner) RunRadosBinary(ctx context.Context, stdin []byte, args ...string) (stdoutContents []byte, stderrContents []byte, err error) {
+	return run(ctx, stdin, r.sshBinaryPath, r.remoteArgs(r.runner.radosBinaryPath, args...)...)
+}
+
+func (r *sshRunner) RunCephBinary(ctx context.Context, stdin []byte, args ...string) (stdoutContents []byte, stderrContents []byte, err error) {
+	return run(ctx, stdin, r.sshBinaryPath, r.remoteArgs(r.runner.cephBinaryPath, args...)...)
+}
+
+func (r *sshRunner) RunRadosGWAdminBinary(ctx context.Context, stdin []byte, args ...string) (stdoutContents []byte, stderrContents []byte, err error) {
+	return run(ctx, stdin, r.sshBinaryPath, r.remoteArgs(r.runner.radosGWAdminBinaryPath, args...)...)
+}
+
+func (r *sshRunner) RunRBDBinary(ctx context.Context, stdin []byte, args ...string) (stdoutContents []byte, stderrContents []byte, err error) {
+	return run(ctx, stdin, r.sshBinaryPath, r.remoteArgs(r.runner.rbdBinaryPath, args...)...)
+}
+
+// WriteTempFile passes the data with ssh stdin so it's never stored on the
+// local host
+func (r *sshRunner) WriteTempFile(ctx context.Context, data []byte) (string, func(context.Context) error, error) {
+	stdout, _, err := run(ctx, data, r.sshBinaryPath, r.shellArgs(`umask 077 && f=$(mktemp) && cat > "$f" && echo "$f"`)...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	path := strings.TrimSpace(string(stdout))
+	if path == "" {
+		return "", nil, errors.New("empty temporary file path received")
+	}
+
+	return path, func(ctx context.Context) error {
+		_, _, err := run(ctx, nil, r.sshBinaryPath, r.shellArgs("rm -f -- "+shellQuote(path))...)
+		return err
+	}, nil
+}
+
+// remoteArgs quotes the command since ssh passes it to the remote shell as a
+// single string
+func (r *sshRunner) remoteArgs(cmd string, args ...string) []string {
+	quoted := []string{shellQuote(cmd)}
+	for _, a := range args {
+		quoted = append(quoted, shellQuote(a))
+	}
+
+	return r.shellArgs(strings.Join(quoted, " "))
+}
+
+func (r *sshRunner) shellArgs(script string) []string {
+	return []string{"-o", "BatchMode=yes", r.host, "--", script}
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+type argsRunner struct {
+	runner Runner
+	args   []string
+}
+
+// withArgs returns the Runner passing the specified arguments to every
+// binary call, e.g. client name or keyring
+func withArgs(r Runner, args ...string) Runner {
+	return &argsRunner{
+		runner: r,
+		args:   args,
+	}
+}
+
+// WithConfig returns the Runner passing the cluster config and the keyring to
+// every binary call. Empty paths are not passed.
+func WithConfig(r Runner, confPath, keyringPath string) Runner {
+	args := []string{}
+	if confPath != "" {
+		args = append(args, "--conf="+confPath)
+	}
+	if keyringPath != "" {
+		args = append(args, "--keyring="+keyringPath)
+	}
+
+	if len(args) == 0 {
+		return r
+	}
+	return withArgs(r, args...)
+}
+
+func (r *argsRunner) RunRadosBinary(ctx context.Context, stdin []byte, args ...string) (stdoutContents []byte, stderrContents []byte, err error) {
+	return r.runner.RunRadosBinary(ctx, stdin, append(slices.Clone(r.args), args...)...)
+}
+
+func (r *argsRunner) RunCephBinary(ctx context.Context, stdin []byte, args ...string) (stdoutContents []byte, stderrContents []byte, err error) {
+	return r.runner.RunCephBinary(ctx, stdin, append(slices.Clone(r.args), args...)...)
+}
+
+func (r *argsRunner) RunRadosGWAdminBinary(ctx context.Context, stdin []byte, args ...string) (stdoutContents []byte, stderrContents []byte, err error) {
+	return r.runner.RunRadosGWAdminBinary(ctx, stdin, append(slices.Clone(r.args), args...)...)
+}
+
+func (r *argsRunner) RunRBDBinary(ctx context.Context, stdin []byte, args ...string) (stdoutContents []byte, stderrContents []byte, err error) {
+	return r.runner.RunRBDBinary(ctx, stdin, append(slices.Clone(r.args), args...)...)
+}
+
+func (r *argsRunner) WriteTempFile(ctx context.Context, data []byte) (string, func(context.Context) error, error) {
+	return r.runner.WriteTempFile(ctx, data)
+}
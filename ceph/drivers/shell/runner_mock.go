@@ -25,3 +25,18 @@ func (m *runnerMock) RunRadosBinary(_ context.Context, stdin []byte, args ...str
 	p := m.Called(stdin, args)
 	return p.Get(0).([]byte), p.Get(1).([]byte), p.Error(2)
 }
+
+func (m *runnerMock) RunRadosGWAdminBinary(_ context.Context, stdin []byte, args ...string) (stdoutContents []byte, stderrContents []byte, err error) {
+	p := m.Called(stdin, args)
+	return p.Get(0).([]byte), p.Get(1).([]byte), p.Error(2)
+}
+
+func (m *runnerMock) RunRBDBinary(_ context.Context, stdin []byte, args ...string) (stdoutContents []byte, stderrContents []byte, err error) {
+	p := m.Called(stdin, args)
+	return p.Get(0).([]byte), p.Get(1).([]byte), p.Error(2)
+}
+
+func (m *runnerMock) WriteTempFile(_ context.Context, data []byte) (string, func(context.Context) error, error) {
+	p := m.Called(data)
+	return p.String(0), p.Get(1).(func(context.Context) error), p.Error(2)
+}
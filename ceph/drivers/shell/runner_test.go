@@ -0,0 +1,91 @@
+package shell
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	r := require.New(t)
+
+	stdout, stderr, err := run(context.Background(), []byte("data"), "sh", "-c", "cat; echo warning >&2")
+	r.NoError(err)
+	r.Equal("data", string(stdout))
+	r.Equal("warning\n", string(stderr))
+}
+
+func TestRunFailure(t *testing.T) {
+	r := require.New(t)
+
+	stdout, stderr, err := run(context.Background(), nil, "sh", "-c", "echo '(2) No such file or directory' >&2; exit 1")
+	r.Error(err)
+	r.Contains(err.Error(), "exit status 1: (2) No such file or directory")
+	r.Nil(stdout)
+	r.True(isENOENT(stderr))
+}
+
+func TestSSHRunnerRemoteArgs(t *testing.T) {
+	r := require.New(t)
+
+	sr := NewSSHRunner("ssh", "trainee1", "/usr/bin/ceph", "/usr/bin/rados", "/usr/bin/radosgw-admin", "/usr/bin/rbd").(*sshRunner)
+	r.Equal(
+		[]string{"-o", "BatchMode=yes", "trainee1", "--", `'/usr/bin/ceph' 'config-key' 'set' 'motd' 'it'\''s fine'`},
+		sr.remoteArgs("/usr/bin/ceph", "config-key", "set", "motd", "it's fine"),
+	)
+}
+
+func TestWriteTempFile(t *testing.T) {
+	r := require.New(t)
+
+	path, remove, err := NewRunner("ceph", "rados", "radosgw-admin", "rbd").WriteTempFile(context.Background(), []byte("secret"))
+	r.NoError(err)
+
+	fi, err := os.Stat(path)
+	r.NoError(err)
+	r.Equal(os.FileMode(0o600), fi.Mode().Perm())
+
+	contents, err := os.ReadFile(path)
+	r.NoError(err)
+	r.Equal("secret", string(contents))
+
+	r.NoError(remove(context.Background()))
+	r.NoFileExists(path)
+}
+
+func TestSSHRunnerWriteTempFile(t *testing.T) {
+	r := require.New(t)
+
+	// the fake ssh runs the remote script locally
+	ssh := filepath.Join(t.TempDir(), "ssh")
+	r.NoError(os.WriteFile(ssh, []byte("#!/bin/sh\nshift 4\nexec sh -c \"$1\"\n"), 0o700))
+
+	path, remove, err := NewSSHRunner(ssh, "trainee1", "ceph", "rados", "radosgw-admin", "rbd").WriteTempFile(context.Background(), []byte("secret"))
+	r.NoError(err)
+
+	fi, err := os.Stat(path)
+	r.NoError(err)
+	r.Equal(os.FileMode(0o600), fi.Mode().Perm())
+
+	contents, err := os.ReadFile(path)
+	r.NoError(err)
+	r.Equal("secret", string(contents))
+
+	r.NoError(remove(context.Background()))
+	r.NoFileExists(path)
+}
+
+func TestWithConfig(t *testing.T) {
+	r := require.New(t)
+
+	m := newRunnerMock()
+	m.On("RunCephBinary", []byte(nil), []string{"--conf=/etc/ceph/t1.conf", "--keyring=/etc/ceph/t1.keyring", "fsid"}).Return([]byte{}, []byte{}, nil).Once()
+
+	_, _, err := WithConfig(m, "/etc/ceph/t1.conf", "/etc/ceph/t1.keyring").RunCephBinary(context.Background(), nil, "fsid")
+	r.NoError(err)
+	r.Equal(Runner(m), WithConfig(m, "", ""))
+	m.AssertExpectations(t)
+}
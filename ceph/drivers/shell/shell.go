@@ -1,9 +1,18 @@
 package shell
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
 	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
 
 	"github.com/teran/ceph-chaos-monkey/ceph"
 	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
@@ -29,6 +38,18 @@ func (c *cluster) GetHealth(ctx context.Context) (ceph.Health, error) {
 	return data, json.Unmarshal(stdout, &data)
 }
 
+func (c *cluster) GetFSID(ctx context.Context) (string, error) {
+	stdout, _, err := c.runner.RunCephBinary(ctx, nil, "fsid", "--format=json")
+	if err != nil {
+		return "", err
+	}
+
+	data := struct {
+		FSID string `json:"fsid"`
+	}{}
+	return data.FSID, json.Unmarshal(stdout, &data)
+}
+
 func (c *cluster) GetOSDs(ctx context.Context) ([]ceph.OSD, error) {
 	type osds struct {
 		OSDs []ceph.OSD `json:"OSDs"`
@@ -44,9 +65,51 @@ func (c *cluster) GetOSDs(ctx context.Context) ([]ceph.OSD, error) {
 		return nil, err
 	}
 
+	// the weights are informational only so the OSDs are returned without
+	// them if the tree couldn't be fetched
+	nodes, err := c.GetOSDTree(ctx)
+	if err != nil {
+		log.Debugf("error getting OSD tree, the OSD weights are not filled: %s", err)
+		return data.OSDs, nil
+	}
+
+	weights := map[int64]ceph.OSDTreeNode{}
+	for _, n := range nodes {
+		if n.Type == "osd" {
+			weights[n.ID] = n
+		}
+	}
+
+	for i, osd := range data.OSDs {
+		if n, ok := weights[int64(osd.ID)]; ok {
+			data.OSDs[i].CrushWeight = n.CrushWeight
+			data.OSDs[i].Reweight = n.Reweight
+			data.OSDs[i].PrimaryAffinity = n.PrimaryAffinity
+		}
+	}
+
 	return data.OSDs, nil
 }
 
+func (c *cluster) GetOSDTree(ctx context.Context) ([]ceph.OSDTreeNode, error) {
+	type tree struct {
+		Nodes []ceph.OSDTreeNode `json:"nodes"`
+		Stray []ceph.OSDTreeNode `json:"stray"`
+	}
+
+	stdout, _, err := c.runner.RunCephBinary(ctx, nil, "osd", "tree", "--format=json")
+	if err != nil {
+		return nil, err
+	}
+
+	data := tree{}
+	if err := json.Unmarshal(stdout, &data); err != nil {
+		return nil, err
+	}
+
+	return append(data.Nodes, data.Stray...), nil
+}
+
 func (c *cluster) GetOSDIDs(ctx context.Context) ([]uint64, error) {
 	stdout, _, err := c.runner.RunCephBinary(ctx, nil, "osd", "ls", "--format=json")
 	if err != nil {
@@ -81,6 +144,27 @@ func (c *cluster) GetMons(ctx context.Context) ([]ceph.Mon, error) {
 	return data.Mons, nil
 }
 
+// GetMonQuorum returns the names of the monitors in quorum
+func (c *cluster) GetMonQuorum(ctx context.Context) ([]string, error) {
+	type quorumStatus struct {
+		// ...
+		QuorumNames []string `json:"quorum_names"`
+		// ...
+	}
+
+	stdout, _, err := c.runner.RunCephBinary(ctx, nil, "quorum_status", "--format=json")
+	if err != nil {
+		return nil, err
+	}
+
+	data := quorumStatus{}
+	if err := json.Unmarshal(stdout, &data); err != nil {
+		return nil, err
+	}
+
+	return data.QuorumNames, nil
+}
+
 func (c *cluster) GetPools(ctx context.Context) ([]ceph.Pool, error) {
 	stdout, _, err := c.runner.RunCephBinary(ctx, nil, "osd", "pool", "ls", "detail", "--format=json")
 	if err != nil {
@@ -128,6 +212,28 @@ func (c *cluster) StopOSDDaemon(ctx context.Context, id uint64) error {
 	return err
 }
 
+func (c *cluster) SetOSDReweight(ctx context.Context, id uint64, weight float64) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "osd", "reweight", "osd."+strconv.FormatUint(id, 10), strconv.FormatFloat(weight, 'f', -1, 64))
+	return err
+}
+
+func (c *cluster) SetPrimaryAffinity(ctx context.Context, id uint64, weight float64) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "osd", "primary-affinity", "osd."+strconv.FormatUint(id, 10), strconv.FormatFloat(weight, 'f', -1, 64))
+	return err
+}
+
+func (c *cluster) SetOSDCrushWeight(ctx context.Context, id uint64, weight float64) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "osd", "crush", "reweight", "osd."+strconv.FormatUint(id, 10), strconv.FormatFloat(weight, 'f', -1, 64))
+	return err
+}
+
+// InjectDataError corrupts the copy of the object stored on the OSD so the
+// inconsistency is found by the next deep-scrub
+func (c *cluster) InjectDataError(ctx context.Context, id uint64, pool, objectName string) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "tell", "osd."+strconv.FormatUint(id, 10), "injectdataerr", pool, objectName)
+	return err
+}
+
 func (c *cluster) SetFlag(ctx context.Context, flag ceph.Flag) error {
 	_, _, err := c.runner.RunCephBinary(ctx, nil, "osd", "set", string(flag))
 	return err
@@ -138,6 +244,34 @@ func (c *cluster) UnsetFlag(ctx context.Context, flag ceph.Flag) error {
 	return err
 }
 
+// GetFlags returns the cluster-wide flags set in the OSD map
+func (c *cluster) GetFlags(ctx context.Context) ([]ceph.Flag, error) {
+	type osdDump struct {
+		// ...
+		Flags string `json:"flags"`
+		// ...
+	}
+
+	stdout, _, err := c.runner.RunCephBinary(ctx, nil, "osd", "dump", "--format=json")
+	if err != nil {
+		return nil, err
+	}
+
+	data := osdDump{}
+	if err := json.Unmarshal(stdout, &data); err != nil {
+		return nil, err
+	}
+
+	flags := []ceph.Flag{}
+	for _, f := range strings.Split(data.Flags, ",") {
+		if f != "" {
+			flags = append(flags, ceph.Flag(f))
+		}
+	}
+
+	return flags, nil
+}
+
 func (c *cluster) SetGroupFlag(ctx context.Context, flag ceph.Flag, group ...string) error {
 	_, _, err := c.runner.RunCephBinary(ctx, nil, append([]string{"osd", "set-group", string(flag)}, group...)...)
 	return err
@@ -153,20 +287,71 @@ func (c *cluster) CreateDefaultPool(ctx context.Context, name string) error {
 	return err
 }
 
+func (c *cluster) EnablePoolApplication(ctx context.Context, name, app string) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "osd", "pool", "application", "enable", name, app)
+	return err
+}
+
+func (c *cluster) DeletePool(ctx context.Context, name string) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "osd", "pool", "delete", name, name, "--yes-i-really-really-mean-it")
+	return err
+}
+
+func (c *cluster) ListCrushRules(ctx context.Context) ([]string, error) {
+	stdout, _, err := c.runner.RunCephBinary(ctx, nil, "osd", "crush", "rule", "ls", "--format=json")
+	if err != nil {
+		return nil, err
+	}
+
+	data := []string{}
+	if err := json.Unmarshal(stdout, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (c *cluster) RemoveCrushRule(ctx context.Context, name string) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "osd", "crush", "rule", "rm", name)
+	return err
+}
+
 func (c *cluster) CreateRADOSObject(ctx context.Context, pool, objectName string, data []byte) error {
 	_, _, err := c.runner.RunRadosBinary(ctx, data, "put", "--pool="+pool, objectName, "-")
 	return err
 }
 
 func (c *cluster) ReadRADOSObject(ctx context.Context, pool, objectName string) ([]byte, error) {
-	stdout, _, err := c.runner.RunRadosBinary(ctx, nil, "get", "--pool="+pool, objectName, "-")
+	stdout, stderr, err := c.runner.RunRadosBinary(ctx, nil, "get", "--pool="+pool, objectName, "-")
 	if err != nil {
+		if isENOENT(stderr) {
+			return nil, fmt.Errorf("object %s/%s: %w", pool, objectName, drivers.ErrNotFound)
+		}
 		return nil, err
 	}
 
 	return stdout, nil
 }
 
+func (c *cluster) DeleteRADOSObject(ctx context.Context, pool, objectName string) error {
+	_, _, err := c.runner.RunRadosBinary(ctx, nil, "rm", "--pool="+pool, objectName)
+	return err
+}
+
+func (c *cluster) MapObject(ctx context.Context, pool, objectName string) (ceph.ObjectMapping, error) {
+	stdout, _, err := c.runner.RunCephBinary(ctx, nil, "osd", "map", pool, objectName, "--format=json")
+	if err != nil {
+		return ceph.ObjectMapping{}, err
+	}
+
+	data := ceph.ObjectMapping{}
+	if err := json.Unmarshal(stdout, &data); err != nil {
+		return ceph.ObjectMapping{}, err
+	}
+
+	return data, nil
+}
+
 func (c *cluster) ListRADOSObjects(ctx context.Context, pool string) ([]string, error) {
 	type object struct {
 		Namespace string `json:"namespace"`
@@ -255,3 +440,606 @@ func (c *cluster) DeepScrubPG(ctx context.Context, target string) error {
 	}
 	return err
 }
+
+func (c *cluster) GetPGUpmapItems(ctx context.Context) ([]ceph.PGUpmapItems, error) {
+	type osdDump struct {
+		// ...
+		PGUpmapItems []ceph.PGUpmapItems `json:"pg_upmap_items"`
+		// ...
+	}
+
+	stdout, _, err := c.runner.RunCephBinary(ctx, nil, "osd", "dump", "--format=json")
+	if err != nil {
+		return nil, err
+	}
+
+	data := osdDump{}
+	if err := json.Unmarshal(stdout, &data); err != nil {
+		return nil, err
+	}
+
+	return data.PGUpmapItems, nil
+}
+
+func (c *cluster) SetPGUpmapItems(ctx context.Context, pgid string, mappings ...ceph.UpmapMapping) error {
+	args := []string{"osd", "pg-upmap-items", pgid}
+	for _, m := range mappings {
+		args = append(args, strconv.FormatUint(m.From, 10), strconv.FormatUint(m.To, 10))
+	}
+
+	_, _, err := c.runner.RunCephBinary(ctx, nil, args...)
+	return err
+}
+
+func (c *cluster) RemovePGUpmapItems(ctx context.Context, pgid string) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "osd", "rm-pg-upmap-items", pgid)
+	return err
+}
+
+func (c *cluster) GetBalancerStatus(ctx context.Context) (ceph.BalancerStatus, error) {
+	stdout, _, err := c.runner.RunCephBinary(ctx, nil, "balancer", "status", "--format=json")
+	if err != nil {
+		return ceph.BalancerStatus{}, err
+	}
+
+	data := ceph.BalancerStatus{}
+	return data, json.Unmarshal(stdout, &data)
+}
+
+func (c *cluster) SetBalancerMode(ctx context.Context, mode ceph.BalancerMode) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "balancer", "mode", string(mode))
+	return err
+}
+
+func (c *cluster) EnableBalancer(ctx context.Context) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "balancer", "on")
+	return err
+}
+
+func (c *cluster) DisableBalancer(ctx context.Context) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "balancer", "off")
+	return err
+}
+
+type fsDump struct {
+	Standbys    []ceph.MDS `json:"standbys"`
+	Filesystems []struct {
+		ID     int64 `json:"id"`
+		MDSMap struct {
+			ceph.Filesystem
+
+			FlagsState struct {
+				Joinable bool `json:"joinable"`
+			} `json:"flags_state"`
+			Info map[string]ceph.MDS `json:"info"`
+		} `json:"mdsmap"`
+	} `json:"filesystems"`
+}
+
+func (c *cluster) dumpFS(ctx context.Context) (fsDump, error) {
+	stdout, _, err := c.runner.RunCephBinary(ctx, nil, "fs", "dump", "--format=json")
+	if err != nil {
+		return fsDump{}, err
+	}
+
+	data := fsDump{}
+	return data, json.Unmarshal(stdout, &data)
+}
+
+func (c *cluster) GetFilesystems(ctx context.Context) ([]ceph.Filesystem, error) {
+	data, err := c.dumpFS(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []ceph.Filesystem{}
+	for _, v := range data.Filesystems {
+		fs := v.MDSMap.Filesystem
+		fs.ID = v.ID
+		fs.Joinable = v.MDSMap.FlagsState.Joinable
+
+		out = append(out, fs)
+	}
+
+	return out, nil
+}
+
+func (c *cluster) GetMDSDaemons(ctx context.Context) ([]ceph.MDS, error) {
+	data, err := c.dumpFS(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []ceph.MDS{}
+	for _, v := range data.Filesystems {
+		// info is keyed by `gid_<gid>` so sort it to keep the order stable
+		for _, k := range slices.Sorted(maps.Keys(v.MDSMap.Info)) {
+			mds := v.MDSMap.Info[k]
+			mds.FSName = v.MDSMap.Name
+
+			out = append(out, mds)
+		}
+	}
+
+	return append(out, data.Standbys...), nil
+}
+
+func (c *cluster) FailMDS(ctx context.Context, name string) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "mds", "fail", name)
+	return err
+}
+
+func (c *cluster) SetMaxMDS(ctx context.Context, fs string, n uint64) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "fs", "set", fs, "max_mds", strconv.FormatUint(n, 10))
+	return err
+}
+
+func (c *cluster) SetFilesystemJoinable(ctx context.Context, fs string, joinable bool) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "fs", "set", fs, "joinable", strconv.FormatBool(joinable))
+	return err
+}
+
+func (c *cluster) SetFilesystemDown(ctx context.Context, fs string, down bool) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "fs", "set", fs, "down", strconv.FormatBool(down))
+	return err
+}
+
+func (c *cluster) ListRGWDaemons(ctx context.Context) ([]ceph.Daemon, error) {
+	stdout, _, err := c.runner.RunCephBinary(ctx, nil, "orch", "ps", "--daemon-type", "rgw", "--format=json")
+	if err != nil {
+		return nil, err
+	}
+
+	data := []ceph.Daemon{}
+	if err := json.Unmarshal(stdout, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (c *cluster) StopDaemon(ctx context.Context, name string) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "orch", "daemon", "stop", name)
+	return err
+}
+
+func (c *cluster) StartDaemon(ctx context.Context, name string) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "orch", "daemon", "start", name)
+	return err
+}
+
+func (c *cluster) RestartDaemon(ctx context.Context, name string) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "orch", "daemon", "restart", name)
+	return err
+}
+
+func (c *cluster) ListBuckets(ctx context.Context) ([]string, error) {
+	stdout, _, err := c.runner.RunRadosGWAdminBinary(ctx, nil, "bucket", "list")
+	if err != nil {
+		return nil, err
+	}
+
+	data := []string{}
+	if err := json.Unmarshal(stdout, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (c *cluster) SetBucketQuota(ctx context.Context, bucket string, maxObjects, maxSizeBytes int64) error {
+	_, _, err := c.runner.RunRadosGWAdminBinary(ctx, nil,
+		"quota", "set", "--quota-scope=bucket", "--bucket="+bucket,
+		"--max-objects="+strconv.FormatInt(maxObjects, 10),
+		"--max-size="+strconv.FormatInt(maxSizeBytes, 10),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = c.runner.RunRadosGWAdminBinary(ctx, nil, "quota", "enable", "--quota-scope=bucket", "--bucket="+bucket)
+	return err
+}
+
+func (c *cluster) ListZones(ctx context.Context) ([]string, error) {
+	type zoneList struct {
+		DefaultInfo string   `json:"default_info"`
+		Zones       []string `json:"zones"`
+	}
+
+	stdout, _, err := c.runner.RunRadosGWAdminBinary(ctx, nil, "zone", "list")
+	if err != nil {
+		return nil, err
+	}
+
+	data := zoneList{}
+	if err := json.Unmarshal(stdout, &data); err != nil {
+		return nil, err
+	}
+
+	return data.Zones, nil
+}
+
+// SetZoneReadOnly modifies the zone and commits the period so the gateways
+// pick the change up. The commit is skipped on the single-site cluster with no
+// realm configured since the zone is read directly there.
+func (c *cluster) SetZoneReadOnly(ctx context.Context, zone string, readOnly bool) error {
+	_, _, err := c.runner.RunRadosGWAdminBinary(ctx, nil, "zone", "modify", "--rgw-zone="+zone, "--read-only="+strconv.FormatBool(readOnly))
+	if err != nil {
+		return err
+	}
+
+	_, stderr, err := c.runner.RunRadosGWAdminBinary(ctx, nil, "period", "update", "--commit")
+	if err != nil && !isENOENT(stderr) {
+		return err
+	}
+
+	return nil
+}
+
+func (c *cluster) ListRBDImages(ctx context.Context, pool string) ([]string, error) {
+	stdout, _, err := c.runner.RunRBDBinary(ctx, nil, "ls", "--format=json", pool)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []string{}
+	if err := json.Unmarshal(stdout, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (c *cluster) GetRBDImage(ctx context.Context, pool, image string) (ceph.RBDImage, error) {
+	stdout, _, err := c.runner.RunRBDBinary(ctx, nil, "info", "--format=json", rbdImageSpec(pool, image))
+	if err != nil {
+		return ceph.RBDImage{}, err
+	}
+
+	data := ceph.RBDImage{}
+	if err := json.Unmarshal(stdout, &data); err != nil {
+		return ceph.RBDImage{}, err
+	}
+
+	return data, nil
+}
+
+func (c *cluster) CreateRBDImage(ctx context.Context, pool, image string, sizeMiB uint64) error {
+	_, _, err := c.runner.RunRBDBinary(ctx, nil, "create", "--size="+strconv.FormatUint(sizeMiB, 10)+"M", rbdImageSpec(pool, image))
+	return err
+}
+
+func (c *cluster) ResizeRBDImage(ctx context.Context, pool, image string, sizeMiB uint64) error {
+	_, _, err := c.runner.RunRBDBinary(ctx, nil, "resize", "--size="+strconv.FormatUint(sizeMiB, 10)+"M", "--allow-shrink", rbdImageSpec(pool, image))
+	return err
+}
+
+func (c *cluster) FlattenRBDImage(ctx context.Context, pool, image string) error {
+	_, _, err := c.runner.RunRBDBinary(ctx, nil, "flatten", rbdImageSpec(pool, image))
+	return err
+}
+
+func (c *cluster) CloneRBDImage(ctx context.Context, pool, image, snapshot, clone string) error {
+	_, _, err := c.runner.RunRBDBinary(ctx, nil, "clone", rbdSnapSpec(pool, image, snapshot), rbdImageSpec(pool, clone))
+	return err
+}
+
+// WriteRBDImage writes the data at the offset by importing the diff crafted
+// in `rbd diff v1` format since rbd CLI has no command to write a single
+// extent to the image
+func (c *cluster) WriteRBDImage(ctx context.Context, pool, image string, offset uint64, data []byte) error {
+	buf := &bytes.Buffer{}
+	buf.WriteString("rbd diff v1\n")
+	buf.WriteByte('w')
+	_ = binary.Write(buf, binary.LittleEndian, offset)
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(data)))
+	buf.Write(data)
+	buf.WriteByte('e')
+
+	_, _, err := c.runner.RunRBDBinary(ctx, buf.Bytes(), "import-diff", "--no-progress", "-", rbdImageSpec(pool, image))
+	return err
+}
+
+func (c *cluster) ExportRBDImage(ctx context.Context, pool, image string) ([]byte, error) {
+	stdout, _, err := c.runner.RunRBDBinary(ctx, nil, "export", "--no-progress", rbdImageSpec(pool, image), "-")
+	if err != nil {
+		return nil, err
+	}
+
+	return stdout, nil
+}
+
+func (c *cluster) ListRBDSnapshots(ctx context.Context, pool, image string) ([]ceph.RBDSnapshot, error) {
+	stdout, _, err := c.runner.RunRBDBinary(ctx, nil, "snap", "ls", "--format=json", rbdImageSpec(pool, image))
+	if err != nil {
+		return nil, err
+	}
+
+	data := []ceph.RBDSnapshot{}
+	if err := json.Unmarshal(stdout, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (c *cluster) CreateRBDSnapshot(ctx context.Context, pool, image, snapshot string) error {
+	_, _, err := c.runner.RunRBDBinary(ctx, nil, "snap", "create", rbdSnapSpec(pool, image, snapshot))
+	return err
+}
+
+func (c *cluster) ProtectRBDSnapshot(ctx context.Context, pool, image, snapshot string) error {
+	_, _, err := c.runner.RunRBDBinary(ctx, nil, "snap", "protect", rbdSnapSpec(pool, image, snapshot))
+	return err
+}
+
+func (c *cluster) RemoveRBDSnapshot(ctx context.Context, pool, image, snapshot string) error {
+	_, _, err := c.runner.RunRBDBinary(ctx, nil, "snap", "rm", "--no-progress", rbdSnapSpec(pool, image, snapshot))
+	return err
+}
+
+func (c *cluster) LockRBDImage(ctx context.Context, pool, image, lockID string) error {
+	_, _, err := c.runner.RunRBDBinary(ctx, nil, "lock", "add", rbdImageSpec(pool, image), lockID)
+	return err
+}
+
+// UnlockRBDImage removes the lock regardless of its locker since the lock
+// could be taken by another client
+func (c *cluster) UnlockRBDImage(ctx context.Context, pool, image, lockID string) error {
+	stdout, _, err := c.runner.RunRBDBinary(ctx, nil, "lock", "ls", "--format=json", rbdImageSpec(pool, image))
+	if err != nil {
+		return err
+	}
+
+	locks := []ceph.RBDLock{}
+	if err := json.Unmarshal(stdout, &locks); err != nil {
+		return err
+	}
+
+	for _, l := range locks {
+		if l.ID != lockID {
+			continue
+		}
+
+		_, _, err := c.runner.RunRBDBinary(ctx, nil, "lock", "rm", rbdImageSpec(pool, image), l.ID, l.Locker)
+		return err
+	}
+
+	return fmt.Errorf("lock `%s` is not held on image %s", lockID, rbdImageSpec(pool, image))
+}
+
+func (c *cluster) ListRBDWatchers(ctx context.Context, pool, image string) ([]ceph.RBDWatcher, error) {
+	type status struct {
+		Watchers []ceph.RBDWatcher `json:"watchers"`
+	}
+
+	stdout, _, err := c.runner.RunRBDBinary(ctx, nil, "status", "--format=json", rbdImageSpec(pool, image))
+	if err != nil {
+		return nil, err
+	}
+
+	data := status{}
+	if err := json.Unmarshal(stdout, &data); err != nil {
+		return nil, err
+	}
+
+	return data.Watchers, nil
+}
+
+func (c *cluster) GetConfig(ctx context.Context, who, name string) (string, error) {
+	stdout, _, err := c.runner.RunCephBinary(ctx, nil, "config", "get", who, name)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(stdout)), nil
+}
+
+func (c *cluster) SetConfig(ctx context.Context, who, name, value string) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "config", "set", who, name, value)
+	return err
+}
+
+func (c *cluster) RemoveConfig(ctx context.Context, who, name string) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "config", "rm", who, name)
+	return err
+}
+
+func (c *cluster) DumpConfig(ctx context.Context) ([]ceph.ConfigOption, error) {
+	stdout, _, err := c.runner.RunCephBinary(ctx, nil, "config", "dump", "--format=json")
+	if err != nil {
+		return nil, err
+	}
+
+	data := []ceph.ConfigOption{}
+	if err := json.Unmarshal(stdout, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// GetConfigKey returns the value stored in the monitors config-key store or
+// drivers.ErrNotFound if the key is not set
+func (c *cluster) GetConfigKey(ctx context.Context, key string) (string, error) {
+	stdout, stderr, err := c.runner.RunCephBinary(ctx, nil, "config-key", "get", key)
+	if err != nil {
+		if isENOENT(stderr) {
+			return "", fmt.Errorf("config key %s: %w", key, drivers.ErrNotFound)
+		}
+		return "", err
+	}
+
+	return string(stdout), nil
+}
+
+// ListClientSessions returns the client sessions of all the monitors since
+// the client is connected to any one of them
+func (c *cluster) ListClientSessions(ctx context.Context) ([]ceph.ClientSession, error) {
+	type entityAddr struct {
+		Addr  string `json:"addr"`
+		Nonce uint64 `json:"nonce"`
+	}
+
+	type session struct {
+		EntityName string `json:"entity_name"`
+		ConType    string `json:"con_type"`
+		Addrs      struct {
+			AddrVec []entityAddr `json:"addrvec"`
+		} `json:"addrs"`
+	}
+
+	mons, err := c.GetMons(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []ceph.ClientSession{}
+	for _, mon := range mons {
+		stdout, _, err := c.runner.RunCephBinary(ctx, nil, "tell", "mon."+mon.Name, "sessions", "--format=json")
+		if err != nil {
+			return nil, err
+		}
+
+		data := []session{}
+		if err := json.Unmarshal(stdout, &data); err != nil {
+			return nil, err
+		}
+
+		for _, s := range data {
+			if s.ConType != "client" || len(s.Addrs.AddrVec) == 0 {
+				continue
+			}
+
+			addr := s.Addrs.AddrVec[0]
+			sessions = append(sessions, ceph.ClientSession{
+				Entity: s.EntityName,
+				Addr:   addr.Addr + "/" + strconv.FormatUint(addr.Nonce, 10),
+			})
+		}
+	}
+
+	return sessions, nil
+}
+
+func (c *cluster) BlocklistAdd(ctx context.Context, addr string, expire time.Duration) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "osd", "blocklist", "add", addr, strconv.FormatInt(int64(expire.Seconds()), 10))
+	return err
+}
+
+func (c *cluster) BlocklistRemove(ctx context.Context, addr string) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "osd", "blocklist", "rm", addr)
+	return err
+}
+
+func (c *cluster) ListBlocklist(ctx context.Context) ([]ceph.BlocklistEntry, error) {
+	stdout, _, err := c.runner.RunCephBinary(ctx, nil, "osd", "blocklist", "ls", "--format=json")
+	if err != nil {
+		return nil, err
+	}
+
+	data := []ceph.BlocklistEntry{}
+	if err := json.Unmarshal(stdout, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (c *cluster) GetAuth(ctx context.Context, entity string) (ceph.AuthEntity, error) {
+	stdout, _, err := c.runner.RunCephBinary(ctx, nil, "auth", "get", entity, "--format=json")
+	if err != nil {
+		return ceph.AuthEntity{}, err
+	}
+
+	return unmarshalAuthEntity(stdout)
+}
+
+func (c *cluster) ListAuth(ctx context.Context) ([]ceph.AuthEntity, error) {
+	type authDump struct {
+		AuthDump []ceph.AuthEntity `json:"auth_dump"`
+	}
+
+	stdout, _, err := c.runner.RunCephBinary(ctx, nil, "auth", "ls", "--format=json")
+	if err != nil {
+		return nil, err
+	}
+
+	data := authDump{}
+	if err := json.Unmarshal(stdout, &data); err != nil {
+		return nil, err
+	}
+
+	return data.AuthDump, nil
+}
+
+func (c *cluster) CreateAuth(ctx context.Context, entity string, caps map[string]string) (ceph.AuthEntity, error) {
+	args := append([]string{"auth", "get-or-create", entity}, capsArgs(caps)...)
+	stdout, _, err := c.runner.RunCephBinary(ctx, nil, append(args, "--format=json")...)
+	if err != nil {
+		return ceph.AuthEntity{}, err
+	}
+
+	return unmarshalAuthEntity(stdout)
+}
+
+func (c *cluster) DeleteAuth(ctx context.Context, entity string) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, "auth", "del", entity)
+	return err
+}
+
+func (c *cluster) SetAuthCaps(ctx context.Context, entity string, caps map[string]string) error {
+	_, _, err := c.runner.RunCephBinary(ctx, nil, append([]string{"auth", "caps", entity}, capsArgs(caps)...)...)
+	return err
+}
+
+// AsClient keeps the client key in the temporary keyring file readable by the
+// owner only on the host the binaries are run on, it's removed with the
+// returned function
+func (c *cluster) AsClient(ctx context.Context, entity ceph.AuthEntity) (drivers.Cluster, func(context.Context) error, error) {
+	keyring, remove, err := c.runner.WriteTempFile(ctx, []byte(fmt.Sprintf("[%s]\n\tkey = %s\n", entity.Entity, entity.Key)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &cluster{
+		runner: withArgs(c.runner, "--name="+entity.Entity, "--keyring="+keyring),
+	}, remove, nil
+}
+
+// isENOENT reports whether the command failed because of missing entity
+func isENOENT(stderr []byte) bool {
+	return bytes.Contains(stderr, []byte("(2) No such file or directory"))
+}
+
+func rbdImageSpec(pool, image string) string {
+	return pool + "/" + image
+}
+
+func rbdSnapSpec(pool, image, snapshot string) string {
+	return rbdImageSpec(pool, image) + "@" + snapshot
+}
+
+func unmarshalAuthEntity(data []byte) (ceph.AuthEntity, error) {
+	entities := []ceph.AuthEntity{}
+	if err := json.Unmarshal(data, &entities); err != nil {
+		return ceph.AuthEntity{}, err
+	}
+
+	if len(entities) != 1 {
+		return ceph.AuthEntity{}, fmt.Errorf("exactly one entity expected, got %d", len(entities))
+	}
+
+	return entities[0], nil
+}
+
+// capsArgs returns caps as `<service> <cap>` argument pairs sorted by service
+// name to keep the command line stable
+func capsArgs(caps map[string]string) []string {
+	args := []string{}
+	for _, svc := range slices.Sorted(maps.Keys(caps)) {
+		args = append(args, svc, caps[svc])
+	}
+	return args
+}
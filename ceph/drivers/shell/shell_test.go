@@ -2,8 +2,10 @@ package shell
 
 import (
 	"context"
+	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 
@@ -15,7 +17,11 @@ func (s *cephTestSuite) TestGetOSDs() {
 	stdout, err := os.ReadFile("testdata/osd-status.json")
 	s.Require().NoError(err)
 
+	tree, err := os.ReadFile("testdata/osd-tree.json")
+	s.Require().NoError(err)
+
 	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "status", "--format=json"}).Return(stdout, []byte{}, nil).Once()
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "tree", "--format=json"}).Return(tree, []byte{}, nil).Once()
 	osds, err := s.cluster.GetOSDs(s.ctx)
 	s.Require().NoError(err)
 	s.Require().Equal([]ceph.OSD{
@@ -30,8 +36,11 @@ func (s *cephTestSuite) TestGetOSDs() {
 				"autoout",
 				"exists",
 			},
-			WriteByteRate: 0,
-			WriteOpsRate:  0,
+			WriteByteRate:   0,
+			WriteOpsRate:    0,
+			CrushWeight:     0,
+			Reweight:        0,
+			PrimaryAffinity: 1,
 		},
 		{
 			HostName:     "",
@@ -57,12 +66,29 @@ func (s *cephTestSuite) TestGetOSDs() {
 				"exists",
 				"up",
 			},
-			WriteByteRate: 0,
-			WriteOpsRate:  0,
+			WriteByteRate:   0,
+			WriteOpsRate:    0,
+			CrushWeight:     0.0194854736328125,
+			Reweight:        0.8,
+			PrimaryAffinity: 0.5,
 		},
 	}, osds)
 }
 
+func (s *cephTestSuite) TestGetOSDsWithoutTree() {
+	stdout, err := os.ReadFile("testdata/osd-status.json")
+	s.Require().NoError(err)
+
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "status", "--format=json"}).Return(stdout, []byte{}, nil).Once()
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "tree", "--format=json"}).Return([]byte(nil), []byte{}, errors.New("timed out")).Once()
+
+	osds, err := s.cluster.GetOSDs(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Len(osds, 3)
+	s.Require().Equal(uint64(1), osds[1].ID)
+	s.Require().Zero(osds[1].Reweight)
+}
+
 func (s *cephTestSuite) TestGetOSDIDs() {
 	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "ls", "--format=json"}).Return([]byte(`[0,3,4,5,67]`), []byte{}, nil).Once()
 
@@ -148,6 +174,22 @@ func (s *cephTestSuite) TestDestroyOSD() {
 	s.Require().NoError(err)
 }
 
+func (s *cephTestSuite) TestGetMonQuorum() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"quorum_status", "--format=json"}).Return([]byte(`{"election_epoch":12,"quorum":[0,1],"quorum_names":["ceph01","ceph02"],"quorum_leader_name":"ceph01"}`), []byte{}, nil).Once()
+
+	quorum, err := s.cluster.GetMonQuorum(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"ceph01", "ceph02"}, quorum)
+}
+
+func (s *cephTestSuite) TestGetFSID() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"fsid", "--format=json"}).Return([]byte(`{"fsid":"5d6b1a3e-0c3f-11f0-9d3c-525400a1b2c3"}`), []byte{}, nil).Once()
+
+	fsid, err := s.cluster.GetFSID(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal("5d6b1a3e-0c3f-11f0-9d3c-525400a1b2c3", fsid)
+}
+
 func (s *cephTestSuite) TestGetPools() {
 	stdout, err := os.ReadFile("testdata/osd-pool-ls-detail.json")
 	s.Require().NoError(err)
@@ -173,6 +215,9 @@ func (s *cephTestSuite) TestGetPools() {
 				PgNumMax: 32,
 				PgNumMin: 1,
 			},
+			ApplicationMetadata: map[string]map[string]string{
+				"mgr": {},
+			},
 		},
 	}, mons)
 }
@@ -191,6 +236,35 @@ func (s *cephTestSuite) TestChangePoolPGNum() {
 	s.Require().NoError(err)
 }
 
+func (s *cephTestSuite) TestEnablePoolApplication() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "pool", "application", "enable", "test-pool", "chaos-monkey"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.EnablePoolApplication(s.ctx, "test-pool", "chaos-monkey")
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestDeletePool() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "pool", "delete", "test-pool", "test-pool", "--yes-i-really-really-mean-it"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.DeletePool(s.ctx, "test-pool")
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestListCrushRules() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "crush", "rule", "ls", "--format=json"}).Return([]byte(`["replicated_rule","chaos-monkey-rule"]`), []byte{}, nil).Once()
+
+	rules, err := s.cluster.ListCrushRules(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"replicated_rule", "chaos-monkey-rule"}, rules)
+}
+
+func (s *cephTestSuite) TestRemoveCrushRule() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "crush", "rule", "rm", "chaos-monkey-rule"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.RemoveCrushRule(s.ctx, "chaos-monkey-rule")
+	s.Require().NoError(err)
+}
+
 func (s *cephTestSuite) TestStopOSDDaemon() {
 	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"orch", "daemon", "stop", "osd.10"}).Return([]byte{}, []byte{}, nil).Once()
 
@@ -255,6 +329,44 @@ func (s *cephTestSuite) TestReadRADOSObject() {
 	s.Require().Equal("test data", string(data))
 }
 
+func (s *cephTestSuite) TestReadRADOSObjectNotFound() {
+	s.runnerMock.On("RunRadosBinary", []byte(nil), []string{"get", "--pool=test-pool", "object-name", "-"}).Return(
+		[]byte(nil), []byte("error getting test-pool/object-name: (2) No such file or directory\n"), errors.New("exit status 1"),
+	).Once()
+
+	_, err := s.cluster.ReadRADOSObject(s.ctx, "test-pool", "object-name")
+	s.Require().ErrorIs(err, drivers.ErrNotFound)
+}
+
+func (s *cephTestSuite) TestMapObject() {
+	stdout, err := os.ReadFile("testdata/osd-map.json")
+	s.Require().NoError(err)
+
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "map", "test-pool", "object-name", "--format=json"}).Return(stdout, []byte{}, nil).Once()
+
+	mapping, err := s.cluster.MapObject(s.ctx, "test-pool", "object-name")
+	s.Require().NoError(err)
+	s.Require().Equal(ceph.ObjectMapping{
+		Epoch:         143,
+		Pool:          "test-pool",
+		PoolID:        3,
+		ObjectName:    "object-name",
+		RawPGID:       "3.9ab6df3b",
+		PGID:          "3.1b",
+		Up:            []int{1, 0, 2},
+		UpPrimary:     1,
+		Acting:        []int{1, 0, 2},
+		ActingPrimary: 1,
+	}, mapping)
+}
+
+func (s *cephTestSuite) TestDeleteRADOSObject() {
+	s.runnerMock.On("RunRadosBinary", []byte(nil), []string{"rm", "--pool=test-pool", "object-name"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.DeleteRADOSObject(s.ctx, "test-pool", "object-name")
+	s.Require().NoError(err)
+}
+
 func (s *cephTestSuite) TestListRADOSObjects() {
 	s.runnerMock.On("RunRadosBinary", []byte(nil), []string{"ls", "--pool=test-pool", "--format=json"}).Return([]byte(`[{"name":"obj1"},{"name":"obj2"}]`), []byte{}, nil).Once()
 
@@ -322,6 +434,726 @@ func (s *cephTestSuite) TestListPGs() {
 	}, pgs)
 }
 
+func (s *cephTestSuite) TestGetConfig() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"config", "get", "osd.3", "osd_max_backfills"}).Return([]byte("1\n"), []byte{}, nil).Once()
+
+	value, err := s.cluster.GetConfig(s.ctx, "osd.3", "osd_max_backfills")
+	s.Require().NoError(err)
+	s.Require().Equal("1", value)
+}
+
+func (s *cephTestSuite) TestSetConfig() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"config", "set", "osd/host:ceph01", "osd_max_backfills", "1"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.SetConfig(s.ctx, "osd/host:ceph01", "osd_max_backfills", "1")
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestRemoveConfig() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"config", "rm", "global", "osd_max_backfills"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.RemoveConfig(s.ctx, "global", "osd_max_backfills")
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestGetConfigKey() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"config-key", "get", "production"}).Return([]byte("yes"), []byte{}, nil).Once()
+
+	v, err := s.cluster.GetConfigKey(s.ctx, "production")
+	s.Require().NoError(err)
+	s.Require().Equal("yes", v)
+}
+
+func (s *cephTestSuite) TestGetConfigKeyNotFound() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"config-key", "get", "production"}).Return(
+		[]byte(nil), []byte("Error ENOENT: error obtaining 'production': (2) No such file or directory\n"), errors.New("exit status 2"),
+	).Once()
+
+	_, err := s.cluster.GetConfigKey(s.ctx, "production")
+	s.Require().ErrorIs(err, drivers.ErrNotFound)
+}
+
+func (s *cephTestSuite) TestDumpConfig() {
+	stdout, err := os.ReadFile("testdata/config-dump.json")
+	s.Require().NoError(err)
+
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"config", "dump", "--format=json"}).Return(stdout, []byte{}, nil).Once()
+	opts, err := s.cluster.DumpConfig(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]ceph.ConfigOption{
+		{
+			Section:            "global",
+			Name:               "container_image",
+			Value:              "quay.io/ceph/ceph@sha256:200087c35811bf28e8a8073b15fa86c07cce85c575f1ccd62d1d6ddbfdc6770a",
+			Level:              "basic",
+			CanUpdateAtRuntime: false,
+		},
+		{
+			Section:            "mon",
+			Name:               "auth_allow_insecure_global_id_reclaim",
+			Value:              "false",
+			Level:              "advanced",
+			CanUpdateAtRuntime: true,
+		},
+		{
+			Section:            "osd",
+			Name:               "osd_memory_target",
+			Value:              "4294967296",
+			Level:              "basic",
+			CanUpdateAtRuntime: true,
+			Mask:               "host:ceph01",
+		},
+		{
+			Section:            "osd.2",
+			Name:               "osd_max_backfills",
+			Value:              "3",
+			Level:              "advanced",
+			CanUpdateAtRuntime: true,
+		},
+	}, opts)
+	s.Require().Equal("osd/host:ceph01", opts[2].Who())
+	s.Require().Equal("osd.2", opts[3].Who())
+}
+
+func (s *cephTestSuite) TestListClientSessions() {
+	mons, err := os.ReadFile("testdata/mon-dump.json")
+	s.Require().NoError(err)
+
+	sessions, err := os.ReadFile("testdata/mon-sessions.json")
+	s.Require().NoError(err)
+
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"mon", "dump", "--format=json"}).Return(mons, []byte{}, nil).Once()
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"tell", "mon.ceph01", "sessions", "--format=json"}).Return(sessions, []byte{}, nil).Once()
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"tell", "mon.ceph02", "sessions", "--format=json"}).Return([]byte("[]"), []byte{}, nil).Once()
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"tell", "mon.ceph03", "sessions", "--format=json"}).Return([]byte("[]"), []byte{}, nil).Once()
+
+	v, err := s.cluster.ListClientSessions(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]ceph.ClientSession{
+		{Entity: "client.admin", Addr: "100.64.65.100:0/1853207391"},
+		{Entity: "client.chaos-monkey-123", Addr: "100.64.65.100:0/3710147553"},
+	}, v)
+}
+
+func (s *cephTestSuite) TestBlocklistAdd() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "blocklist", "add", "100.64.65.100:0/0", "300"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.BlocklistAdd(s.ctx, "100.64.65.100:0/0", 5*time.Minute)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestBlocklistRemove() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "blocklist", "rm", "100.64.65.100:0/0"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.BlocklistRemove(s.ctx, "100.64.65.100:0/0")
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestListBlocklist() {
+	stdout, err := os.ReadFile("testdata/osd-blocklist-ls.json")
+	s.Require().NoError(err)
+
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "blocklist", "ls", "--format=json"}).Return(stdout, []byte{}, nil).Once()
+
+	entries, err := s.cluster.ListBlocklist(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]ceph.BlocklistEntry{
+		{Addr: "100.64.65.19:0/3710147553", Until: "2025-04-08T10:14:31.204116+0000"},
+		{Addr: "100.64.65.30:0/0", Until: "2025-04-08T11:00:00.000000+0000"},
+	}, entries)
+}
+
+func (s *cephTestSuite) TestGetAuth() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"auth", "get", "client.test", "--format=json"}).Return(
+		[]byte(`[{"entity":"client.test","key":"AQBJSOln","caps":{"mon":"allow r"}}]`), []byte{}, nil,
+	).Once()
+
+	entity, err := s.cluster.GetAuth(s.ctx, "client.test")
+	s.Require().NoError(err)
+	s.Require().Equal(ceph.AuthEntity{
+		Entity: "client.test",
+		Key:    "AQBJSOln",
+		Caps:   map[string]string{"mon": "allow r"},
+	}, entity)
+}
+
+func (s *cephTestSuite) TestListAuth() {
+	stdout, err := os.ReadFile("testdata/auth-ls.json")
+	s.Require().NoError(err)
+
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"auth", "ls", "--format=json"}).Return(stdout, []byte{}, nil).Once()
+
+	entities, err := s.cluster.ListAuth(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]ceph.AuthEntity{
+		{
+			Entity: "osd.0",
+			Key:    "AQBJSOlnwW4OMBAAh6qNTLSN3ghx6dl2Y3ROWQ==",
+			Caps: map[string]string{
+				"mgr": "allow profile osd",
+				"mon": "allow profile osd",
+				"osd": "allow *",
+			},
+		},
+		{
+			Entity: "client.admin",
+			Key:    "AQD7RulnGPTDIRAAT5PUWUQ1vTTymHsAfrn1Yw==",
+			Caps: map[string]string{
+				"mds": "allow *",
+				"mgr": "allow *",
+				"mon": "allow *",
+				"osd": "allow *",
+			},
+		},
+		{
+			Entity: "client.bootstrap-osd",
+			Key:    "AQD9RulnUGp3KRAAbSpRu5phE1dmyq0Y/WXyHA==",
+			Caps: map[string]string{
+				"mon": "allow profile bootstrap-osd",
+			},
+		},
+	}, entities)
+}
+
+func (s *cephTestSuite) TestCreateAuth() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"auth", "get-or-create", "client.test", "mon", "allow r", "osd", "allow rw pool=test", "--format=json"}).Return(
+		[]byte(`[{"entity":"client.test","key":"AQBJSOln","caps":{"mon":"allow r","osd":"allow rw pool=test"}}]`), []byte{}, nil,
+	).Once()
+
+	entity, err := s.cluster.CreateAuth(s.ctx, "client.test", map[string]string{
+		"osd": "allow rw pool=test",
+		"mon": "allow r",
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(ceph.AuthEntity{
+		Entity: "client.test",
+		Key:    "AQBJSOln",
+		Caps:   map[string]string{"mon": "allow r", "osd": "allow rw pool=test"},
+	}, entity)
+}
+
+func (s *cephTestSuite) TestDeleteAuth() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"auth", "del", "client.test"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.DeleteAuth(s.ctx, "client.test")
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestSetAuthCaps() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"auth", "caps", "client.test", "mon", "allow r"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.SetAuthCaps(s.ctx, "client.test", map[string]string{"mon": "allow r"})
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestAsClient() {
+	removed := false
+	s.runnerMock.On("WriteTempFile", []byte("[client.test]\n\tkey = AQBJSOln\n")).Return("/tmp/ceph-chaos-monkey-1", func(context.Context) error {
+		removed = true
+		return nil
+	}, nil).Once()
+	s.runnerMock.On("RunRadosBinary", []byte(nil), []string{
+		"--name=client.test", "--keyring=/tmp/ceph-chaos-monkey-1", "get", "--pool=test-pool", "object-name", "-",
+	}).Return([]byte("test data"), []byte{}, nil).Once()
+
+	client, remove, err := s.cluster.AsClient(s.ctx, ceph.AuthEntity{Entity: "client.test", Key: "AQBJSOln"})
+	s.Require().NoError(err)
+
+	data, err := client.ReadRADOSObject(s.ctx, "test-pool", "object-name")
+	s.Require().NoError(err)
+	s.Require().Equal("test data", string(data))
+
+	s.Require().NoError(remove(s.ctx))
+	s.Require().True(removed)
+}
+
+func (s *cephTestSuite) TestGetOSDTree() {
+	stdout, err := os.ReadFile("testdata/osd-tree.json")
+	s.Require().NoError(err)
+
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "tree", "--format=json"}).Return(stdout, []byte{}, nil).Once()
+
+	nodes, err := s.cluster.GetOSDTree(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]ceph.OSDTreeNode{
+		{
+			ID:       -1,
+			Name:     "default",
+			Type:     "root",
+			TypeID:   11,
+			Children: []int64{-3},
+		},
+		{
+			ID:       -3,
+			Name:     "ceph03",
+			Type:     "host",
+			TypeID:   1,
+			Children: []int64{2},
+		},
+		{
+			ID:              2,
+			Name:            "osd.2",
+			Type:            "osd",
+			DeviceClass:     "hdd",
+			CrushWeight:     0.0194854736328125,
+			Depth:           2,
+			Exists:          1,
+			Status:          "up",
+			Reweight:        0.8,
+			PrimaryAffinity: 0.5,
+		},
+		{
+			ID:              0,
+			Name:            "osd.0",
+			Type:            "osd",
+			Exists:          1,
+			Status:          "down",
+			PrimaryAffinity: 1,
+		},
+	}, nodes)
+}
+
+func (s *cephTestSuite) TestSetOSDReweight() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "reweight", "osd.3", "0.25"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.SetOSDReweight(s.ctx, 3, 0.25)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestSetPrimaryAffinity() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "primary-affinity", "osd.3", "0"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.SetPrimaryAffinity(s.ctx, 3, 0)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestSetOSDCrushWeight() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "crush", "reweight", "osd.3", "0.5"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.SetOSDCrushWeight(s.ctx, 3, 0.5)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestInjectDataError() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"tell", "osd.3", "injectdataerr", "test-pool", "object-name"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.InjectDataError(s.ctx, 3, "test-pool", "object-name")
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestGetFlags() {
+	stdout, err := os.ReadFile("testdata/osd-dump-flags.json")
+	s.Require().NoError(err)
+
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "dump", "--format=json"}).Return(stdout, []byte{}, nil).Once()
+
+	flags, err := s.cluster.GetFlags(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]ceph.Flag{ceph.FlagNoOut, "sortbitwise", "recovery_deletes", "purged_snapdirs", "pglog_hardlimit"}, flags)
+}
+
+func (s *cephTestSuite) TestGetPGUpmapItems() {
+	stdout, err := os.ReadFile("testdata/osd-dump.json")
+	s.Require().NoError(err)
+
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "dump", "--format=json"}).Return(stdout, []byte{}, nil).Once()
+
+	items, err := s.cluster.GetPGUpmapItems(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]ceph.PGUpmapItems{
+		{
+			PGID:     "1.0",
+			Mappings: []ceph.UpmapMapping{{From: 3, To: 5}},
+		},
+		{
+			PGID:     "2.1f",
+			Mappings: []ceph.UpmapMapping{{From: 0, To: 1}, {From: 2, To: 4}},
+		},
+	}, items)
+}
+
+func (s *cephTestSuite) TestSetPGUpmapItems() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "pg-upmap-items", "1.0", "3", "5", "2", "4"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.SetPGUpmapItems(s.ctx, "1.0", ceph.UpmapMapping{From: 3, To: 5}, ceph.UpmapMapping{From: 2, To: 4})
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestRemovePGUpmapItems() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"osd", "rm-pg-upmap-items", "1.0"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.RemovePGUpmapItems(s.ctx, "1.0")
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestGetBalancerStatus() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"balancer", "status", "--format=json"}).Return([]byte(`{
+		"active": true,
+		"last_optimize_duration": "0:00:00.000411",
+		"last_optimize_started": "Tue Apr  8 09:14:53 2025",
+		"mode": "upmap",
+		"no_optimization_needed": true,
+		"optimize_result": "Unable to find further optimization, or pool(s) pg_num is decreasing, or distribution is already perfect",
+		"plans": []
+	}`), []byte{}, nil).Once()
+
+	status, err := s.cluster.GetBalancerStatus(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal(ceph.BalancerStatus{
+		Active:               true,
+		Mode:                 ceph.BalancerModeUpmap,
+		LastOptimizeDuration: "0:00:00.000411",
+		LastOptimizeStarted:  "Tue Apr  8 09:14:53 2025",
+		NoOptimizationNeeded: true,
+		OptimizeResult:       "Unable to find further optimization, or pool(s) pg_num is decreasing, or distribution is already perfect",
+	}, status)
+}
+
+func (s *cephTestSuite) TestSetBalancerMode() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"balancer", "mode", "crush-compat"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.SetBalancerMode(s.ctx, ceph.BalancerModeCrushCompat)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestEnableBalancer() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"balancer", "on"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.EnableBalancer(s.ctx)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestDisableBalancer() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"balancer", "off"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.DisableBalancer(s.ctx)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestGetFilesystems() {
+	stdout, err := os.ReadFile("testdata/fs-dump.json")
+	s.Require().NoError(err)
+
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"fs", "dump", "--format=json"}).Return(stdout, []byte{}, nil).Once()
+
+	fss, err := s.cluster.GetFilesystems(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]ceph.Filesystem{
+		{
+			ID:           1,
+			Name:         "cephfs",
+			MaxMDS:       1,
+			Joinable:     true,
+			In:           []int64{0},
+			Failed:       []int64{},
+			Damaged:      []int64{},
+			MetadataPool: 2,
+			DataPools:    []int64{3},
+			Enabled:      true,
+		},
+	}, fss)
+}
+
+func (s *cephTestSuite) TestGetMDSDaemons() {
+	stdout, err := os.ReadFile("testdata/fs-dump.json")
+	s.Require().NoError(err)
+
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"fs", "dump", "--format=json"}).Return(stdout, []byte{}, nil).Once()
+
+	daemons, err := s.cluster.GetMDSDaemons(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]ceph.MDS{
+		{
+			GID:    24155,
+			Name:   "cephfs.ceph01.xjlmnb",
+			Rank:   0,
+			State:  "up:active",
+			Addr:   "100.64.65.19:6801/3062541238",
+			FSName: "cephfs",
+		},
+		{
+			GID:   24161,
+			Name:  "cephfs.ceph02.ebqkqx",
+			Rank:  -1,
+			State: "up:standby",
+			Addr:  "100.64.65.20:6801/2341846152",
+		},
+	}, daemons)
+}
+
+func (s *cephTestSuite) TestFailMDS() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"mds", "fail", "cephfs.ceph01.xjlmnb"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.FailMDS(s.ctx, "cephfs.ceph01.xjlmnb")
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestSetMaxMDS() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"fs", "set", "cephfs", "max_mds", "3"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.SetMaxMDS(s.ctx, "cephfs", 3)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestSetFilesystemJoinable() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"fs", "set", "cephfs", "joinable", "false"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.SetFilesystemJoinable(s.ctx, "cephfs", false)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestSetFilesystemDown() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"fs", "set", "cephfs", "down", "true"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.SetFilesystemDown(s.ctx, "cephfs", true)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestListRGWDaemons() {
+	stdout, err := os.ReadFile("testdata/orch-ps-rgw.json")
+	s.Require().NoError(err)
+
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"orch", "ps", "--daemon-type", "rgw", "--format=json"}).Return(stdout, []byte{}, nil).Once()
+
+	daemons, err := s.cluster.ListRGWDaemons(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]ceph.Daemon{
+		{
+			DaemonType:  "rgw",
+			DaemonID:    "objects.ceph01.tqwbbd",
+			DaemonName:  "rgw.objects.ceph01.tqwbbd",
+			Hostname:    "ceph01",
+			ServiceName: "rgw.objects",
+			Status:      1,
+			StatusDesc:  "running",
+			Ports:       []int{80},
+		},
+		{
+			DaemonType:  "rgw",
+			DaemonID:    "objects.ceph02.qkzhwv",
+			DaemonName:  "rgw.objects.ceph02.qkzhwv",
+			Hostname:    "ceph02",
+			ServiceName: "rgw.objects",
+			Status:      0,
+			StatusDesc:  "stopped",
+			Ports:       []int{80},
+		},
+	}, daemons)
+}
+
+func (s *cephTestSuite) TestStopDaemon() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"orch", "daemon", "stop", "rgw.objects.ceph01.tqwbbd"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.StopDaemon(s.ctx, "rgw.objects.ceph01.tqwbbd")
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestStartDaemon() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"orch", "daemon", "start", "rgw.objects.ceph01.tqwbbd"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.StartDaemon(s.ctx, "rgw.objects.ceph01.tqwbbd")
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestRestartDaemon() {
+	s.runnerMock.On("RunCephBinary", []byte(nil), []string{"orch", "daemon", "restart", "rgw.objects.ceph01.tqwbbd"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.RestartDaemon(s.ctx, "rgw.objects.ceph01.tqwbbd")
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestListBuckets() {
+	s.runnerMock.On("RunRadosGWAdminBinary", []byte(nil), []string{"bucket", "list"}).Return([]byte(`["bucket1","bucket2"]`), []byte{}, nil).Once()
+
+	buckets, err := s.cluster.ListBuckets(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"bucket1", "bucket2"}, buckets)
+}
+
+func (s *cephTestSuite) TestSetBucketQuota() {
+	s.runnerMock.On("RunRadosGWAdminBinary", []byte(nil), []string{"quota", "set", "--quota-scope=bucket", "--bucket=bucket1", "--max-objects=10", "--max-size=1048576"}).Return([]byte{}, []byte{}, nil).Once()
+	s.runnerMock.On("RunRadosGWAdminBinary", []byte(nil), []string{"quota", "enable", "--quota-scope=bucket", "--bucket=bucket1"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.SetBucketQuota(s.ctx, "bucket1", 10, 1048576)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestListZones() {
+	s.runnerMock.On("RunRadosGWAdminBinary", []byte(nil), []string{"zone", "list"}).Return([]byte(`{"default_info":"","zones":["default"]}`), []byte{}, nil).Once()
+
+	zones, err := s.cluster.ListZones(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"default"}, zones)
+}
+
+func (s *cephTestSuite) TestSetZoneReadOnly() {
+	s.runnerMock.On("RunRadosGWAdminBinary", []byte(nil), []string{"zone", "modify", "--rgw-zone=default", "--read-only=true"}).Return([]byte{}, []byte{}, nil).Once()
+	s.runnerMock.On("RunRadosGWAdminBinary", []byte(nil), []string{"period", "update", "--commit"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.SetZoneReadOnly(s.ctx, "default", true)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestSetZoneReadOnlyNoRealm() {
+	s.runnerMock.On("RunRadosGWAdminBinary", []byte(nil), []string{"zone", "modify", "--rgw-zone=default", "--read-only=true"}).Return([]byte{}, []byte{}, nil).Once()
+	s.runnerMock.On("RunRadosGWAdminBinary", []byte(nil), []string{"period", "update", "--commit"}).Return([]byte(nil), []byte("failed to read realm: (2) No such file or directory"), errors.New("exit status 2")).Once()
+
+	err := s.cluster.SetZoneReadOnly(s.ctx, "default", true)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestSetZoneReadOnlyCommitError() {
+	s.runnerMock.On("RunRadosGWAdminBinary", []byte(nil), []string{"zone", "modify", "--rgw-zone=default", "--read-only=true"}).Return([]byte{}, []byte{}, nil).Once()
+	s.runnerMock.On("RunRadosGWAdminBinary", []byte(nil), []string{"period", "update", "--commit"}).Return([]byte(nil), []byte("permission denied"), errors.New("exit status 1")).Once()
+
+	err := s.cluster.SetZoneReadOnly(s.ctx, "default", true)
+	s.Require().Error(err)
+}
+
+func (s *cephTestSuite) TestListRBDImages() {
+	s.runnerMock.On("RunRBDBinary", []byte(nil), []string{"ls", "--format=json", "pool"}).Return([]byte(`["image1","image2"]`), []byte{}, nil).Once()
+
+	images, err := s.cluster.ListRBDImages(s.ctx, "pool")
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"image1", "image2"}, images)
+}
+
+func (s *cephTestSuite) TestGetRBDImage() {
+	stdout, err := os.ReadFile("testdata/rbd-info.json")
+	s.Require().NoError(err)
+
+	s.runnerMock.On("RunRBDBinary", []byte(nil), []string{"info", "--format=json", "chaos-monkey-1234/chaos-monkey-rbd"}).Return(stdout, []byte{}, nil).Once()
+
+	image, err := s.cluster.GetRBDImage(s.ctx, "chaos-monkey-1234", "chaos-monkey-rbd")
+	s.Require().NoError(err)
+	s.Require().Equal(ceph.RBDImage{
+		Name:            "chaos-monkey-rbd",
+		ID:              "1f2b5a8c3e41",
+		Size:            16777216,
+		Objects:         4,
+		Order:           22,
+		ObjectSize:      4194304,
+		BlockNamePrefix: "rbd_data.1f2b5a8c3e41",
+		Format:          2,
+		Features:        []string{"layering", "exclusive-lock", "object-map", "fast-diff", "deep-flatten"},
+		Parent: &ceph.RBDParent{
+			Pool:     "chaos-monkey-1234",
+			Image:    "chaos-monkey-rbd-base",
+			Snapshot: "base",
+		},
+	}, image)
+}
+
+func (s *cephTestSuite) TestCreateRBDImage() {
+	s.runnerMock.On("RunRBDBinary", []byte(nil), []string{"create", "--size=16M", "pool/image"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.CreateRBDImage(s.ctx, "pool", "image", 16)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestResizeRBDImage() {
+	s.runnerMock.On("RunRBDBinary", []byte(nil), []string{"resize", "--size=8M", "--allow-shrink", "pool/image"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.ResizeRBDImage(s.ctx, "pool", "image", 8)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestFlattenRBDImage() {
+	s.runnerMock.On("RunRBDBinary", []byte(nil), []string{"flatten", "pool/image"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.FlattenRBDImage(s.ctx, "pool", "image")
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestCloneRBDImage() {
+	s.runnerMock.On("RunRBDBinary", []byte(nil), []string{"clone", "pool/base@snap", "pool/clone"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.CloneRBDImage(s.ctx, "pool", "base", "snap", "clone")
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestWriteRBDImage() {
+	diff := []byte("rbd diff v1\nw")
+	diff = append(diff, 0x00, 0x10, 0, 0, 0, 0, 0, 0)
+	diff = append(diff, 0x04, 0, 0, 0, 0, 0, 0, 0)
+	diff = append(diff, []byte("data")...)
+	diff = append(diff, 'e')
+
+	s.runnerMock.On("RunRBDBinary", diff, []string{"import-diff", "--no-progress", "-", "pool/image"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.WriteRBDImage(s.ctx, "pool", "image", 4096, []byte("data"))
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestExportRBDImage() {
+	s.runnerMock.On("RunRBDBinary", []byte(nil), []string{"export", "--no-progress", "pool/image", "-"}).Return([]byte("data"), []byte{}, nil).Once()
+
+	data, err := s.cluster.ExportRBDImage(s.ctx, "pool", "image")
+	s.Require().NoError(err)
+	s.Require().Equal([]byte("data"), data)
+}
+
+func (s *cephTestSuite) TestListRBDSnapshots() {
+	stdout, err := os.ReadFile("testdata/rbd-snap-ls.json")
+	s.Require().NoError(err)
+
+	s.runnerMock.On("RunRBDBinary", []byte(nil), []string{"snap", "ls", "--format=json", "pool/image"}).Return(stdout, []byte{}, nil).Once()
+
+	snaps, err := s.cluster.ListRBDSnapshots(s.ctx, "pool", "image")
+	s.Require().NoError(err)
+	s.Require().Equal([]ceph.RBDSnapshot{
+		{ID: 4, Name: "chaos-monkey-1", Size: 16777216, Protected: "false", Timestamp: "Tue Apr  8 10:25:40 2025"},
+		{ID: 5, Name: "chaos-monkey-2", Size: 16777216, Protected: "true", Timestamp: "Tue Apr  8 10:26:02 2025"},
+	}, snaps)
+	s.Require().False(snaps[0].IsProtected())
+	s.Require().True(snaps[1].IsProtected())
+}
+
+func (s *cephTestSuite) TestRBDSnapshotOperations() {
+	s.runnerMock.On("RunRBDBinary", []byte(nil), []string{"snap", "create", "pool/image@snap"}).Return([]byte{}, []byte{}, nil).Once()
+	s.runnerMock.On("RunRBDBinary", []byte(nil), []string{"snap", "protect", "pool/image@snap"}).Return([]byte{}, []byte{}, nil).Once()
+	s.runnerMock.On("RunRBDBinary", []byte(nil), []string{"snap", "rm", "--no-progress", "pool/image@snap"}).Return([]byte{}, []byte{}, nil).Once()
+
+	s.Require().NoError(s.cluster.CreateRBDSnapshot(s.ctx, "pool", "image", "snap"))
+	s.Require().NoError(s.cluster.ProtectRBDSnapshot(s.ctx, "pool", "image", "snap"))
+	s.Require().NoError(s.cluster.RemoveRBDSnapshot(s.ctx, "pool", "image", "snap"))
+}
+
+func (s *cephTestSuite) TestLockRBDImage() {
+	s.runnerMock.On("RunRBDBinary", []byte(nil), []string{"lock", "add", "pool/image", "chaos-monkey-lock"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.LockRBDImage(s.ctx, "pool", "image", "chaos-monkey-lock")
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestUnlockRBDImage() {
+	s.runnerMock.On("RunRBDBinary", []byte(nil), []string{"lock", "ls", "--format=json", "pool/image"}).Return([]byte(`[{"id":"other","locker":"client.4100","address":"192.168.1.10:0/1"},{"id":"chaos-monkey-lock","locker":"client.4125","address":"192.168.1.15:0/3416557163"}]`), []byte{}, nil).Once()
+	s.runnerMock.On("RunRBDBinary", []byte(nil), []string{"lock", "rm", "pool/image", "chaos-monkey-lock", "client.4125"}).Return([]byte{}, []byte{}, nil).Once()
+
+	err := s.cluster.UnlockRBDImage(s.ctx, "pool", "image", "chaos-monkey-lock")
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestUnlockRBDImageNotLocked() {
+	s.runnerMock.On("RunRBDBinary", []byte(nil), []string{"lock", "ls", "--format=json", "pool/image"}).Return([]byte(`[]`), []byte{}, nil).Once()
+
+	err := s.cluster.UnlockRBDImage(s.ctx, "pool", "image", "chaos-monkey-lock")
+	s.Require().Error(err)
+}
+
+func (s *cephTestSuite) TestListRBDWatchers() {
+	stdout, err := os.ReadFile("testdata/rbd-status.json")
+	s.Require().NoError(err)
+
+	s.runnerMock.On("RunRBDBinary", []byte(nil), []string{"status", "--format=json", "pool/image"}).Return(stdout, []byte{}, nil).Once()
+
+	watchers, err := s.cluster.ListRBDWatchers(s.ctx, "pool", "image")
+	s.Require().NoError(err)
+	s.Require().Equal([]ceph.RBDWatcher{
+		{Address: "192.168.1.15:0/3416557163", Client: 4125, Cookie: 140589423126528},
+	}, watchers)
+}
+
 // ======================= definitions =======================
 type cephTestSuite struct {
 	suite.Suite
@@ -1,5 +1,11 @@
 package ceph
 
+import (
+	"net"
+	"slices"
+	"strings"
+)
+
 type OSD struct {
 	HostName      string   `json:"host name"`
 	ID            uint64   `json:"id"`
@@ -10,6 +16,26 @@ type OSD struct {
 	State         []string `json:"state"`
 	WriteByteRate uint64   `json:"write byte rate"`
 	WriteOpsRate  uint64   `json:"write ops rate"`
+
+	// Weights are filled from `osd tree`, zero if it couldn't be fetched
+	CrushWeight     float64 `json:"crush_weight"`
+	Reweight        float64 `json:"reweight"`
+	PrimaryAffinity float64 `json:"primary_affinity"`
+}
+
+type OSDTreeNode struct {
+	ID              int64   `json:"id"`
+	Name            string  `json:"name"`
+	Type            string  `json:"type"`
+	TypeID          int     `json:"type_id"`
+	DeviceClass     string  `json:"device_class"`
+	Children        []int64 `json:"children"`
+	CrushWeight     float64 `json:"crush_weight"`
+	Depth           int     `json:"depth"`
+	Exists          int     `json:"exists"`
+	Status          string  `json:"status"`
+	Reweight        float64 `json:"reweight"`
+	PrimaryAffinity float64 `json:"primary_affinity"`
 }
 
 type Mon struct {
@@ -44,6 +70,8 @@ type Pool struct {
 	QuotaMaxObjects    uint64      `json:"quota_max_objects"`
 	ErasureCodeProfile string      `json:"erasure_code_profile"`
 	Options            PoolOptions `json:"options,omitempty"`
+
+	ApplicationMetadata map[string]map[string]string `json:"application_metadata,omitempty"`
 }
 
 type Flag string
@@ -57,6 +85,7 @@ const (
 	FlagPause       Flag = "pause"
 	FlagNoBackfill  Flag = "nobackfill"
 	FlagNoUp        Flag = "noup"
+	FlagNoDown      Flag = "nodown"
 	FlagNoRebalance Flag = "norebalance"
 )
 
@@ -89,3 +118,187 @@ type PGStat struct {
 	State string   `json:"state"`
 	Up    []uint64 `json:"up"`
 }
+
+// IsActiveClean reports whether the PG is active and clean whatever else it's
+// doing, e.g. `active+clean+scrubbing` or `active+clean+snaptrim`
+func (p PGStat) IsActiveClean() bool {
+	states := strings.Split(p.State, "+")
+	return slices.Contains(states, "active") && slices.Contains(states, "clean")
+}
+
+type ConfigOption struct {
+	Section            string `json:"section"`
+	Name               string `json:"name"`
+	Value              string `json:"value"`
+	Level              string `json:"level"`
+	CanUpdateAtRuntime bool   `json:"can_update_at_runtime"`
+	Mask               string `json:"mask"`
+}
+
+// Who returns the target of the option in the form accepted by
+// `ceph config set`, e.g. `global`, `osd.3` or `osd/host:ceph01`
+func (o ConfigOption) Who() string {
+	if o.Mask == "" {
+		return o.Section
+	}
+	return o.Section + "/" + o.Mask
+}
+
+type AuthEntity struct {
+	Entity string            `json:"entity"`
+	Key    string            `json:"key"`
+	Caps   map[string]string `json:"caps"`
+}
+
+type BlocklistEntry struct {
+	Addr  string `json:"addr"`
+	Until string `json:"until"`
+}
+
+// ClientSession is the client connection to the monitor
+type ClientSession struct {
+	Entity string
+	// Addr is the client instance address in the form of `<ip>:<port>/<nonce>`
+	Addr string
+}
+
+// HostAddr returns the address with zero port and nonce which blocklists all
+// the client instances connected from the host of the session
+func (s ClientSession) HostAddr() (string, error) {
+	addr, _, _ := strings.Cut(s.Addr, "/")
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, "0") + "/0", nil
+}
+
+type UpmapMapping struct {
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+}
+
+type PGUpmapItems struct {
+	PGID     string         `json:"pgid"`
+	Mappings []UpmapMapping `json:"mappings"`
+}
+
+type BalancerMode string
+
+const (
+	BalancerModeNone        BalancerMode = "none"
+	BalancerModeCrushCompat BalancerMode = "crush-compat"
+	BalancerModeUpmap       BalancerMode = "upmap"
+	BalancerModeRead        BalancerMode = "read"
+	BalancerModeUpmapRead   BalancerMode = "upmap-read"
+)
+
+type BalancerStatus struct {
+	Active               bool         `json:"active"`
+	Mode                 BalancerMode `json:"mode"`
+	LastOptimizeDuration string       `json:"last_optimize_duration"`
+	LastOptimizeStarted  string       `json:"last_optimize_started"`
+	NoOptimizationNeeded bool         `json:"no_optimization_needed"`
+	OptimizeResult       string       `json:"optimize_result"`
+}
+
+// Filesystem is a CephFS filesystem as described in its mdsmap
+type Filesystem struct {
+	ID           int64   `json:"id"`
+	Name         string  `json:"fs_name"`
+	MaxMDS       uint64  `json:"max_mds"`
+	Joinable     bool    `json:"joinable"`
+	In           []int64 `json:"in"`
+	Failed       []int64 `json:"failed"`
+	Damaged      []int64 `json:"damaged"`
+	MetadataPool int64   `json:"metadata_pool"`
+	DataPools    []int64 `json:"data_pools"`
+	Enabled      bool    `json:"enabled"`
+}
+
+type MDS struct {
+	GID   uint64 `json:"gid"`
+	Name  string `json:"name"`
+	Rank  int64  `json:"rank"`
+	State string `json:"state"`
+	Addr  string `json:"addr"`
+
+	// FSName is empty for standby daemons
+	FSName string `json:"-"`
+}
+
+// Daemon is a daemon managed by the orchestrator
+type Daemon struct {
+	DaemonType  string `json:"daemon_type"`
+	DaemonID    string `json:"daemon_id"`
+	DaemonName  string `json:"daemon_name"`
+	Hostname    string `json:"hostname"`
+	ServiceName string `json:"service_name"`
+	Status      int    `json:"status"`
+	StatusDesc  string `json:"status_desc"`
+	Ports       []int  `json:"ports"`
+}
+
+// RBDImage is the RBD image info
+type RBDImage struct {
+	Name            string     `json:"name"`
+	ID              string     `json:"id"`
+	Size            uint64     `json:"size"`
+	Objects         uint64     `json:"objects"`
+	Order           int        `json:"order"`
+	ObjectSize      uint64     `json:"object_size"`
+	BlockNamePrefix string     `json:"block_name_prefix"`
+	Format          int        `json:"format"`
+	Features        []string   `json:"features"`
+	Parent          *RBDParent `json:"parent,omitempty"`
+}
+
+// RBDParent is the snapshot RBD image is cloned from
+type RBDParent struct {
+	Pool     string `json:"pool"`
+	Image    string `json:"image"`
+	Snapshot string `json:"snapshot"`
+}
+
+// RBDSnapshot is the RBD image snapshot
+type RBDSnapshot struct {
+	ID        uint64 `json:"id"`
+	Name      string `json:"name"`
+	Size      uint64 `json:"size"`
+	Protected string `json:"protected"`
+	Timestamp string `json:"timestamp"`
+}
+
+// IsProtected reports whether the snapshot is protected from removal
+func (s RBDSnapshot) IsProtected() bool {
+	return s.Protected == "true"
+}
+
+// RBDLock is the advisory lock held on RBD image
+type RBDLock struct {
+	ID      string `json:"id"`
+	Locker  string `json:"locker"`
+	Address string `json:"address"`
+}
+
+// RBDWatcher is the client having RBD image opened
+type RBDWatcher struct {
+	Address string `json:"address"`
+	Client  uint64 `json:"client"`
+	Cookie  uint64 `json:"cookie"`
+}
+
+// ObjectMapping is the placement of RADOS object
+type ObjectMapping struct {
+	Epoch         uint64 `json:"epoch"`
+	Pool          string `json:"pool"`
+	PoolID        int    `json:"pool_id"`
+	ObjectName    string `json:"objname"`
+	RawPGID       string `json:"raw_pgid"`
+	PGID          string `json:"pgid"`
+	Up            []int  `json:"up"`
+	UpPrimary     int    `json:"up_primary"`
+	Acting        []int  `json:"acting"`
+	ActingPrimary int    `json:"acting_primary"`
+}
@@ -2,13 +2,24 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	kingpin "github.com/alecthomas/kingpin/v2"
 	log "github.com/sirupsen/logrus"
 	"github.com/teran/go-collection/random"
+	"golang.org/x/sys/unix"
 
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
 	cephShellDriver "github.com/teran/ceph-chaos-monkey/ceph/drivers/shell"
 	"github.com/teran/ceph-chaos-monkey/monkey"
 )
@@ -16,8 +27,16 @@ import (
 const (
 	appName = "ceph-chaos-monkey"
 
-	runCmd     = "run"
-	versionCmd = "version"
+	runCmd             = "run"
+	resumeCmd          = "resume"
+	controlCmd         = "control"
+	sessionCmd         = "session"
+	serveCmd           = "serve"
+	cleanupCmd         = "cleanup"
+	challengeListCmd   = "challenge list"
+	challengeStartCmd  = "challenge start"
+	challengeStatusCmd = "challenge status"
+	versionCmd         = "version"
 )
 
 var (
@@ -40,22 +59,251 @@ var (
 			Default("/usr/bin/rados").
 			String()
 
-	isRun        = app.Command(runCmd, "run the game")
-	fussInterval = isRun.
-			Flag("fuss-interval", "set fuss interval i.e. how often to trigger chaos behavior. Example: 2m for 2 minutes").
+	radosGWAdminBinaryPath = app.
+				Flag("radosgw-admin-binary", "path to the radosgw-admin binary").
+				Default("/usr/bin/radosgw-admin").
+				String()
+
+	rbdBinaryPath = app.
+			Flag("rbd-binary", "path to the rbd binary").
+			Default("/usr/bin/rbd").
+			String()
+
+	acknowledgedFSID = app.
+				Flag("i-understand-this-destroys-data", "fsid of the cluster to skip the confirmation questions for. Nothing is done if it doesn't match the target cluster. The session requires the comma separated fsids of all the roster clusters").
+				String()
+
+	isRun          = app.Command(runCmd, "run the game")
+	runPacingFlags = newPacingFlags(isRun)
+	seed           = isRun.
+			Flag("seed", "seed of the random the fusses are picked with to replay the same game. Random if not set").
+			Int64()
+
+	runSafetyFlags = newSafetyFlags(isRun)
+
+	console = isRun.
+		Flag("console", "read the trainee commands such as `hint` from stdin during the game").
+		Bool()
+
+	blind = isRun.
+		Flag("blind", "show only the incident IDs during the game and reveal the fusses once diagnosed with `diagnose` console command. Implies --console").
+		Bool()
+
+	scenarioPaths = isRun.
+			Flag("scenario", "path to the YAML file with the scenario to play during the game. Could be repeated").
+			Strings()
+
+	scenariosOnly = isRun.
+			Flag("scenarios-only", "play only the scenarios without the random fusses").
+			Bool()
+
+	ioProfilePath = isRun.
+			Flag("io-profile", "path to the YAML file with the background IO workload profile. The flags below override its options").
+			String()
+
+	ioSizeDistributionSet bool
+	ioSizeDistribution    = isRun.
+				Flag("io-object-size-distribution", "object size distribution of the background IO").
+				IsSetByUser(&ioSizeDistributionSet).
+				Enum(string(monkey.SizeDistributionFixed), string(monkey.SizeDistributionUniform), string(monkey.SizeDistributionLogNormal))
+
+	ioObjectSizeSet bool
+	ioObjectSize    = isRun.
+			Flag("io-object-size", "object size for fixed distribution or median for lognormal one. Example: 256KiB").
+			IsSetByUser(&ioObjectSizeSet).
+			Bytes()
+
+	ioObjectSizeMinSet bool
+	ioObjectSizeMin    = isRun.
+				Flag("io-object-size-min", "minimal object size for uniform and lognormal distributions").
+				IsSetByUser(&ioObjectSizeMinSet).
+				Bytes()
+
+	ioObjectSizeMaxSet bool
+	ioObjectSizeMax    = isRun.
+				Flag("io-object-size-max", "maximal object size for uniform and lognormal distributions").
+				IsSetByUser(&ioObjectSizeMaxSet).
+				Bytes()
+
+	ioMixSet bool
+	ioMix    = isRun.
+			Flag("io-mix", "relative weights of read, write and delete operations. Example: 60:35:5").
+			IsSetByUser(&ioMixSet).
+			String()
+
+	ioOpsPerSecondSet bool
+	ioOpsPerSecond    = isRun.
+				Flag("io-ops-per-second", "target rate of the background IO operations, 0 for unlimited").
+				IsSetByUser(&ioOpsPerSecondSet).
+				Float64()
+
+	ioConcurrencySet bool
+	ioConcurrency    = isRun.
+				Flag("io-concurrency", "number of the background IO workers").
+				IsSetByUser(&ioConcurrencySet).
+				Int()
+
+	ioMaxFootprintSet bool
+	ioMaxFootprint    = isRun.
+				Flag("io-max-footprint", "maximal amount of data written by the background IO to the pool. Example: 1GiB").
+				IsSetByUser(&ioMaxFootprintSet).
+				Bytes()
+
+	ledgerDir = isRun.
+			Flag("ledger-dir", "directory to keep the ledger of acknowledged background IO writes in").
+			Default(os.TempDir()).
+			String()
+
+	reportDir = isRun.
+			Flag("report-dir", "directory to save the final report of the game to").
+			Default(os.TempDir()).
+			String()
+
+	dashboard = isRun.
+			Flag("dashboard", "show the live full screen dashboard with the cluster state and the background IO graphs during the game. Requires the terminal").
+			Bool()
+
+	controlSocket = isRun.
+			Flag("control-socket", "path to the UNIX socket to listen for the instructor commands steering the game on, see `control`. Leave empty to disable").
+			String()
+
+	checkpointDir = isRun.
+			Flag("checkpoint-dir", "directory to save the game checkpoints to so the game could be resumed with `resume` if the process dies. Leave empty to disable").
+			Default(os.TempDir()).
+			String()
+
+	cephFSDir = isRun.
+			Flag("cephfs-dir", "directory on the mounted CephFS to run the background file workload in. Leave empty to disable").
+			String()
+
+	rbdWorkload = isRun.
+			Flag("rbd", "run the RBD block workload on the image in the background IO pool").
+			Bool()
+
+	s3Endpoint = isRun.
+			Flag("s3-endpoint", "RADOS Gateway S3 endpoint to run the background object workload against. Leave empty to disable").
+			String()
+
+	s3Region = isRun.
+			Flag("s3-region", "S3 region to sign requests for").
+			Default("us-east-1").
+			String()
+
+	s3AccessKey = isRun.
+			Flag("s3-access-key", "S3 access key").
+			Envar("S3_ACCESS_KEY").
+			String()
+
+	s3SecretKey = isRun.
+			Flag("s3-secret-key", "S3 secret key").
+			Envar("S3_SECRET_KEY").
+			String()
+
+	s3Bucket = isRun.
+			Flag("s3-bucket", "S3 bucket to run the background object workload in. Created if not exists").
+			Default("chaos-monkey").
+			String()
+
+	isResume       = app.Command(resumeCmd, "resume the game interrupted by the process death from its checkpoint")
+	checkpointPath = isResume.
+			Flag("checkpoint", "path to the checkpoint file of the game printed when it's started").
 			Required().
-			Duration()
+			String()
+	resumeSafetyFlags = newSafetyFlags(isResume)
+	resumeDashboard   = isResume.
+				Flag("dashboard", "show the live full screen dashboard with the cluster state and the background IO graphs during the game. Requires the terminal").
+				Bool()
+	resumeControlSocket = isResume.
+				Flag("control-socket", "path to the UNIX socket to listen for the instructor commands steering the game on, see `control`. Leave empty to disable").
+				String()
 
-	gameDuration = isRun.
-			Flag("game-duration", "set game duration i.e. overall time for chaos monkey to destroy Ceph cluster. Example 10m for 10 minutes").
+	isControl         = app.Command(controlCmd, "send the instructor command to the game listening on the control socket")
+	controlSocketPath = isControl.
+				Flag("socket", "path to the control socket of the game").
+				Required().
+				String()
+	controlArgs = isControl.
+			Arg("command", "pause, resume, extend <duration>, shorten <duration>, skip, trigger <fuss-id> or status").
 			Required().
-			Duration()
+			Strings()
+
+	isSession         = app.Command(sessionCmd, "play the same seeded game on the cluster of every trainee at the same time and print the leaderboard")
+	sessionRosterPath = isSession.
+				Flag("roster", "path to the YAML file with the trainees and their cluster connection settings").
+				Required().
+				String()
+	sessionPacingFlags = newPacingFlags(isSession)
+	sessionSafetyFlags = newSafetyFlags(isSession)
+	sessionSeed        = isSession.
+				Flag("seed", "seed of the random the fusses are picked with. Random if not set").
+				Int64()
+	sessionSSHBinaryPath = isSession.
+				Flag("ssh-binary", "path to the ssh binary used by the trainees with ssh runner").
+				Default("/usr/bin/ssh").
+				String()
+	sessionLedgerDir = isSession.
+				Flag("ledger-dir", "directory to keep the ledgers of acknowledged background IO writes in").
+				Default(os.TempDir()).
+				String()
+	sessionReportDir = isSession.
+				Flag("report-dir", "directory to save the final reports of the games to").
+				Default(os.TempDir()).
+				String()
+
+	isServe     = app.Command(serveCmd, "serve the web UI and the HTTP API to run and observe the games")
+	serveListen = isServe.
+			Flag("listen", "address to serve the web UI and the HTTP API on").
+			Default("127.0.0.1:8080").
+			String()
+	serveSafetyFlags = newSafetyFlags(isServe)
+	serveLedgerDir   = isServe.
+				Flag("ledger-dir", "directory to keep the ledgers of acknowledged background IO writes in").
+				Default(os.TempDir()).
+				String()
+	serveReportDir = isServe.
+			Flag("report-dir", "directory to save the final reports of the games to").
+			Default(os.TempDir()).
+			String()
+
+	isCleanup        = app.Command(cleanupCmd, "remove pools, client keys and other artifacts left by the past games")
+	cleanupLedgerDir = isCleanup.
+				Flag("ledger-dir", "directory the ledgers of the past games are kept in").
+				Default(os.TempDir()).
+				String()
+	cleanupCheckpointDir = isCleanup.
+				Flag("checkpoint-dir", "directory the checkpoints of the past games are kept in, the config overrides and blocklist entries recorded there are reverted").
+				Default(os.TempDir()).
+				String()
+	cleanupConfigOverrides = isCleanup.
+				Flag("config-override", "config override to remove in the form of <who>/<name>, e.g. osd/osd_max_backfills, besides the ones recorded by the games. Repeatable").
+				Strings()
+	cleanupSafetyFlags = newSafetyFlags(isCleanup)
+
+	isChallenge       = app.Command("challenge", "inject the specific fault and verify the trainee has fixed it")
+	challengeStateDir = isChallenge.
+				Flag("state-dir", "directory to keep the state of the current challenge in").
+				Default(os.TempDir()).
+				String()
+
+	_ = isChallenge.Command("list", "list the challenges")
+
+	isChallengeStart = isChallenge.Command("start", "start the challenge")
+	challengeID      = isChallengeStart.
+				Arg("id", "ID of the challenge from `challenge list`").
+				Required().
+				String()
+	challengeSafetyFlags = newSafetyFlags(isChallengeStart)
+
+	isChallengeStatus = isChallenge.Command("status", "verify the current challenge and print pass or fail with the elapsed time")
+	challengeWait     = isChallengeStatus.
+				Flag("wait", "wait until the challenge is passed or its time limit is over").
+				Bool()
 
 	_ = app.Command(versionCmd, "print version and exit")
 )
 
 func main() {
-	ctx := context.TODO()
+	ctx := signalContext()
 	appCmd := kingpin.MustParse(app.Parse(os.Args[1:]))
 
 	if isTrace != nil && *isTrace {
@@ -64,18 +312,536 @@ func main() {
 
 	switch appCmd {
 	case runCmd:
-		runner := cephShellDriver.NewRunner(*cephBinaryPath, *radosBinaryPath)
+		runner := cephShellDriver.NewRunner(*cephBinaryPath, *radosBinaryPath, *radosGWAdminBinaryPath, *rbdBinaryPath)
 		cluster := cephShellDriver.New(runner)
-		printer := monkey.NewPrinter()
 		stats := monkey.NewStats()
 
-		m := monkey.New(cluster, random.GetRand(), printer, stats, *fussInterval, *gameDuration)
+		profile, err := workloadProfile()
+		if err != nil {
+			panic(err)
+		}
+
+		policy, err := safetyPolicy(runSafetyFlags)
+		if err != nil {
+			panic(err)
+		}
+
+		// the game is always seeded so the random stream position could be
+		// checkpointed
+		s := *seed
+		if s == 0 {
+			s = time.Now().UnixNano()
+		}
+
+		interval, duration, opts := gamePacing(runPacingFlags)
+		opts = append(opts, monkey.WithSeed(s))
+
+		opts = append(opts,
+			monkey.WithSafetyPolicy(policy),
+			monkey.WithConfirmer(confirmer(cluster)),
+			monkey.WithWorkloadProfile(profile),
+			monkey.WithLedgerDir(*ledgerDir),
+			monkey.WithReportDir(*reportDir),
+			monkey.WithCheckpointDir(*checkpointDir),
+			monkey.WithControlSocket(*controlSocket),
+		)
+		if *dashboard {
+			opts = withDashboard(opts)
+		}
+		for _, path := range *scenarioPaths {
+			sc, err := monkey.LoadScenario(path)
+			if err != nil {
+				panic(err)
+			}
+			opts = append(opts, monkey.WithScenarios(sc))
+		}
+
+		if *scenariosOnly {
+			if len(*scenarioPaths) == 0 {
+				panic("--scenarios-only requires at least one --scenario")
+			}
+			opts = append(opts, monkey.WithScenariosOnly())
+		}
+
+		if *console || *blind {
+			opts = append(opts, monkey.WithConsole(os.Stdin))
+		}
+
+		if *blind {
+			opts = append(opts, monkey.WithBlindMode())
+		}
+
+		if *cephFSDir != "" {
+			opts = append(opts, monkey.WithCephFSWorkload(*cephFSDir, random.GetRand()))
+		}
+
+		if *rbdWorkload {
+			opts = append(opts, monkey.WithRBDWorkload(random.GetRand()))
+		}
+
+		if *s3Endpoint != "" {
+			opt, err := monkey.WithS3Workload(monkey.S3WorkloadConfig{
+				Endpoint:  *s3Endpoint,
+				Region:    *s3Region,
+				AccessKey: *s3AccessKey,
+				SecretKey: *s3SecretKey,
+				Bucket:    *s3Bucket,
+			}, random.GetRand())
+			if err != nil {
+				panic(err)
+			}
+			opts = append(opts, opt)
+		}
+
+		m := monkey.New(cluster, random.GetRand(), monkey.NewPrinter(), stats, interval, duration, opts...)
 		if err := m.Run(ctx); err != nil {
 			panic(err)
 		}
 		return
+	case resumeCmd:
+		runner := cephShellDriver.NewRunner(*cephBinaryPath, *radosBinaryPath, *radosGWAdminBinaryPath, *rbdBinaryPath)
+		cluster := cephShellDriver.New(runner)
+
+		policy, err := safetyPolicy(resumeSafetyFlags)
+		if err != nil {
+			panic(err)
+		}
+
+		// the console is kept only if the game has been played with it
+		opts := []monkey.Option{
+			monkey.WithSafetyPolicy(policy),
+			monkey.WithConfirmer(confirmer(cluster)),
+			monkey.WithConsole(os.Stdin),
+			monkey.WithControlSocket(*resumeControlSocket),
+		}
+		if *resumeDashboard {
+			opts = withDashboard(opts)
+		}
+
+		m, err := monkey.Resume(cluster, *checkpointPath, monkey.NewPrinter(), opts...)
+		if err != nil {
+			panic(err)
+		}
+
+		if err := m.Run(ctx); err != nil {
+			panic(err)
+		}
+		return
+	case controlCmd:
+		if err := monkey.SendControl(ctx, *controlSocketPath, *controlArgs, os.Stdout); err != nil {
+			panic(err)
+		}
+		return
+	case sessionCmd:
+		roster, err := monkey.LoadRoster(*sessionRosterPath)
+		if err != nil {
+			panic(err)
+		}
+
+		if err := roster.CheckAcknowledged(*acknowledgedFSID); err != nil {
+			monkey.NewPrinter().Printf("Refusing to run the session: %s\n", err)
+			return
+		}
+
+		policy, err := safetyPolicy(sessionSafetyFlags)
+		if err != nil {
+			panic(err)
+		}
+
+		players := []monkey.Player{}
+		for _, t := range roster.Trainees {
+			cluster := cephShellDriver.New(traineeRunner(t))
+			players = append(players, monkey.Player{
+				Name:      t.Name,
+				Cluster:   cluster,
+				Confirmer: monkey.NewFSIDConfirmer(cluster, t.FSID),
+			})
+		}
+
+		interval, duration, opts := gamePacing(sessionPacingFlags)
+		opts = append(opts,
+			monkey.WithSafetyPolicy(policy),
+			monkey.WithLedgerDir(*sessionLedgerDir),
+			monkey.WithReportDir(*sessionReportDir),
+		)
+
+		s := *sessionSeed
+		if s == 0 {
+			s = time.Now().UnixNano()
+		}
+
+		if err := monkey.NewSession(players, monkey.NewPrinter(), s, interval, duration, opts...).Run(ctx); err != nil {
+			panic(err)
+		}
+		return
+	case serveCmd:
+		runner := cephShellDriver.NewRunner(*cephBinaryPath, *radosBinaryPath, *radosGWAdminBinaryPath, *rbdBinaryPath)
+		cluster := cephShellDriver.New(runner)
+
+		policy, err := safetyPolicy(serveSafetyFlags)
+		if err != nil {
+			panic(err)
+		}
+
+		srv := monkey.NewServer(cluster, random.GetRand(),
+			monkey.WithSafetyPolicy(policy),
+			monkey.WithLedgerDir(*serveLedgerDir),
+			monkey.WithReportDir(*serveReportDir),
+		)
+
+		if err := serve(ctx, *serveListen, srv); err != nil {
+			panic(err)
+		}
+		return
+	case cleanupCmd:
+		runner := cephShellDriver.NewRunner(*cephBinaryPath, *radosBinaryPath, *radosGWAdminBinaryPath, *rbdBinaryPath)
+		cluster := cephShellDriver.New(runner)
+
+		policy, err := safetyPolicy(cleanupSafetyFlags)
+		if err != nil {
+			panic(err)
+		}
+
+		c := monkey.NewCleaner(cluster, monkey.NewPrinter(), confirmer(cluster), policy, *cleanupLedgerDir, *cleanupCheckpointDir, *cleanupConfigOverrides)
+		if err := c.Run(ctx); err != nil {
+			panic(err)
+		}
+		return
+	case challengeListCmd, challengeStartCmd, challengeStatusCmd:
+		runner := cephShellDriver.NewRunner(*cephBinaryPath, *radosBinaryPath, *radosGWAdminBinaryPath, *rbdBinaryPath)
+		cluster := cephShellDriver.New(runner)
+
+		policy := monkey.SafetyPolicy{}
+		if appCmd == challengeStartCmd {
+			var err error
+			policy, err = safetyPolicy(challengeSafetyFlags)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		c := monkey.NewChallenger(cluster, random.GetRand(), monkey.NewPrinter(), confirmer(cluster), policy, *challengeStateDir)
+
+		var err error
+		switch appCmd {
+		case challengeListCmd:
+			c.List()
+		case challengeStartCmd:
+			err = c.Start(ctx, *challengeID)
+		case challengeStatusCmd:
+			err = c.Status(ctx, *challengeWait)
+		}
+		if err != nil {
+			panic(err)
+		}
+		return
 	case versionCmd:
 		fmt.Printf("%s v%s (built @ %s)\n", appName, appVersion, buildTimestamp)
 		os.Exit(1)
 	}
 }
+
+// signalContext returns the context canceled on the first SIGINT or SIGTERM so
+// the game is finished gracefully. The second signal terminates the process.
+func signalContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-sigCh
+		log.Warn("Interrupted, finishing the game. Send the signal again to exit immediately")
+		cancel()
+
+		<-sigCh
+		log.Warn("Interrupted again, exiting")
+		runForcedExitHooks()
+		os.Exit(130)
+	}()
+
+	return ctx
+}
+
+var (
+	forcedExitMutex sync.Mutex
+	forcedExitHooks []func()
+)
+
+// onForcedExit registers fn to be run before the process is terminated by the
+// second signal, e.g. to restore the terminal
+func onForcedExit(fn func()) {
+	forcedExitMutex.Lock()
+	defer forcedExitMutex.Unlock()
+
+	forcedExitHooks = append(forcedExitHooks, fn)
+}
+
+func runForcedExitHooks() {
+	forcedExitMutex.Lock()
+	defer forcedExitMutex.Unlock()
+
+	for _, fn := range forcedExitHooks {
+		fn()
+	}
+}
+
+// traineeRunner returns the runner for the trainee cluster falling back to the
+// global binary paths
+func traineeRunner(t monkey.Trainee) cephShellDriver.Runner {
+	orDefault := func(v, def string) string {
+		if v == "" {
+			return def
+		}
+		return v
+	}
+
+	ceph := orDefault(t.CephBinary, *cephBinaryPath)
+	rados := orDefault(t.RadosBinary, *radosBinaryPath)
+	radosGWAdmin := orDefault(t.RadosGWAdminBinary, *radosGWAdminBinaryPath)
+	rbd := orDefault(t.RBDBinary, *rbdBinaryPath)
+
+	var runner cephShellDriver.Runner
+	if t.Runner == monkey.RunnerSSH {
+		runner = cephShellDriver.NewSSHRunner(*sessionSSHBinaryPath, t.Host, ceph, rados, radosGWAdmin, rbd)
+	} else {
+		runner = cephShellDriver.NewRunner(ceph, rados, radosGWAdmin, rbd)
+	}
+
+	return cephShellDriver.WithConfig(runner, t.Conf, t.Keyring)
+}
+
+// serve runs the HTTP server until ctx is done, the game in progress is
+// aborted and its rollbacks are done before the server is shut down
+func serve(ctx context.Context, addr string, srv *monkey.Server) error {
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           srv,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Infof("serving the web UI on http://%s", addr)
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		srv.Close()
+		return err
+	case <-ctx.Done():
+	}
+
+	srv.Close()
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// withDashboard adds the dashboard fitting the terminal to the options. The
+// plain output is kept if the stdout is not the terminal. The logs are shown
+// in the game output while the dashboard is drawn and the terminal is
+// restored even if the process is terminated by the second signal.
+func withDashboard(opts []monkey.Option) []monkey.Option {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		log.Warnf("stdout is not a terminal, the dashboard is disabled: %s", err)
+		return opts
+	}
+
+	d := monkey.NewDashboard(os.Stdout, int(ws.Col), int(ws.Row))
+	log.SetOutput(d.LogWriter(os.Stderr))
+	onForcedExit(d.Close)
+
+	return append(opts, monkey.WithUI(d))
+}
+
+func confirmer(cluster drivers.Cluster) monkey.Confirmer {
+	if *acknowledgedFSID != "" {
+		return monkey.NewFSIDConfirmer(cluster, *acknowledgedFSID)
+	}
+	return monkey.NewStdinConfirmer()
+}
+
+// pacingFlags are shared by the commands playing the game
+type pacingFlags struct {
+	interval *time.Duration
+	duration *time.Duration
+	level    *string
+}
+
+func newPacingFlags(cmd *kingpin.CmdClause) pacingFlags {
+	return pacingFlags{
+		interval: cmd.
+			Flag("fuss-interval", "set fuss interval i.e. how often to trigger chaos behavior. Example: 2m for 2 minutes. Required unless --level is set").
+			Duration(),
+		duration: cmd.
+			Flag("game-duration", "set game duration i.e. overall time for chaos monkey to destroy Ceph cluster. Example 10m for 10 minutes. Required unless --level is set").
+			Duration(),
+		level: cmd.
+			Flag("level", "difficulty level picking the fusses, their weights and the game pacing").
+			Enum(string(monkey.LevelBeginner), string(monkey.LevelIntermediate), string(monkey.LevelExpert)),
+	}
+}
+
+// gamePacing returns the fuss interval and the game duration falling back to
+// the level defaults
+func gamePacing(f pacingFlags) (time.Duration, time.Duration, []monkey.Option) {
+	opts := []monkey.Option{}
+
+	interval, duration := *f.interval, *f.duration
+	if *f.level != "" {
+		lp, err := monkey.GetLevelProfile(monkey.Level(*f.level))
+		if err != nil {
+			panic(err)
+		}
+
+		if interval == 0 {
+			interval = lp.Interval
+		}
+
+		if duration == 0 {
+			duration = lp.Duration
+		}
+
+		opts = append(opts, monkey.WithLevel(lp))
+	}
+
+	if interval == 0 || duration == 0 {
+		panic("--fuss-interval and --game-duration are required unless --level is set")
+	}
+
+	return interval, duration, opts
+}
+
+// safetyFlags are shared by the commands breaking the cluster
+type safetyFlags struct {
+	fsidAllowlistPath         *string
+	productionConfigKey       *string
+	productionPoolApplication *string
+	policyPath                *string
+	policyPublicKeyPath       *string
+}
+
+func newSafetyFlags(cmd *kingpin.CmdClause) safetyFlags {
+	return safetyFlags{
+		fsidAllowlistPath: cmd.
+			Flag("fsid-allowlist", "path to the file with fsids of the clusters the game is allowed to be run against, one per line").
+			Required().
+			String(),
+		productionConfigKey: cmd.
+			Flag("production-config-key", "refuse the game on the cluster having the config-key set. Leave empty to disable").
+			Default("production").
+			String(),
+		productionPoolApplication: cmd.
+			Flag("production-pool-application", "refuse the game on the cluster having any pool tagged with the application. Leave empty to disable").
+			Default("production").
+			String(),
+		policyPath: cmd.
+			Flag("policy", "path to the YAML file with the fusses allowed to be triggered. All of them are allowed if not set").
+			String(),
+		policyPublicKeyPath: cmd.
+			Flag("policy-public-key", "path to the PEM encoded ed25519 public key the allowlist and policy files must be signed with, the signatures are read from the files with .sig suffix. Signatures are not checked if not set").
+			String(),
+	}
+}
+
+func safetyPolicy(f safetyFlags) (monkey.SafetyPolicy, error) {
+	var key ed25519.PublicKey
+	if *f.policyPublicKeyPath != "" {
+		var err error
+		key, err = monkey.LoadPolicyPublicKey(*f.policyPublicKeyPath)
+		if err != nil {
+			return monkey.SafetyPolicy{}, err
+		}
+	}
+
+	fsids, err := monkey.LoadFSIDAllowlist(*f.fsidAllowlistPath, key)
+	if err != nil {
+		return monkey.SafetyPolicy{}, err
+	}
+
+	policy := monkey.SafetyPolicy{
+		AllowedFSIDs:              fsids,
+		ProductionConfigKey:       *f.productionConfigKey,
+		ProductionPoolApplication: *f.productionPoolApplication,
+	}
+
+	if *f.policyPath != "" {
+		policy.AllowedFusses, err = monkey.LoadAllowedFusses(*f.policyPath, key)
+		if err != nil {
+			return monkey.SafetyPolicy{}, err
+		}
+	}
+
+	return policy, nil
+}
+
+func workloadProfile() (monkey.WorkloadProfile, error) {
+	profile := monkey.DefaultWorkloadProfile()
+	if *ioProfilePath != "" {
+		p, err := monkey.LoadWorkloadProfile(*ioProfilePath)
+		if err != nil {
+			return monkey.WorkloadProfile{}, err
+		}
+		profile = p
+	}
+
+	if ioSizeDistributionSet {
+		profile.ObjectSize.Distribution = monkey.SizeDistribution(*ioSizeDistribution)
+	}
+
+	if ioObjectSizeSet {
+		profile.ObjectSize.Size = *ioObjectSize
+	}
+
+	if ioObjectSizeMinSet {
+		profile.ObjectSize.Min = *ioObjectSizeMin
+	}
+
+	if ioObjectSizeMaxSet {
+		profile.ObjectSize.Max = *ioObjectSizeMax
+	}
+
+	if ioMixSet {
+		parts := strings.Split(*ioMix, ":")
+		if len(parts) != 3 {
+			return monkey.WorkloadProfile{}, fmt.Errorf("mix must be in `read:write:delete` format, got `%s`", *ioMix)
+		}
+
+		weights := []uint{}
+		for _, p := range parts {
+			v, err := strconv.ParseUint(p, 10, 32)
+			if err != nil {
+				return monkey.WorkloadProfile{}, fmt.Errorf("invalid weight `%s` in mix: %w", p, err)
+			}
+			weights = append(weights, uint(v))
+		}
+
+		profile.Mix = monkey.OpsMix{Read: weights[0], Write: weights[1], Delete: weights[2]}
+	}
+
+	if ioOpsPerSecondSet {
+		profile.OpsPerSecond = *ioOpsPerSecond
+	}
+
+	if ioConcurrencySet {
+		profile.Concurrency = *ioConcurrency
+	}
+
+	if ioMaxFootprintSet {
+		profile.MaxFootprint = *ioMaxFootprint
+	}
+
+	return profile, profile.Validate()
+}
@@ -0,0 +1,183 @@
+package monkey
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+const auditTimeout = 10 * time.Minute
+
+type auditStatus string
+
+const (
+	auditStatusIntact     auditStatus = "intact"
+	auditStatusMissing    auditStatus = "missing"
+	auditStatusCorrupt    auditStatus = "corrupt"
+	auditStatusUnreadable auditStatus = "unreadable"
+)
+
+// auditLoss counts the objects which are not intact
+type auditLoss struct {
+	Missing    uint64
+	Corrupt    uint64
+	Unreadable uint64
+}
+
+func (l *auditLoss) observe(status auditStatus) {
+	switch status {
+	case auditStatusMissing:
+		l.Missing++
+	case auditStatusCorrupt:
+		l.Corrupt++
+	case auditStatusUnreadable:
+		l.Unreadable++
+	}
+}
+
+type auditReport struct {
+	Total  uint64
+	Intact uint64
+	auditLoss
+
+	// ByPG and ByOSDSet are counted for the objects which are not intact
+	ByPG     map[string]*auditLoss
+	ByOSDSet map[string]*auditLoss
+}
+
+func (r *auditReport) observe(status auditStatus, pg, osdSet string) {
+	r.Total++
+	if status == auditStatusIntact {
+		r.Intact++
+		return
+	}
+
+	r.auditLoss.observe(status)
+
+	if _, ok := r.ByPG[pg]; !ok {
+		r.ByPG[pg] = &auditLoss{}
+	}
+	r.ByPG[pg].observe(status)
+
+	if _, ok := r.ByOSDSet[osdSet]; !ok {
+		r.ByOSDSet[osdSet] = &auditLoss{}
+	}
+	r.ByOSDSet[osdSet].observe(status)
+}
+
+// audit reads back every object recorded in the ledger and
+// classifies it. It's performed on behalf of the admin client so the fusses
+// affecting the throwaway one don't spoil the verdict.
+func (m *monkey) audit(ctx context.Context) *auditReport {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditTimeout)
+	defer cancel()
+
+	report := &auditReport{
+		ByPG:     map[string]*auditLoss{},
+		ByOSDSet: map[string]*auditLoss{},
+	}
+	mutex := &sync.Mutex{}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(m.profile.Concurrency)
+
+	for _, e := range m.ledger.list() {
+		g.Go(func() error {
+			status := m.auditObject(ctx, e)
+
+			pg, osdSet := "unknown", "unknown"
+			if status != auditStatusIntact {
+				mapping, err := m.cluster.MapObject(ctx, e.Pool, e.Object)
+				if err != nil {
+					log.Debugf("error mapping object %s: %s", e.Object, err)
+				} else {
+					pg = mapping.PGID
+					osdSet = osdSetString(mapping.Acting)
+				}
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			report.observe(status, pg, osdSet)
+			return nil
+		})
+	}
+
+	_ = g.Wait()
+
+	return report
+}
+
+func (m *monkey) auditObject(ctx context.Context, e ledgerEntry) auditStatus {
+	data, err := m.cluster.ReadRADOSObject(ctx, e.Pool, e.Object)
+	if err != nil {
+		if errors.Is(err, drivers.ErrNotFound) {
+			return auditStatusMissing
+		}
+
+		log.Debugf("error reading object %s: %s", e.Object, err)
+		return auditStatusUnreadable
+	}
+
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != e.SHA256 {
+		return auditStatusCorrupt
+	}
+
+	return auditStatusIntact
+}
+
+func (m *monkey) printAudit(r *auditReport) {
+	m.printer.Printf("Data integrity audit of %d acknowledged objects:\n", r.Total)
+	m.printer.Printf("- intact: %d\n", r.Intact)
+	m.printer.Printf("- missing: %d\n", r.Missing)
+	m.printer.Printf("- corrupt: %d\n", r.Corrupt)
+	m.printer.Printf("- unreadable: %d\n", r.Unreadable)
+
+	if len(r.ByPG) > 0 {
+		m.printer.Println("Objects not intact by PG:")
+		for _, pg := range slices.Sorted(maps.Keys(r.ByPG)) {
+			m.printer.Printf("- %s: %s\n", pg, r.ByPG[pg])
+		}
+
+		m.printer.Println("Objects not intact by acting OSD set:")
+		for _, set := range slices.Sorted(maps.Keys(r.ByOSDSet)) {
+			m.printer.Printf("- %s: %s\n", set, r.ByOSDSet[set])
+		}
+	}
+
+	switch {
+	case r.Missing+r.Corrupt > 0:
+		m.printer.Printf("Verdict: DATA LOSS, %d of %d acknowledged objects are missing or corrupt\n", r.Missing+r.Corrupt, r.Total)
+	case r.Unreadable > 0:
+		m.printer.Printf("Verdict: no data loss detected but %d objects couldn't be verified\n", r.Unreadable)
+	default:
+		m.printer.Println("Verdict: no acknowledged writes were lost")
+	}
+}
+
+func (l *auditLoss) String() string {
+	return fmt.Sprintf("missing = %d, corrupt = %d, unreadable = %d", l.Missing, l.Corrupt, l.Unreadable)
+}
+
+func osdSetString(osds []int) string {
+	ids := []string{}
+	for _, id := range osds {
+		ids = append(ids, strconv.Itoa(id))
+	}
+	return "[" + strings.Join(ids, ",") + "]"
+}
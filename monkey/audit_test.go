@@ -0,0 +1,47 @@
+package monkey
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+func (s *cephTestSuite) TestAudit() {
+	m := s.newTestMonkey()
+	m.profile.Concurrency = 1
+
+	names := []string{}
+	for _, data := range []string{"intact", "missing", "corrupt", "unreadable"} {
+		sum := sha256.Sum256([]byte(data))
+		name := hex.EncodeToString(sum[:])
+		names = append(names, name)
+		s.Require().NoError(m.ledger.add(ledgerEntry{Object: name, Pool: "chaos-monkey-123", Size: uint64(len(data)), SHA256: name}))
+	}
+
+	s.cluster.On("ReadRADOSObject", "chaos-monkey-123", names[0]).Return([]byte("intact"), nil).Once()
+	s.cluster.On("ReadRADOSObject", "chaos-monkey-123", names[1]).Return([]byte(nil), fmt.Errorf("object: %w", drivers.ErrNotFound)).Once()
+	s.cluster.On("ReadRADOSObject", "chaos-monkey-123", names[2]).Return([]byte("garbage"), nil).Once()
+	s.cluster.On("ReadRADOSObject", "chaos-monkey-123", names[3]).Return([]byte(nil), errors.New("timed out")).Once()
+
+	s.cluster.On("MapObject", "chaos-monkey-123", names[1]).Return(ceph.ObjectMapping{PGID: "3.1b", Acting: []int{1, 0, 2}}, nil).Once()
+	s.cluster.On("MapObject", "chaos-monkey-123", names[2]).Return(ceph.ObjectMapping{PGID: "3.7", Acting: []int{1, 0, 2}}, nil).Once()
+	s.cluster.On("MapObject", "chaos-monkey-123", names[3]).Return(ceph.ObjectMapping{}, errors.New("test error")).Once()
+
+	r := m.audit(s.ctx)
+	s.Require().Equal(uint64(4), r.Total)
+	s.Require().Equal(uint64(1), r.Intact)
+	s.Require().Equal(auditLoss{Missing: 1, Corrupt: 1, Unreadable: 1}, r.auditLoss)
+	s.Require().Equal(map[string]*auditLoss{
+		"3.1b":    {Missing: 1},
+		"3.7":     {Corrupt: 1},
+		"unknown": {Unreadable: 1},
+	}, r.ByPG)
+	s.Require().Equal(map[string]*auditLoss{
+		"[1,0,2]": {Missing: 1, Corrupt: 1},
+		"unknown": {Unreadable: 1},
+	}, r.ByOSDSet)
+}
@@ -0,0 +1,115 @@
+package monkey
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// incident is the fuss hidden from the trainee in the blind mode
+type incident struct {
+	id     string
+	fussID string
+	entry  JournalEntry
+
+	diagnosed bool
+	correct   bool
+}
+
+// WithBlindMode hides the fusses behind the incident IDs until the trainee
+// diagnoses them with `diagnose` console command
+func WithBlindMode() Option {
+	return func(m *monkey) {
+		m.blind = true
+	}
+}
+
+// newIncident registers the fuss just triggered. The ID is derived from the
+// game pool name and the incident number so it tells nothing about the fuss.
+func (m *monkey) newIncident(fussID string, entry JournalEntry) {
+	sum := sha256.Sum256([]byte(m.bgIOPoolName + "/" + strconv.Itoa(len(m.incidents))))
+
+	i := &incident{
+		id:     hex.EncodeToString(sum[:])[:6],
+		fussID: fussID,
+		entry:  entry,
+	}
+	m.incidents = append(m.incidents, i)
+	m.score.incidents++
+
+	m.printer.Printf("Incident %s: something has happened in the cluster\n", i.id)
+}
+
+// diagnose scores the trainee guess and reveals the incident. Only the first
+// diagnosis of the incident counts.
+func (m *monkey) diagnose(args []string) {
+	if len(args) != 2 {
+		m.printer.Println("Usage: diagnose <incident> <fuss-id>")
+		return
+	}
+
+	var i *incident
+	for _, v := range m.incidents {
+		if v.id == args[0] {
+			i = v
+			break
+		}
+	}
+
+	if i == nil {
+		m.printer.Printf("Unknown incident `%s`, see `incidents`\n", args[0])
+		return
+	}
+
+	if i.diagnosed {
+		m.printer.Printf("Incident %s is already diagnosed: %s\n", i.id, i.entry.Entry)
+		return
+	}
+
+	if _, ok := fussByID(args[1]); !ok {
+		m.printer.Printf("Unknown fuss `%s`, see `fusses`\n", args[1])
+		return
+	}
+
+	i.diagnosed = true
+	i.correct = args[1] == i.fussID
+
+	result := "wrong"
+	if i.correct {
+		m.score.correct++
+		result = "correct"
+	}
+
+	m.printer.Printf("Diagnosis is %s! Incident %s at %s: %s\n", result, i.id, i.entry.Timestamp.Format(time.RFC3339), i.entry.Entry)
+
+	m.journal = append(m.journal, JournalEntry{
+		Timestamp: time.Now(),
+		Entry:     fmt.Sprintf("incident %s diagnosed as %s (%s)", i.id, args[1], result),
+	})
+}
+
+func (m *monkey) printIncidents() {
+	if len(m.incidents) == 0 {
+		m.printer.Println("No incidents so far")
+		return
+	}
+
+	for _, i := range m.incidents {
+		status := "not diagnosed"
+		if i.diagnosed {
+			status = i.entry.Entry
+		}
+		m.printer.Printf("%s %s %s\n", i.id, i.entry.Timestamp.Format(time.RFC3339), status)
+	}
+}
+
+// printFusses lists the fusses the trainee could diagnose the incidents as
+func (m *monkey) printFusses() {
+	for _, f := range fusses {
+		if m.policy.allows(f) {
+			m.printer.Printf("%-30s %s\n", f.id, f.name)
+		}
+	}
+}
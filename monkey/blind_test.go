@@ -0,0 +1,56 @@
+package monkey
+
+import (
+	"time"
+)
+
+func (s *cephTestSuite) TestDiagnose() {
+	m := s.newTestMonkey()
+	m.printer = NewPrinter()
+	m.bgIOPoolName = "chaos-monkey-1"
+	m.blind = true
+
+	m.newIncident("set-flag", JournalEntry{Timestamp: time.Now(), Entry: "set random flag"})
+	m.newIncident("stop-osd", JournalEntry{Timestamp: time.Now(), Entry: "stop random OSD daemon"})
+	s.Require().Len(m.incidents, 2)
+	s.Require().Len(m.incidents[0].id, 6)
+	s.Require().NotEqual(m.incidents[0].id, m.incidents[1].id)
+
+	first, second := m.incidents[0], m.incidents[1]
+
+	// unknown incidents and fusses are not counted
+	m.diagnose([]string{"000000", "set-flag"})
+	m.diagnose([]string{first.id, "format-disks"})
+	m.diagnose([]string{first.id})
+	s.Require().False(first.diagnosed)
+
+	m.diagnose([]string{first.id, "set-flag"})
+	s.Require().True(first.correct)
+
+	// only the first diagnosis counts
+	m.diagnose([]string{first.id, "unset-flag"})
+	s.Require().True(first.correct)
+
+	m.diagnose([]string{second.id, "destroy-osd"})
+	s.Require().True(second.diagnosed)
+	s.Require().False(second.correct)
+
+	s.Require().Equal(score{incidents: 2, correct: 1}, m.score)
+	s.Require().Equal(50, m.score.points())
+	s.Require().Equal("incident "+second.id+" diagnosed as destroy-osd (wrong)", m.journal[len(m.journal)-1].Entry)
+}
+
+func (s *cephTestSuite) TestBlindModeFuss() {
+	m := s.newTestMonkey()
+	m.printer = NewPrinter()
+	m.blind = true
+	m.policy = SafetyPolicy{AllowedFusses: []string{"reweight-by-utilization"}}
+
+	s.rnd.On("Intn", 1).Return(0).Once()
+	s.cluster.On("ReweightByUtilization").Return(nil).Once()
+
+	s.Require().NoError(m.doSomeFuss(s.ctx))
+	s.Require().Len(m.incidents, 1)
+	s.Require().Equal("reweight-by-utilization", m.incidents[0].fussID)
+	s.Require().Equal("run reweight-by-utilization", m.incidents[0].entry.Entry)
+}
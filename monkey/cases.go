@@ -3,6 +3,7 @@ package monkey
 import (
 	"context"
 	"errors"
+	"slices"
 	"strconv"
 
 	"github.com/teran/go-collection/random"
@@ -11,7 +12,8 @@ import (
 	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
 )
 
-var cephFlags = []ceph.Flag{
+// osdFlags are set for the group of OSDs by the group flag fusses
+var osdFlags = []ceph.Flag{
 	ceph.FlagNoBackfill,
 	ceph.FlagNoDeepScrub,
 	ceph.FlagNoIn,
@@ -22,8 +24,38 @@ var cephFlags = []ceph.Flag{
 	ceph.FlagNoUp,
 }
 
-func setRandomFlag(ctx context.Context, c drivers.Cluster, rnd random.Random) error {
-	return c.SetFlag(ctx, cephFlags[rnd.Intn(len(cephFlags))])
+// cephFlags are set and unset by the random flag fusses, the time-boxed ones
+// are included as well since they're reverted once the random window is over
+var cephFlags = append(slices.Clone(osdFlags), timeBoxedFlags...)
+
+// timeBoxedFlags freeze the cluster IO so they're never left set for unknown
+// amount of time
+var timeBoxedFlags = []ceph.Flag{
+	ceph.FlagNoDown,
+	ceph.FlagPause,
+}
+
+func setRandomFlag(ctx context.Context, c drivers.Cluster, rnd random.Random) ([]rollback, error) {
+	return setFlag(ctx, c, rnd, cephFlags[rnd.Intn(len(cephFlags))])
+}
+
+// setFlag sets the flag and returns the rollback unsetting it after the
+// random freeze window if the flag is time-boxed
+func setFlag(ctx context.Context, c drivers.Cluster, rnd random.Random, flag ceph.Flag) ([]rollback, error) {
+	if err := c.SetFlag(ctx, flag); err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(timeBoxedFlags, flag) {
+		return nil, nil
+	}
+
+	return []rollback{{
+		Action: rollbackActionUnsetFlag,
+		Args:   []string{string(flag)},
+		Delay:  randomFreezeWindow(rnd),
+		Stall:  flag == ceph.FlagPause,
+	}}, nil
 }
 
 func unsetRandomFlag(ctx context.Context, c drivers.Cluster, rnd random.Random) error {
@@ -45,6 +77,19 @@ func destroyRandomOSD(ctx context.Context, c drivers.Cluster, rnd random.Random)
 	return c.DestroyOSD(ctx, id)
 }
 
+func stopRandomOSDDaemon(ctx context.Context, c drivers.Cluster, rnd random.Random) error {
+	ids, err := c.GetOSDIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		return errors.New("no OSDs are present in the cluster")
+	}
+
+	return c.StopOSDDaemon(ctx, ids[rnd.Intn(len(ids))])
+}
+
 func randomlyResizeRandomPool(ctx context.Context, c drivers.Cluster, rnd random.Random) error {
 	pools, err := c.GetPools(ctx)
 	if err != nil {
@@ -139,7 +184,7 @@ func setRandomFlagForRandomGroup(ctx context.Context, c drivers.Cluster, rnd ran
 		targets = append(targets, h.Hostname)
 	}
 
-	return c.SetGroupFlag(ctx, cephFlags[rnd.Intn(len(cephFlags))], targets[:int(len(targets)/3)]...)
+	return c.SetGroupFlag(ctx, osdFlags[rnd.Intn(len(osdFlags))], targets[:int(len(targets)/3)]...)
 }
 
 func unsetRandomFlagFromRandomGroup(ctx context.Context, c drivers.Cluster, rnd random.Random) error {
@@ -163,7 +208,7 @@ func unsetRandomFlagFromRandomGroup(ctx context.Context, c drivers.Cluster, rnd
 		targets = append(targets, h.Hostname)
 	}
 
-	return c.UnsetGroupFlag(ctx, cephFlags[rnd.Intn(len(cephFlags))], targets[:int(len(targets)/3)]...)
+	return c.UnsetGroupFlag(ctx, osdFlags[rnd.Intn(len(osdFlags))], targets[:int(len(targets)/3)]...)
 }
 
 func deepScrubRandomPG(ctx context.Context, c drivers.Cluster, rnd random.Random) error {
@@ -0,0 +1,129 @@
+package monkey
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/teran/go-collection/random"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+// strippedCaps keeps the client able to authenticate so its IO is failing
+// with permission errors instead of connection ones
+var strippedCaps = map[string]string{
+	"mon": "allow r",
+}
+
+// blocklistOwnClient blocklists the hosts the background IO client is
+// connected from as a whole: every operation is performed by the new process
+// with the new nonce so blocklisting the instances connected at the moment
+// would affect the operations in flight only. The host any cluster daemon is
+// connected from is never blocklisted. The OSD operations of the monkey itself
+// fail on the host as well till the rollback.
+func blocklistOwnClient(ctx context.Context, c drivers.Cluster, rnd random.Random) ([]rollback, error) {
+	sessions, err := c.ListClientSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	daemonHosts := map[string]bool{}
+	addrs := []string{}
+	for _, s := range sessions {
+		addr, err := s.HostAddr()
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case !strings.HasPrefix(s.Entity, "client."):
+			daemonHosts[addr] = true
+		case strings.HasPrefix(s.Entity, ioClientPrefix) && !slices.Contains(addrs, addr):
+			addrs = append(addrs, addr)
+		}
+	}
+
+	if len(addrs) == 0 {
+		return nil, errors.New("chaos monkey client has no sessions open")
+	}
+
+	addrs = slices.DeleteFunc(addrs, func(addr string) bool { return daemonHosts[addr] })
+	if len(addrs) == 0 {
+		return nil, errors.New("chaos monkey client is connected from the hosts of the cluster daemons only")
+	}
+
+	expire := time.Duration(rnd.Intn(10)+1) * time.Minute
+
+	rollbacks := []rollback{}
+	for _, addr := range addrs {
+		if err := c.BlocklistAdd(ctx, addr, expire); err != nil {
+			return rollbacks, err
+		}
+
+		rollbacks = append(rollbacks, rollback{
+			Action: rollbackActionBlocklistRemove,
+			Args:   []string{addr},
+		})
+	}
+
+	return rollbacks, nil
+}
+
+func stripMonkeyClientCaps(ctx context.Context, c drivers.Cluster, rnd random.Random) ([]rollback, error) {
+	entities, err := c.ListAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := []ceph.AuthEntity{}
+	for _, e := range entities {
+		if strings.HasPrefix(e.Entity, ioClientPrefix) {
+			clients = append(clients, e)
+		}
+	}
+
+	return stripRandomClientCapsFrom(ctx, c, rnd, clients)
+}
+
+func stripRandomClientCaps(ctx context.Context, c drivers.Cluster, rnd random.Random) ([]rollback, error) {
+	entities, err := c.ListAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := []ceph.AuthEntity{}
+	for _, e := range entities {
+		if strings.HasPrefix(e.Entity, "client.") && e.Entity != "client.admin" {
+			clients = append(clients, e)
+		}
+	}
+
+	return stripRandomClientCapsFrom(ctx, c, rnd, clients)
+}
+
+func stripRandomClientCapsFrom(ctx context.Context, c drivers.Cluster, rnd random.Random, clients []ceph.AuthEntity) ([]rollback, error) {
+	if len(clients) == 0 {
+		return nil, errors.New("no suitable client keys are present in the cluster")
+	}
+
+	client := clients[rnd.Intn(len(clients))]
+
+	if err := c.SetAuthCaps(ctx, client.Entity, strippedCaps); err != nil {
+		return nil, err
+	}
+
+	args := []string{client.Entity}
+	for _, svc := range slices.Sorted(maps.Keys(client.Caps)) {
+		args = append(args, svc, client.Caps[svc])
+	}
+
+	return []rollback{{
+		Action: rollbackActionSetAuthCaps,
+		Args:   args,
+	}}, nil
+}
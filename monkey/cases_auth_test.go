@@ -0,0 +1,151 @@
+package monkey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+func (s *cephTestSuite) TestBlocklistOwnClient() {
+	s.cluster.On("ListClientSessions").Return([]ceph.ClientSession{
+		{Entity: "client.admin", Addr: "10.0.0.1:0/1853207391"},
+		{Entity: "client.chaos-monkey-123", Addr: "10.0.0.1:0/3710147553"},
+		{Entity: "client.chaos-monkey-123", Addr: "10.0.0.1:0/2914527105"},
+		{Entity: "client.chaos-monkey-123", Addr: "[fd00::2]:0/2914527105"},
+	}, nil).Once()
+	s.rnd.On("Intn", 10).Return(4).Once()
+	s.cluster.On("BlocklistAdd", "10.0.0.1:0/0", 5*time.Minute).Return(nil).Once()
+	s.cluster.On("BlocklistAdd", "[fd00::2]:0/0", 5*time.Minute).Return(nil).Once()
+
+	rollbacks, err := blocklistOwnClient(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+	s.Require().Equal([]rollback{
+		{Action: rollbackActionBlocklistRemove, Args: []string{"10.0.0.1:0/0"}},
+		{Action: rollbackActionBlocklistRemove, Args: []string{"[fd00::2]:0/0"}},
+	}, rollbacks)
+}
+
+// blocklistingCluster performs every RADOS operation with the new client
+// instance the same way the shell driver does and fails it if the instance
+// is blocklisted the same way the OSDs do
+type blocklistingCluster struct {
+	drivers.Cluster
+
+	ip        string
+	nonce     int
+	blocklist []string
+}
+
+func (c *blocklistingCluster) addr() string {
+	return fmt.Sprintf("%s:0/%d", c.ip, c.nonce)
+}
+
+func (c *blocklistingCluster) ListClientSessions(context.Context) ([]ceph.ClientSession, error) {
+	return []ceph.ClientSession{{Entity: "client.chaos-monkey-123", Addr: c.addr()}}, nil
+}
+
+func (c *blocklistingCluster) BlocklistAdd(_ context.Context, addr string, _ time.Duration) error {
+	c.blocklist = append(c.blocklist, addr)
+	return nil
+}
+
+func (c *blocklistingCluster) CreateRADOSObject(context.Context, string, string, []byte) error {
+	c.nonce++
+
+	host, err := ceph.ClientSession{Addr: c.addr()}.HostAddr()
+	if err != nil {
+		return err
+	}
+
+	if slices.Contains(c.blocklist, c.addr()) || slices.Contains(c.blocklist, host) {
+		return errors.New("(108) Cannot send after transport endpoint shutdown")
+	}
+	return nil
+}
+
+func (s *cephTestSuite) TestBlocklistOwnClientFailsLaterIO() {
+	c := &blocklistingCluster{Cluster: s.cluster, ip: "10.0.0.2"}
+
+	m := s.newTestMonkey()
+	m.ioCluster = c
+	m.profile.MaxFootprint = 8
+
+	s.rnd.On("Read").Return([]byte("data"), 4, nil).Twice()
+	s.Require().True(m.doBackgroundIOWrite(s.ctx))
+
+	s.rnd.On("Intn", 10).Return(0).Once()
+	_, err := blocklistOwnClient(s.ctx, c, s.rnd)
+	s.Require().NoError(err)
+
+	s.Require().False(m.doBackgroundIOWrite(s.ctx))
+	s.Require().Equal(uint64(1), m.stats.Dump().WritesErrorsTotal)
+}
+
+func (s *cephTestSuite) TestBlocklistOwnClientDaemonHost() {
+	s.cluster.On("ListClientSessions").Return([]ceph.ClientSession{
+		{Entity: "mgr.a", Addr: "10.0.0.1:0/1853207391"},
+		{Entity: "client.chaos-monkey-123", Addr: "10.0.0.1:0/3710147553"},
+	}, nil).Once()
+
+	_, err := blocklistOwnClient(s.ctx, s.cluster, s.rnd)
+	s.Require().EqualError(err, "chaos monkey client is connected from the hosts of the cluster daemons only")
+}
+
+func (s *cephTestSuite) TestBlocklistOwnClientNoSessions() {
+	s.cluster.On("ListClientSessions").Return([]ceph.ClientSession{
+		{Entity: "client.admin", Addr: "10.0.0.1:0/1853207391"},
+	}, nil).Once()
+
+	_, err := blocklistOwnClient(s.ctx, s.cluster, s.rnd)
+	s.Require().EqualError(err, "chaos monkey client has no sessions open")
+}
+
+func (s *cephTestSuite) TestStripMonkeyClientCaps() {
+	s.cluster.On("ListAuth").Return([]ceph.AuthEntity{
+		{Entity: "client.admin", Caps: map[string]string{"mon": "allow *"}},
+		{Entity: "client.chaos-monkey-123", Caps: map[string]string{"mon": "allow r", "osd": "allow rw pool=chaos-monkey-123"}},
+		{Entity: "client.user", Caps: map[string]string{"mon": "allow r"}},
+	}, nil).Once()
+	s.rnd.On("Intn", 1).Return(0).Once()
+	s.cluster.On("SetAuthCaps", "client.chaos-monkey-123", strippedCaps).Return(nil).Once()
+
+	rollbacks, err := stripMonkeyClientCaps(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+	s.Require().Equal([]rollback{
+		{
+			Action: rollbackActionSetAuthCaps,
+			Args:   []string{"client.chaos-monkey-123", "mon", "allow r", "osd", "allow rw pool=chaos-monkey-123"},
+		},
+	}, rollbacks)
+}
+
+func (s *cephTestSuite) TestStripRandomClientCaps() {
+	s.cluster.On("ListAuth").Return([]ceph.AuthEntity{
+		{Entity: "osd.0", Caps: map[string]string{"osd": "allow *"}},
+		{Entity: "client.admin", Caps: map[string]string{"mon": "allow *"}},
+		{Entity: "client.user1", Caps: map[string]string{"mon": "allow r", "osd": "allow rw"}},
+		{Entity: "client.user2", Caps: map[string]string{"mon": "allow r"}},
+	}, nil).Once()
+	s.rnd.On("Intn", 2).Return(0).Once()
+	s.cluster.On("SetAuthCaps", "client.user1", strippedCaps).Return(nil).Once()
+
+	rollbacks, err := stripRandomClientCaps(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+	s.Require().Equal([]rollback{
+		{Action: rollbackActionSetAuthCaps, Args: []string{"client.user1", "mon", "allow r", "osd", "allow rw"}},
+	}, rollbacks)
+}
+
+func (s *cephTestSuite) TestStripRandomClientCapsNoClients() {
+	s.cluster.On("ListAuth").Return([]ceph.AuthEntity{
+		{Entity: "client.admin", Caps: map[string]string{"mon": "allow *"}},
+	}, nil).Once()
+
+	_, err := stripRandomClientCaps(s.ctx, s.cluster, s.rnd)
+	s.Require().Error(err)
+}
@@ -0,0 +1,67 @@
+package monkey
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/teran/go-collection/random"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+var balancerModes = []ceph.BalancerMode{
+	ceph.BalancerModeNone,
+	ceph.BalancerModeCrushCompat,
+	ceph.BalancerModeUpmap,
+	ceph.BalancerModeRead,
+	ceph.BalancerModeUpmapRead,
+}
+
+// addBogusUpmapForRandomPG remaps one of the random PG's OSDs to the OSD not
+// holding the PG so the PG becomes misplaced
+func addBogusUpmapForRandomPG(ctx context.Context, c drivers.Cluster, rnd random.Random) error {
+	pgs, err := c.ListPGs(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(pgs) == 0 {
+		return errors.New("no PGs are present in the cluster")
+	}
+
+	pg := pgs[rnd.Intn(len(pgs))]
+	if len(pg.Up) == 0 {
+		return errors.New("PG " + pg.PGID + " has no up OSDs")
+	}
+
+	ids, err := c.GetOSDIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	candidates := []uint64{}
+	for _, id := range ids {
+		if !slices.Contains(pg.Up, id) {
+			candidates = append(candidates, id)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return errors.New("no OSDs are available to remap PG " + pg.PGID + " to")
+	}
+
+	return c.SetPGUpmapItems(ctx, pg.PGID, ceph.UpmapMapping{
+		From: pg.Up[rnd.Intn(len(pg.Up))],
+		To:   candidates[rnd.Intn(len(candidates))],
+	})
+}
+
+func setRandomBalancerMode(ctx context.Context, c drivers.Cluster, rnd random.Random) error {
+	return c.SetBalancerMode(ctx, balancerModes[rnd.Intn(len(balancerModes))])
+}
+
+func disableBalancer(ctx context.Context, c drivers.Cluster, _ random.Random) error {
+	return c.DisableBalancer(ctx)
+}
@@ -0,0 +1,46 @@
+package monkey
+
+import (
+	"github.com/teran/ceph-chaos-monkey/ceph"
+)
+
+func (s *cephTestSuite) TestAddBogusUpmapForRandomPG() {
+	s.cluster.On("ListPGs").Return([]ceph.PGStat{
+		{PGID: "1.1", Up: []uint64{0, 1, 2}},
+		{PGID: "1.2", Up: []uint64{1, 2, 3}},
+	}, nil).Once()
+	s.rnd.On("Intn", 2).Return(1).Once()
+	s.cluster.On("GetOSDIDs").Return([]uint64{0, 1, 2, 3, 4}, nil).Once()
+	s.rnd.On("Intn", 3).Return(2).Once()
+	s.rnd.On("Intn", 2).Return(0).Once()
+	s.cluster.On("SetPGUpmapItems", "1.2", []ceph.UpmapMapping{{From: 3, To: 0}}).Return(nil).Once()
+
+	err := addBogusUpmapForRandomPG(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestAddBogusUpmapForRandomPGNoCandidates() {
+	s.cluster.On("ListPGs").Return([]ceph.PGStat{
+		{PGID: "1.1", Up: []uint64{0, 1, 2}},
+	}, nil).Once()
+	s.rnd.On("Intn", 1).Return(0).Once()
+	s.cluster.On("GetOSDIDs").Return([]uint64{0, 1, 2}, nil).Once()
+
+	err := addBogusUpmapForRandomPG(s.ctx, s.cluster, s.rnd)
+	s.Require().Error(err)
+}
+
+func (s *cephTestSuite) TestSetRandomBalancerMode() {
+	s.rnd.On("Intn", len(balancerModes)).Return(1).Once()
+	s.cluster.On("SetBalancerMode", ceph.BalancerModeCrushCompat).Return(nil).Once()
+
+	err := setRandomBalancerMode(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestDisableBalancer() {
+	s.cluster.On("DisableBalancer").Return(nil).Once()
+
+	err := disableBalancer(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+}
@@ -0,0 +1,84 @@
+package monkey
+
+import (
+	"context"
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/teran/go-collection/random"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+const maxMDSLimit = 4
+
+func hasFilesystems(ctx context.Context, c drivers.Cluster) bool {
+	fss, err := c.GetFilesystems(ctx)
+	if err != nil {
+		log.Debugf("error getting filesystems: %s", err)
+		return false
+	}
+
+	return len(fss) > 0
+}
+
+func failRandomActiveMDS(ctx context.Context, c drivers.Cluster, rnd random.Random) error {
+	daemons, err := c.GetMDSDaemons(ctx)
+	if err != nil {
+		return err
+	}
+
+	active := []ceph.MDS{}
+	for _, d := range daemons {
+		if d.Rank >= 0 {
+			active = append(active, d)
+		}
+	}
+
+	if len(active) == 0 {
+		return errors.New("no active MDS daemons are present in the cluster")
+	}
+
+	return c.FailMDS(ctx, active[rnd.Intn(len(active))].Name)
+}
+
+func setRandomMaxMDSForRandomFilesystem(ctx context.Context, c drivers.Cluster, rnd random.Random) error {
+	fs, err := randomFilesystem(ctx, c, rnd)
+	if err != nil {
+		return err
+	}
+
+	return c.SetMaxMDS(ctx, fs.Name, uint64(rnd.Intn(maxMDSLimit)+1))
+}
+
+func makeRandomFilesystemNotJoinable(ctx context.Context, c drivers.Cluster, rnd random.Random) error {
+	fs, err := randomFilesystem(ctx, c, rnd)
+	if err != nil {
+		return err
+	}
+
+	return c.SetFilesystemJoinable(ctx, fs.Name, false)
+}
+
+func takeRandomFilesystemDown(ctx context.Context, c drivers.Cluster, rnd random.Random) error {
+	fs, err := randomFilesystem(ctx, c, rnd)
+	if err != nil {
+		return err
+	}
+
+	return c.SetFilesystemDown(ctx, fs.Name, true)
+}
+
+func randomFilesystem(ctx context.Context, c drivers.Cluster, rnd random.Random) (ceph.Filesystem, error) {
+	fss, err := c.GetFilesystems(ctx)
+	if err != nil {
+		return ceph.Filesystem{}, err
+	}
+
+	if len(fss) == 0 {
+		return ceph.Filesystem{}, errors.New("no filesystems are present in the cluster")
+	}
+
+	return fss[rnd.Intn(len(fss))], nil
+}
@@ -0,0 +1,68 @@
+package monkey
+
+import (
+	"errors"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+)
+
+func (s *cephTestSuite) TestHasFilesystems() {
+	s.cluster.On("GetFilesystems").Return([]ceph.Filesystem{{Name: "cephfs"}}, nil).Once()
+	s.Require().True(hasFilesystems(s.ctx, s.cluster))
+
+	s.cluster.On("GetFilesystems").Return([]ceph.Filesystem{}, nil).Once()
+	s.Require().False(hasFilesystems(s.ctx, s.cluster))
+
+	s.cluster.On("GetFilesystems").Return([]ceph.Filesystem(nil), errors.New("test error")).Once()
+	s.Require().False(hasFilesystems(s.ctx, s.cluster))
+}
+
+func (s *cephTestSuite) TestFailRandomActiveMDS() {
+	s.cluster.On("GetMDSDaemons").Return([]ceph.MDS{
+		{Name: "mds1", Rank: 0, FSName: "cephfs"},
+		{Name: "mds2", Rank: -1},
+		{Name: "mds3", Rank: 1, FSName: "cephfs"},
+	}, nil).Once()
+	s.rnd.On("Intn", 2).Return(1).Once()
+	s.cluster.On("FailMDS", "mds3").Return(nil).Once()
+
+	err := failRandomActiveMDS(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestFailRandomActiveMDSNoActive() {
+	s.cluster.On("GetMDSDaemons").Return([]ceph.MDS{
+		{Name: "mds2", Rank: -1},
+	}, nil).Once()
+
+	err := failRandomActiveMDS(s.ctx, s.cluster, s.rnd)
+	s.Require().Error(err)
+}
+
+func (s *cephTestSuite) TestSetRandomMaxMDSForRandomFilesystem() {
+	s.cluster.On("GetFilesystems").Return([]ceph.Filesystem{{Name: "fs1"}, {Name: "fs2"}}, nil).Once()
+	s.rnd.On("Intn", 2).Return(1).Once()
+	s.rnd.On("Intn", maxMDSLimit).Return(2).Once()
+	s.cluster.On("SetMaxMDS", "fs2", uint64(3)).Return(nil).Once()
+
+	err := setRandomMaxMDSForRandomFilesystem(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestMakeRandomFilesystemNotJoinable() {
+	s.cluster.On("GetFilesystems").Return([]ceph.Filesystem{{Name: "fs1"}}, nil).Once()
+	s.rnd.On("Intn", 1).Return(0).Once()
+	s.cluster.On("SetFilesystemJoinable", "fs1", false).Return(nil).Once()
+
+	err := makeRandomFilesystemNotJoinable(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestTakeRandomFilesystemDown() {
+	s.cluster.On("GetFilesystems").Return([]ceph.Filesystem{{Name: "fs1"}}, nil).Once()
+	s.rnd.On("Intn", 1).Return(0).Once()
+	s.cluster.On("SetFilesystemDown", "fs1", true).Return(nil).Once()
+
+	err := takeRandomFilesystemDown(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+}
@@ -0,0 +1,136 @@
+package monkey
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/teran/go-collection/random"
+
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+type configFuss struct {
+	daemonType string
+	name       string
+	value      func(random.Random) string
+}
+
+// configFusses is a catalogue of settings harmful for the cluster but safe to
+// revert: none of them makes the data inaccessible by itself.
+var configFusses = []configFuss{
+	{
+		daemonType: "osd",
+		name:       "osd_max_backfills",
+		value:      func(random.Random) string { return "1" },
+	},
+	{
+		daemonType: "osd",
+		name:       "osd_recovery_max_active",
+		value:      func(random.Random) string { return "1" },
+	},
+	{
+		daemonType: "osd",
+		name:       "osd_recovery_sleep",
+		value:      func(rnd random.Random) string { return strconv.Itoa(rnd.Intn(55) + 5) },
+	},
+	{
+		daemonType: "osd",
+		name:       "osd_client_op_priority",
+		value:      func(random.Random) string { return "1" },
+	},
+	{
+		daemonType: "osd",
+		name:       "bluestore_cache_size",
+		value:      func(rnd random.Random) string { return strconv.Itoa((rnd.Intn(16) + 1) * 1024 * 1024) },
+	},
+	{
+		daemonType: "mon",
+		name:       "mon_osd_down_out_interval",
+		value:      func(rnd random.Random) string { return strconv.Itoa((rnd.Intn(30) + 1) * 86400) },
+	},
+}
+
+func setRandomConfigOption(ctx context.Context, c drivers.Cluster, rnd random.Random) ([]rollback, error) {
+	opt := configFusses[rnd.Intn(len(configFusses))]
+
+	who, err := randomConfigTarget(ctx, c, rnd, opt.daemonType)
+	if err != nil {
+		return nil, err
+	}
+
+	return setConfig(ctx, c, who, opt.name, opt.value(rnd))
+}
+
+// setConfig sets the option and returns the rollback restoring the previous
+// override or removing the new one
+func setConfig(ctx context.Context, c drivers.Cluster, who, name, value string) ([]rollback, error) {
+	current, err := c.DumpConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rb := rollback{
+		Action: rollbackActionRemoveConfig,
+		Args:   []string{who, name},
+	}
+	for _, v := range current {
+		if v.Who() == who && v.Name == name {
+			rb = rollback{
+				Action: rollbackActionSetConfig,
+				Args:   []string{who, name, v.Value},
+			}
+			break
+		}
+	}
+
+	if err := c.SetConfig(ctx, who, name, value); err != nil {
+		return nil, err
+	}
+
+	return []rollback{rb}, nil
+}
+
+// randomConfigTarget picks the section to apply the option to: global,
+// all the daemons of the type on a random host or a random daemon.
+func randomConfigTarget(ctx context.Context, c drivers.Cluster, rnd random.Random, daemonType string) (string, error) {
+	switch rnd.Intn(3) {
+	case 0:
+		return "global", nil
+	case 1:
+		hosts, err := c.ListHosts(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		if len(hosts) == 0 {
+			return "", errors.New("no hosts are present in the cluster")
+		}
+
+		return daemonType + "/host:" + hosts[rnd.Intn(len(hosts))].Hostname, nil
+	}
+
+	if daemonType == "mon" {
+		mons, err := c.GetMons(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		if len(mons) == 0 {
+			return "", errors.New("no monitors are present in the cluster")
+		}
+
+		return "mon." + mons[rnd.Intn(len(mons))].Name, nil
+	}
+
+	ids, err := c.GetOSDIDs(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if len(ids) == 0 {
+		return "", errors.New("no OSDs are present in the cluster")
+	}
+
+	return "osd." + strconv.FormatUint(ids[rnd.Intn(len(ids))], 10), nil
+}
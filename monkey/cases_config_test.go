@@ -0,0 +1,61 @@
+package monkey
+
+import (
+	"github.com/teran/ceph-chaos-monkey/ceph"
+)
+
+func (s *cephTestSuite) TestSetRandomConfigOptionGlobal() {
+	s.rnd.On("Intn", len(configFusses)).Return(0).Once()
+	s.rnd.On("Intn", 3).Return(0).Once()
+	s.cluster.On("DumpConfig").Return([]ceph.ConfigOption{
+		{Section: "osd.1", Name: "osd_max_backfills", Value: "3"},
+	}, nil).Once()
+	s.cluster.On("SetConfig", "global", "osd_max_backfills", "1").Return(nil).Once()
+
+	rollbacks, err := setRandomConfigOption(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+	s.Require().Equal([]rollback{
+		{Action: rollbackActionRemoveConfig, Args: []string{"global", "osd_max_backfills"}},
+	}, rollbacks)
+}
+
+func (s *cephTestSuite) TestSetRandomConfigOptionHost() {
+	s.rnd.On("Intn", len(configFusses)).Return(2).Once()
+	s.rnd.On("Intn", 3).Return(1).Once()
+	s.cluster.On("ListHosts").Return([]ceph.Host{
+		{Hostname: "host1"},
+		{Hostname: "host2"},
+	}, nil).Once()
+	s.rnd.On("Intn", 2).Return(1).Once()
+	s.cluster.On("DumpConfig").Return([]ceph.ConfigOption{
+		{Section: "osd", Mask: "host:host2", Name: "osd_recovery_sleep", Value: "0.1"},
+	}, nil).Once()
+	s.rnd.On("Intn", 55).Return(10).Once()
+	s.cluster.On("SetConfig", "osd/host:host2", "osd_recovery_sleep", "15").Return(nil).Once()
+
+	rollbacks, err := setRandomConfigOption(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+	s.Require().Equal([]rollback{
+		{Action: rollbackActionSetConfig, Args: []string{"osd/host:host2", "osd_recovery_sleep", "0.1"}},
+	}, rollbacks)
+}
+
+func (s *cephTestSuite) TestSetRandomConfigOptionDaemon() {
+	s.rnd.On("Intn", len(configFusses)).Return(5).Once()
+	s.rnd.On("Intn", 3).Return(2).Once()
+	s.cluster.On("GetMons").Return([]ceph.Mon{
+		{Name: "a"},
+		{Name: "b"},
+		{Name: "c"},
+	}, nil).Once()
+	s.rnd.On("Intn", 3).Return(1).Once()
+	s.cluster.On("DumpConfig").Return([]ceph.ConfigOption{}, nil).Once()
+	s.rnd.On("Intn", 30).Return(1).Once()
+	s.cluster.On("SetConfig", "mon.b", "mon_osd_down_out_interval", "172800").Return(nil).Once()
+
+	rollbacks, err := setRandomConfigOption(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+	s.Require().Equal([]rollback{
+		{Action: rollbackActionRemoveConfig, Args: []string{"mon.b", "mon_osd_down_out_interval"}},
+	}, rollbacks)
+}
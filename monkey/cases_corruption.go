@@ -0,0 +1,69 @@
+package monkey
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/teran/go-collection/random"
+
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+// bluestoreInjectReadErr makes BlueStore honor the injected data errors, they
+// are silently ignored without it
+const bluestoreInjectReadErr = "bluestore_debug_inject_read_err"
+
+// injectDataErrorIntoRandomObject corrupts a single copy of the random object
+// written by the background IO so the PG becomes inconsistent after the
+// deep-scrub. Only the monkey pools are touched. The error injection is
+// enabled on the OSD till the end of the game.
+func injectDataErrorIntoRandomObject(ctx context.Context, c drivers.Cluster, rnd random.Random) ([]rollback, error) {
+	pools, err := c.GetPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, p := range pools {
+		if strings.HasPrefix(p.PoolName, artifactPrefix) {
+			names = append(names, p.PoolName)
+		}
+	}
+
+	if len(names) == 0 {
+		return nil, errors.New("no background IO pools are present in the cluster")
+	}
+
+	pool := names[rnd.Intn(len(names))]
+
+	objects, err := c.ListRADOSObjects(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(objects) == 0 {
+		return nil, errors.New("no objects are present in the background IO pool")
+	}
+
+	object := objects[rnd.Intn(len(objects))]
+
+	mapping, err := c.MapObject(ctx, pool, object)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(mapping.Acting) == 0 {
+		return nil, errors.New("object has no acting OSDs")
+	}
+
+	osd := uint64(mapping.Acting[rnd.Intn(len(mapping.Acting))])
+
+	rollbacks, err := setConfig(ctx, c, "osd."+strconv.FormatUint(osd, 10), bluestoreInjectReadErr, "true")
+	if err != nil {
+		return nil, err
+	}
+
+	return rollbacks, c.InjectDataError(ctx, osd, pool, object)
+}
@@ -0,0 +1,34 @@
+package monkey
+
+import (
+	"github.com/teran/ceph-chaos-monkey/ceph"
+)
+
+func (s *cephTestSuite) TestInjectDataErrorIntoRandomObject() {
+	s.cluster.On("GetPools").Return([]ceph.Pool{
+		{PoolName: "rbd"},
+		{PoolName: "chaos-monkey-123"},
+	}, nil).Once()
+	s.rnd.On("Intn", 1).Return(0).Once()
+	s.cluster.On("ListRADOSObjects", "chaos-monkey-123").Return([]string{"obj1", "obj2"}, nil).Once()
+	s.rnd.On("Intn", 2).Return(1).Once()
+	s.cluster.On("MapObject", "chaos-monkey-123", "obj2").Return(ceph.ObjectMapping{Acting: []int{4, 1, 7}}, nil).Once()
+	s.rnd.On("Intn", 3).Return(2).Once()
+	s.cluster.On("DumpConfig").Return([]ceph.ConfigOption{}, nil).Once()
+	s.cluster.On("SetConfig", "osd.7", bluestoreInjectReadErr, "true").Return(nil).Once()
+	s.cluster.On("InjectDataError", uint64(7), "chaos-monkey-123", "obj2").Return(nil).Once()
+
+	rollbacks, err := injectDataErrorIntoRandomObject(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+	s.Require().Equal([]rollback{{
+		Action: rollbackActionRemoveConfig,
+		Args:   []string{"osd.7", bluestoreInjectReadErr},
+	}}, rollbacks)
+}
+
+func (s *cephTestSuite) TestInjectDataErrorNoMonkeyPools() {
+	s.cluster.On("GetPools").Return([]ceph.Pool{{PoolName: "rbd"}}, nil).Once()
+
+	_, err := injectDataErrorIntoRandomObject(s.ctx, s.cluster, s.rnd)
+	s.Require().Error(err)
+}
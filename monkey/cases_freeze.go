@@ -0,0 +1,50 @@
+package monkey
+
+import (
+	"context"
+	"time"
+
+	"github.com/teran/go-collection/random"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+const (
+	minFreezeWindow = 30 * time.Second
+	maxFreezeWindow = 5 * time.Minute
+)
+
+// freezeFlagSets are the flags freezing the cluster IO set together
+var freezeFlagSets = [][]ceph.Flag{
+	{ceph.FlagPause},
+	{ceph.FlagNoUp, ceph.FlagNoDown},
+}
+
+// freezeClusterIO sets the flags freezing the cluster for the random window
+// and returns the rollbacks reverting them once the window is over
+func freezeClusterIO(ctx context.Context, c drivers.Cluster, rnd random.Random) ([]rollback, error) {
+	flags := freezeFlagSets[rnd.Intn(len(freezeFlagSets))]
+	window := randomFreezeWindow(rnd)
+
+	rollbacks := []rollback{}
+	for i, flag := range flags {
+		if err := c.SetFlag(ctx, flag); err != nil {
+			// flags set so far must still be reverted
+			return rollbacks, err
+		}
+
+		rollbacks = append(rollbacks, rollback{
+			Action: rollbackActionUnsetFlag,
+			Args:   []string{string(flag)},
+			Delay:  window,
+			Stall:  i == 0,
+		})
+	}
+
+	return rollbacks, nil
+}
+
+func randomFreezeWindow(rnd random.Random) time.Duration {
+	return minFreezeWindow + time.Duration(rnd.Int63n(int64(maxFreezeWindow-minFreezeWindow)))
+}
@@ -0,0 +1,47 @@
+package monkey
+
+import (
+	"errors"
+	"time"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+)
+
+func (s *cephTestSuite) TestFreezeClusterIOPause() {
+	s.rnd.On("Intn", len(freezeFlagSets)).Return(0).Once()
+	s.rnd.On("Int63n", int64(maxFreezeWindow-minFreezeWindow)).Return(int64(10 * time.Second)).Once()
+	s.cluster.On("SetFlag", ceph.FlagPause).Return(nil).Once()
+
+	rollbacks, err := freezeClusterIO(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+	s.Require().Equal([]rollback{
+		{Action: rollbackActionUnsetFlag, Args: []string{"pause"}, Delay: 40 * time.Second, Stall: true},
+	}, rollbacks)
+}
+
+func (s *cephTestSuite) TestFreezeClusterIONoUpNoDown() {
+	s.rnd.On("Intn", len(freezeFlagSets)).Return(1).Once()
+	s.rnd.On("Int63n", int64(maxFreezeWindow-minFreezeWindow)).Return(int64(0)).Once()
+	s.cluster.On("SetFlag", ceph.FlagNoUp).Return(nil).Once()
+	s.cluster.On("SetFlag", ceph.FlagNoDown).Return(nil).Once()
+
+	rollbacks, err := freezeClusterIO(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+	s.Require().Equal([]rollback{
+		{Action: rollbackActionUnsetFlag, Args: []string{"noup"}, Delay: minFreezeWindow, Stall: true},
+		{Action: rollbackActionUnsetFlag, Args: []string{"nodown"}, Delay: minFreezeWindow},
+	}, rollbacks)
+}
+
+func (s *cephTestSuite) TestFreezeClusterIOPartialFailure() {
+	s.rnd.On("Intn", len(freezeFlagSets)).Return(1).Once()
+	s.rnd.On("Int63n", int64(maxFreezeWindow-minFreezeWindow)).Return(int64(0)).Once()
+	s.cluster.On("SetFlag", ceph.FlagNoUp).Return(nil).Once()
+	s.cluster.On("SetFlag", ceph.FlagNoDown).Return(errors.New("test error")).Once()
+
+	rollbacks, err := freezeClusterIO(s.ctx, s.cluster, s.rnd)
+	s.Require().Error(err)
+	s.Require().Equal([]rollback{
+		{Action: rollbackActionUnsetFlag, Args: []string{"noup"}, Delay: minFreezeWindow, Stall: true},
+	}, rollbacks)
+}
@@ -0,0 +1,196 @@
+package monkey
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/teran/go-collection/random"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+const (
+	rbdLockID = artifactPrefix + "lock"
+
+	minRBDLockWindow = 1 * time.Minute
+	maxRBDLockWindow = 10 * time.Minute
+)
+
+type rbdImageRef struct {
+	pool  string
+	image string
+}
+
+func hasRBDImages(ctx context.Context, c drivers.Cluster) bool {
+	images, err := listRBDImages(ctx, c)
+	if err != nil {
+		log.Debugf("error listing RBD images: %s", err)
+		return false
+	}
+
+	return len(images) > 0
+}
+
+// lockRandomRBDImage takes the lock on random image for the random window and
+// returns the rollback releasing it once the window is over
+func lockRandomRBDImage(ctx context.Context, c drivers.Cluster, rnd random.Random) ([]rollback, error) {
+	image, err := randomRBDImage(ctx, c, rnd)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.LockRBDImage(ctx, image.pool, image.image, rbdLockID); err != nil {
+		return nil, err
+	}
+
+	return []rollback{{
+		Action: rollbackActionUnlockRBDImage,
+		Args:   []string{image.pool, image.image, rbdLockID},
+		Delay:  minRBDLockWindow + time.Duration(rnd.Int63n(int64(maxRBDLockWindow-minRBDLockWindow))),
+	}}, nil
+}
+
+func removeRandomRBDSnapshot(ctx context.Context, c drivers.Cluster, rnd random.Random) error {
+	image, err := randomRBDImage(ctx, c, rnd)
+	if err != nil {
+		return err
+	}
+
+	snaps, err := c.ListRBDSnapshots(ctx, image.pool, image.image)
+	if err != nil {
+		return err
+	}
+
+	unprotected := []ceph.RBDSnapshot{}
+	for _, snap := range snaps {
+		if !snap.IsProtected() {
+			unprotected = append(unprotected, snap)
+		}
+	}
+
+	if len(unprotected) == 0 {
+		return errors.New("no unprotected snapshots are present for the image")
+	}
+
+	return c.RemoveRBDSnapshot(ctx, image.pool, image.image, unprotected[rnd.Intn(len(unprotected))].Name)
+}
+
+func flattenRandomRBDClone(ctx context.Context, c drivers.Cluster, rnd random.Random) error {
+	images, err := listRBDImages(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	clones := []rbdImageRef{}
+	for _, image := range images {
+		info, err := c.GetRBDImage(ctx, image.pool, image.image)
+		if err != nil {
+			log.Debugf("error getting RBD image %s/%s: %s", image.pool, image.image, err)
+			continue
+		}
+
+		if info.Parent != nil {
+			clones = append(clones, image)
+		}
+	}
+
+	if len(clones) == 0 {
+		return errors.New("no cloned RBD images are present in the cluster")
+	}
+
+	clone := clones[rnd.Intn(len(clones))]
+
+	return c.FlattenRBDImage(ctx, clone.pool, clone.image)
+}
+
+// resizeRandomRBDImage sets the random size up to twice as large as the
+// current one so the image could be shrunk with the data loss
+func resizeRandomRBDImage(ctx context.Context, c drivers.Cluster, rnd random.Random) error {
+	image, err := randomRBDImage(ctx, c, rnd)
+	if err != nil {
+		return err
+	}
+
+	info, err := c.GetRBDImage(ctx, image.pool, image.image)
+	if err != nil {
+		return err
+	}
+
+	sizeMiB := max(info.Size/1024/1024, 1)
+
+	return c.ResizeRBDImage(ctx, image.pool, image.image, uint64(rnd.Int63n(int64(sizeMiB)*2))+1)
+}
+
+func blocklistRandomRBDWatcher(ctx context.Context, c drivers.Cluster, rnd random.Random) ([]rollback, error) {
+	images, err := listRBDImages(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := []string{}
+	for _, image := range images {
+		watchers, err := c.ListRBDWatchers(ctx, image.pool, image.image)
+		if err != nil {
+			log.Debugf("error listing watchers for RBD image %s/%s: %s", image.pool, image.image, err)
+			continue
+		}
+
+		for _, w := range watchers {
+			addrs = append(addrs, w.Address)
+		}
+	}
+
+	if len(addrs) == 0 {
+		return nil, errors.New("no RBD images are being watched")
+	}
+
+	addr := addrs[rnd.Intn(len(addrs))]
+	if err := c.BlocklistAdd(ctx, addr, time.Duration(rnd.Intn(10)+1)*time.Minute); err != nil {
+		return nil, err
+	}
+
+	return []rollback{{
+		Action: rollbackActionBlocklistRemove,
+		Args:   []string{addr},
+	}}, nil
+}
+
+func randomRBDImage(ctx context.Context, c drivers.Cluster, rnd random.Random) (rbdImageRef, error) {
+	images, err := listRBDImages(ctx, c)
+	if err != nil {
+		return rbdImageRef{}, err
+	}
+
+	if len(images) == 0 {
+		return rbdImageRef{}, errors.New("no RBD images are present in the cluster")
+	}
+
+	return images[rnd.Intn(len(images))], nil
+}
+
+// listRBDImages lists images in all the pools skipping the ones RBD can't be
+// used with
+func listRBDImages(ctx context.Context, c drivers.Cluster) ([]rbdImageRef, error) {
+	pools, err := c.GetPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	images := []rbdImageRef{}
+	for _, pool := range pools {
+		names, err := c.ListRBDImages(ctx, pool.PoolName)
+		if err != nil {
+			log.Tracef("error listing RBD images in pool %s: %s", pool.PoolName, err)
+			continue
+		}
+
+		for _, name := range names {
+			images = append(images, rbdImageRef{pool: pool.PoolName, image: name})
+		}
+	}
+
+	return images, nil
+}
@@ -0,0 +1,128 @@
+package monkey
+
+import (
+	"errors"
+	"time"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+)
+
+func (s *cephTestSuite) mockRBDImages() {
+	s.cluster.On("GetPools").Return([]ceph.Pool{
+		{PoolName: "rbd"},
+		{PoolName: ".mgr"},
+		{PoolName: "volumes"},
+	}, nil).Once()
+	s.cluster.On("ListRBDImages", "rbd").Return([]string{"image1"}, nil).Once()
+	s.cluster.On("ListRBDImages", ".mgr").Return([]string(nil), errors.New("test error")).Once()
+	s.cluster.On("ListRBDImages", "volumes").Return([]string{"image2"}, nil).Once()
+}
+
+func (s *cephTestSuite) TestHasRBDImages() {
+	s.mockRBDImages()
+	s.Require().True(hasRBDImages(s.ctx, s.cluster))
+
+	s.cluster.On("GetPools").Return([]ceph.Pool{}, nil).Once()
+	s.Require().False(hasRBDImages(s.ctx, s.cluster))
+}
+
+func (s *cephTestSuite) TestLockRandomRBDImage() {
+	s.mockRBDImages()
+	s.rnd.On("Intn", 2).Return(1).Once()
+	s.cluster.On("LockRBDImage", "volumes", "image2", rbdLockID).Return(nil).Once()
+	s.rnd.On("Int63n", int64(maxRBDLockWindow-minRBDLockWindow)).Return(int64(time.Minute)).Once()
+
+	rollbacks, err := lockRandomRBDImage(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+	s.Require().Equal([]rollback{{
+		Action: rollbackActionUnlockRBDImage,
+		Args:   []string{"volumes", "image2", rbdLockID},
+		Delay:  minRBDLockWindow + time.Minute,
+	}}, rollbacks)
+}
+
+func (s *cephTestSuite) TestRemoveRandomRBDSnapshot() {
+	s.mockRBDImages()
+	s.rnd.On("Intn", 2).Return(0).Once()
+	s.cluster.On("ListRBDSnapshots", "rbd", "image1").Return([]ceph.RBDSnapshot{
+		{Name: "snap1", Protected: "true"},
+		{Name: "snap2", Protected: "false"},
+	}, nil).Once()
+	s.rnd.On("Intn", 1).Return(0).Once()
+	s.cluster.On("RemoveRBDSnapshot", "rbd", "image1", "snap2").Return(nil).Once()
+
+	err := removeRandomRBDSnapshot(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestRemoveRandomRBDSnapshotAllProtected() {
+	s.mockRBDImages()
+	s.rnd.On("Intn", 2).Return(0).Once()
+	s.cluster.On("ListRBDSnapshots", "rbd", "image1").Return([]ceph.RBDSnapshot{
+		{Name: "snap1", Protected: "true"},
+	}, nil).Once()
+
+	err := removeRandomRBDSnapshot(s.ctx, s.cluster, s.rnd)
+	s.Require().Error(err)
+}
+
+func (s *cephTestSuite) TestFlattenRandomRBDClone() {
+	s.mockRBDImages()
+	s.cluster.On("GetRBDImage", "rbd", "image1").Return(ceph.RBDImage{Name: "image1"}, nil).Once()
+	s.cluster.On("GetRBDImage", "volumes", "image2").Return(ceph.RBDImage{
+		Name:   "image2",
+		Parent: &ceph.RBDParent{Pool: "rbd", Image: "image1", Snapshot: "base"},
+	}, nil).Once()
+	s.rnd.On("Intn", 1).Return(0).Once()
+	s.cluster.On("FlattenRBDImage", "volumes", "image2").Return(nil).Once()
+
+	err := flattenRandomRBDClone(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestFlattenRandomRBDCloneNoClones() {
+	s.mockRBDImages()
+	s.cluster.On("GetRBDImage", "rbd", "image1").Return(ceph.RBDImage{Name: "image1"}, nil).Once()
+	s.cluster.On("GetRBDImage", "volumes", "image2").Return(ceph.RBDImage{Name: "image2"}, nil).Once()
+
+	err := flattenRandomRBDClone(s.ctx, s.cluster, s.rnd)
+	s.Require().Error(err)
+}
+
+func (s *cephTestSuite) TestResizeRandomRBDImage() {
+	s.mockRBDImages()
+	s.rnd.On("Intn", 2).Return(0).Once()
+	s.cluster.On("GetRBDImage", "rbd", "image1").Return(ceph.RBDImage{Name: "image1", Size: 16 * 1024 * 1024}, nil).Once()
+	s.rnd.On("Int63n", int64(32)).Return(int64(7)).Once()
+	s.cluster.On("ResizeRBDImage", "rbd", "image1", uint64(8)).Return(nil).Once()
+
+	err := resizeRandomRBDImage(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestBlocklistRandomRBDWatcher() {
+	s.mockRBDImages()
+	s.cluster.On("ListRBDWatchers", "rbd", "image1").Return([]ceph.RBDWatcher{}, nil).Once()
+	s.cluster.On("ListRBDWatchers", "volumes", "image2").Return([]ceph.RBDWatcher{
+		{Address: "192.168.1.15:0/3416557163", Client: 4125},
+	}, nil).Once()
+	s.rnd.On("Intn", 1).Return(0).Once()
+	s.rnd.On("Intn", 10).Return(4).Once()
+	s.cluster.On("BlocklistAdd", "192.168.1.15:0/3416557163", 5*time.Minute).Return(nil).Once()
+
+	rollbacks, err := blocklistRandomRBDWatcher(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+	s.Require().Equal([]rollback{{
+		Action: rollbackActionBlocklistRemove,
+		Args:   []string{"192.168.1.15:0/3416557163"},
+	}}, rollbacks)
+}
+
+func (s *cephTestSuite) TestBlocklistRandomRBDWatcherNoWatchers() {
+	s.mockRBDImages()
+	s.cluster.On("ListRBDWatchers", "rbd", "image1").Return([]ceph.RBDWatcher{}, nil).Once()
+	s.cluster.On("ListRBDWatchers", "volumes", "image2").Return([]ceph.RBDWatcher{}, nil).Once()
+
+	_, err := blocklistRandomRBDWatcher(s.ctx, s.cluster, s.rnd)
+	s.Require().Error(err)
+}
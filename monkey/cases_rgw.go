@@ -0,0 +1,109 @@
+package monkey
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/teran/go-collection/random"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+const (
+	minRGWStopWindow = 1 * time.Minute
+	maxRGWStopWindow = 10 * time.Minute
+
+	tinyBucketQuotaMaxObjects = 10
+	tinyBucketQuotaMaxSize    = 1024 * 1024
+)
+
+func hasRGWDaemons(ctx context.Context, c drivers.Cluster) bool {
+	daemons, err := c.ListRGWDaemons(ctx)
+	if err != nil {
+		log.Debugf("error listing RGW daemons: %s", err)
+		return false
+	}
+
+	return len(daemons) > 0
+}
+
+// stopRandomRGWDaemon stops the random RGW daemon for the random window and
+// returns the rollback starting it back once the window is over
+func stopRandomRGWDaemon(ctx context.Context, c drivers.Cluster, rnd random.Random) ([]rollback, error) {
+	daemon, err := randomRGWDaemon(ctx, c, rnd)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.StopDaemon(ctx, daemon.DaemonName); err != nil {
+		return nil, err
+	}
+
+	return []rollback{{
+		Action: rollbackActionStartDaemon,
+		Args:   []string{daemon.DaemonName},
+		Delay:  minRGWStopWindow + time.Duration(rnd.Int63n(int64(maxRGWStopWindow-minRGWStopWindow))),
+	}}, nil
+}
+
+func restartRandomRGWDaemon(ctx context.Context, c drivers.Cluster, rnd random.Random) error {
+	daemon, err := randomRGWDaemon(ctx, c, rnd)
+	if err != nil {
+		return err
+	}
+
+	return c.RestartDaemon(ctx, daemon.DaemonName)
+}
+
+func setTinyQuotaForRandomBucket(ctx context.Context, c drivers.Cluster, rnd random.Random) error {
+	buckets, err := c.ListBuckets(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(buckets) == 0 {
+		return errors.New("no buckets are present in the cluster")
+	}
+
+	return c.SetBucketQuota(ctx,
+		buckets[rnd.Intn(len(buckets))],
+		int64(rnd.Intn(tinyBucketQuotaMaxObjects)+1),
+		int64(rnd.Intn(tinyBucketQuotaMaxSize)+1),
+	)
+}
+
+func makeRandomZoneReadOnly(ctx context.Context, c drivers.Cluster, rnd random.Random) ([]rollback, error) {
+	zones, err := c.ListZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(zones) == 0 {
+		return nil, errors.New("no zones are present in the cluster")
+	}
+
+	// the rollback is returned even on error since the zone could be
+	// modified with the period commit failed
+	zone := zones[rnd.Intn(len(zones))]
+	return []rollback{{
+		Action: rollbackActionSetZoneReadOnly,
+		Args:   []string{zone, strconv.FormatBool(false)},
+	}}, c.SetZoneReadOnly(ctx, zone, true)
+}
+
+func randomRGWDaemon(ctx context.Context, c drivers.Cluster, rnd random.Random) (ceph.Daemon, error) {
+	daemons, err := c.ListRGWDaemons(ctx)
+	if err != nil {
+		return ceph.Daemon{}, err
+	}
+
+	if len(daemons) == 0 {
+		return ceph.Daemon{}, errors.New("no RGW daemons are present in the cluster")
+	}
+
+	return daemons[rnd.Intn(len(daemons))], nil
+}
@@ -0,0 +1,99 @@
+package monkey
+
+import (
+	"errors"
+	"time"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+)
+
+func (s *cephTestSuite) TestHasRGWDaemons() {
+	s.cluster.On("ListRGWDaemons").Return([]ceph.Daemon{{DaemonName: "rgw.objects.ceph01.tqwbbd"}}, nil).Once()
+	s.Require().True(hasRGWDaemons(s.ctx, s.cluster))
+
+	s.cluster.On("ListRGWDaemons").Return([]ceph.Daemon{}, nil).Once()
+	s.Require().False(hasRGWDaemons(s.ctx, s.cluster))
+
+	s.cluster.On("ListRGWDaemons").Return([]ceph.Daemon(nil), errors.New("test error")).Once()
+	s.Require().False(hasRGWDaemons(s.ctx, s.cluster))
+}
+
+func (s *cephTestSuite) TestStopRandomRGWDaemon() {
+	s.cluster.On("ListRGWDaemons").Return([]ceph.Daemon{
+		{DaemonName: "rgw.objects.ceph01.tqwbbd"},
+		{DaemonName: "rgw.objects.ceph02.qkzhwv"},
+	}, nil).Once()
+	s.rnd.On("Intn", 2).Return(1).Once()
+	s.cluster.On("StopDaemon", "rgw.objects.ceph02.qkzhwv").Return(nil).Once()
+	s.rnd.On("Int63n", int64(maxRGWStopWindow-minRGWStopWindow)).Return(int64(30 * time.Second)).Once()
+
+	rollbacks, err := stopRandomRGWDaemon(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+	s.Require().Equal([]rollback{{
+		Action: rollbackActionStartDaemon,
+		Args:   []string{"rgw.objects.ceph02.qkzhwv"},
+		Delay:  minRGWStopWindow + 30*time.Second,
+	}}, rollbacks)
+}
+
+func (s *cephTestSuite) TestStopRandomRGWDaemonNoDaemons() {
+	s.cluster.On("ListRGWDaemons").Return([]ceph.Daemon{}, nil).Once()
+
+	_, err := stopRandomRGWDaemon(s.ctx, s.cluster, s.rnd)
+	s.Require().Error(err)
+}
+
+func (s *cephTestSuite) TestRestartRandomRGWDaemon() {
+	s.cluster.On("ListRGWDaemons").Return([]ceph.Daemon{
+		{DaemonName: "rgw.objects.ceph01.tqwbbd"},
+	}, nil).Once()
+	s.rnd.On("Intn", 1).Return(0).Once()
+	s.cluster.On("RestartDaemon", "rgw.objects.ceph01.tqwbbd").Return(nil).Once()
+
+	err := restartRandomRGWDaemon(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestSetTinyQuotaForRandomBucket() {
+	s.cluster.On("ListBuckets").Return([]string{"bucket1", "bucket2"}, nil).Once()
+	s.rnd.On("Intn", 2).Return(0).Once()
+	s.rnd.On("Intn", tinyBucketQuotaMaxObjects).Return(4).Once()
+	s.rnd.On("Intn", tinyBucketQuotaMaxSize).Return(1023).Once()
+	s.cluster.On("SetBucketQuota", "bucket1", int64(5), int64(1024)).Return(nil).Once()
+
+	err := setTinyQuotaForRandomBucket(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestSetTinyQuotaForRandomBucketNoBuckets() {
+	s.cluster.On("ListBuckets").Return([]string{}, nil).Once()
+
+	err := setTinyQuotaForRandomBucket(s.ctx, s.cluster, s.rnd)
+	s.Require().Error(err)
+}
+
+func (s *cephTestSuite) TestMakeRandomZoneReadOnly() {
+	s.cluster.On("ListZones").Return([]string{"default"}, nil).Once()
+	s.rnd.On("Intn", 1).Return(0).Once()
+	s.cluster.On("SetZoneReadOnly", "default", true).Return(nil).Once()
+
+	rollbacks, err := makeRandomZoneReadOnly(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+	s.Require().Equal([]rollback{{
+		Action: rollbackActionSetZoneReadOnly,
+		Args:   []string{"default", "false"},
+	}}, rollbacks)
+}
+
+func (s *cephTestSuite) TestMakeRandomZoneReadOnlyError() {
+	s.cluster.On("ListZones").Return([]string{"default"}, nil).Once()
+	s.rnd.On("Intn", 1).Return(0).Once()
+	s.cluster.On("SetZoneReadOnly", "default", true).Return(errors.New("test error")).Once()
+
+	rollbacks, err := makeRandomZoneReadOnly(s.ctx, s.cluster, s.rnd)
+	s.Require().Error(err)
+	s.Require().Equal([]rollback{{
+		Action: rollbackActionSetZoneReadOnly,
+		Args:   []string{"default", "false"},
+	}}, rollbacks)
+}
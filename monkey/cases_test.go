@@ -3,6 +3,7 @@ package monkey
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 	"github.com/teran/go-collection/random"
@@ -15,8 +16,24 @@ func (s *cephTestSuite) TestSetRandomFlag() {
 	s.rnd.On("Intn", len(cephFlags)).Return(3).Once()
 	s.cluster.On("SetFlag", ceph.FlagNoOut).Return(nil).Once()
 
-	err := setRandomFlag(s.ctx, s.cluster, s.rnd)
+	rollbacks, err := setRandomFlag(s.ctx, s.cluster, s.rnd)
 	s.Require().NoError(err)
+	s.Require().Empty(rollbacks)
+}
+
+func (s *cephTestSuite) TestSetRandomFlagTimeBoxed() {
+	s.rnd.On("Intn", len(cephFlags)).Return(len(cephFlags) - 1).Once()
+	s.cluster.On("SetFlag", ceph.FlagPause).Return(nil).Once()
+	s.rnd.On("Int63n", int64(maxFreezeWindow-minFreezeWindow)).Return(int64(30 * time.Second)).Once()
+
+	rollbacks, err := setRandomFlag(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+	s.Require().Equal([]rollback{{
+		Action: rollbackActionUnsetFlag,
+		Args:   []string{"pause"},
+		Delay:  time.Minute,
+		Stall:  true,
+	}}, rollbacks)
 }
 
 func (s *cephTestSuite) TestUnsetRandomFlag() {
@@ -126,7 +143,7 @@ func (s *cephTestSuite) TestSetRandomFlagForRandomGroup() {
 		{Hostname: "host2"},
 		{Hostname: "host3"},
 	}, nil).Once()
-	s.rnd.On("Intn", len(cephFlags)).Return(2).Once()
+	s.rnd.On("Intn", len(osdFlags)).Return(2).Once()
 
 	expectedTargets := []string{"osd.1", "osd.2", "osd.3"}
 	s.cluster.On("SetGroupFlag", ceph.FlagNoIn, expectedTargets).Return(nil).Once()
@@ -142,7 +159,7 @@ func (s *cephTestSuite) TestUnsetRandomFlagFromRandomGroup() {
 		{Hostname: "host2"},
 		{Hostname: "host3"},
 	}, nil).Once()
-	s.rnd.On("Intn", len(cephFlags)).Return(1).Once()
+	s.rnd.On("Intn", len(osdFlags)).Return(1).Once()
 
 	expectedTargets := []string{"osd.1", "osd.2", "osd.3"}
 	s.cluster.On("UnsetGroupFlag", ceph.FlagNoDeepScrub, expectedTargets).Return(nil).Once()
@@ -0,0 +1,59 @@
+package monkey
+
+import (
+	"context"
+	"errors"
+
+	"github.com/teran/go-collection/random"
+
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+func setRandomOSDReweight(ctx context.Context, c drivers.Cluster, rnd random.Random) error {
+	id, err := randomOSDID(ctx, c, rnd)
+	if err != nil {
+		return err
+	}
+
+	return c.SetOSDReweight(ctx, id, randomWeight(rnd))
+}
+
+func setRandomOSDPrimaryAffinity(ctx context.Context, c drivers.Cluster, rnd random.Random) error {
+	id, err := randomOSDID(ctx, c, rnd)
+	if err != nil {
+		return err
+	}
+
+	return c.SetPrimaryAffinity(ctx, id, randomWeight(rnd))
+}
+
+func setRandomOSDCrushWeight(ctx context.Context, c drivers.Cluster, rnd random.Random) error {
+	id, err := randomOSDID(ctx, c, rnd)
+	if err != nil {
+		return err
+	}
+
+	return c.SetOSDCrushWeight(ctx, id, randomWeight(rnd))
+}
+
+func randomOSDID(ctx context.Context, c drivers.Cluster, rnd random.Random) (uint64, error) {
+	ids, err := c.GetOSDIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(ids) == 0 {
+		return 0, errors.New("no OSDs are present in the cluster")
+	}
+
+	return ids[rnd.Intn(len(ids))], nil
+}
+
+// randomWeight returns either zero to exclude the OSD completely or a random
+// fraction to skew the distribution
+func randomWeight(rnd random.Random) float64 {
+	if rnd.Intn(2) == 0 {
+		return 0
+	}
+	return rnd.Float64()
+}
@@ -0,0 +1,50 @@
+package monkey
+
+func (s *cephTestSuite) TestSetRandomOSDReweightToZero() {
+	s.cluster.On("GetOSDIDs").Return([]uint64{3, 5, 7}, nil).Once()
+	s.rnd.On("Intn", 3).Return(1).Once()
+	s.rnd.On("Intn", 2).Return(0).Once()
+	s.cluster.On("SetOSDReweight", uint64(5), 0.0).Return(nil).Once()
+
+	err := setRandomOSDReweight(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestSetRandomOSDReweightToFraction() {
+	s.cluster.On("GetOSDIDs").Return([]uint64{3, 5, 7}, nil).Once()
+	s.rnd.On("Intn", 3).Return(2).Once()
+	s.rnd.On("Intn", 2).Return(1).Once()
+	s.rnd.On("Float64").Return(0.35).Once()
+	s.cluster.On("SetOSDReweight", uint64(7), 0.35).Return(nil).Once()
+
+	err := setRandomOSDReweight(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestSetRandomOSDPrimaryAffinity() {
+	s.cluster.On("GetOSDIDs").Return([]uint64{3, 5, 7}, nil).Once()
+	s.rnd.On("Intn", 3).Return(0).Once()
+	s.rnd.On("Intn", 2).Return(1).Once()
+	s.rnd.On("Float64").Return(0.5).Once()
+	s.cluster.On("SetPrimaryAffinity", uint64(3), 0.5).Return(nil).Once()
+
+	err := setRandomOSDPrimaryAffinity(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+}
+
+func (s *cephTestSuite) TestSetRandomOSDPrimaryAffinityNoOSDs() {
+	s.cluster.On("GetOSDIDs").Return([]uint64{}, nil).Once()
+
+	err := setRandomOSDPrimaryAffinity(s.ctx, s.cluster, s.rnd)
+	s.Require().Error(err)
+}
+
+func (s *cephTestSuite) TestSetRandomOSDCrushWeight() {
+	s.cluster.On("GetOSDIDs").Return([]uint64{3, 5, 7}, nil).Once()
+	s.rnd.On("Intn", 3).Return(2).Once()
+	s.rnd.On("Intn", 2).Return(0).Once()
+	s.cluster.On("SetOSDCrushWeight", uint64(7), 0.0).Return(nil).Once()
+
+	err := setRandomOSDCrushWeight(s.ctx, s.cluster, s.rnd)
+	s.Require().NoError(err)
+}
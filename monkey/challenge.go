@@ -0,0 +1,295 @@
+package monkey
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/teran/go-collection/random"
+
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+const (
+	challengeStateFile = "ceph-chaos-monkey.challenge.json"
+
+	defaultChallengePollInterval = 10 * time.Second
+)
+
+// challengeInjectFn injects the fault and returns the params the verifiers
+// need to tell it's fixed, e.g. the original pool size
+type challengeInjectFn func(ctx context.Context, c drivers.Cluster, rnd random.Random) (map[string]string, error)
+
+// Challenge is the single fault the trainee has to find and fix in time
+type Challenge struct {
+	ID   string
+	Name string
+
+	// Objective is what the trainee is told: the symptom, not the cause
+	Objective string
+	TimeLimit time.Duration
+
+	// fussID is the fuss from the catalogue the challenge is allowed by in
+	// the policy
+	fussID    string
+	inject    challengeInjectFn
+	verifiers []verifier
+}
+
+// ChallengeState is kept between `challenge start` and `challenge status`
+type ChallengeState struct {
+	ID        string            `json:"id"`
+	StartedAt time.Time         `json:"started_at"`
+	PassedAt  *time.Time        `json:"passed_at,omitempty"`
+	Params    map[string]string `json:"params,omitempty"`
+}
+
+type challengeOutcome string
+
+const (
+	challengeOutcomeInProgress challengeOutcome = "in progress"
+	challengeOutcomePassed     challengeOutcome = "passed"
+	challengeOutcomeFailed     challengeOutcome = "failed"
+)
+
+type verifierResult struct {
+	name string
+	ok   bool
+}
+
+type challengeResult struct {
+	outcome   challengeOutcome
+	elapsed   time.Duration
+	verifiers []verifierResult
+}
+
+type Challenger interface {
+	List()
+	Start(ctx context.Context, id string) error
+	Status(ctx context.Context, wait bool) error
+}
+
+type challenger struct {
+	cluster      drivers.Cluster
+	rnd          random.Random
+	printer      Printer
+	confirmer    Confirmer
+	policy       SafetyPolicy
+	stateDir     string
+	pollInterval time.Duration
+}
+
+// NewChallenger creates the challenger keeping the state of the current
+// challenge in stateDir
+func NewChallenger(cluster drivers.Cluster, rnd random.Random, printer Printer, confirmer Confirmer, policy SafetyPolicy, stateDir string) Challenger {
+	return &challenger{
+		cluster:      cluster,
+		rnd:          rnd,
+		printer:      printer,
+		confirmer:    confirmer,
+		policy:       policy,
+		stateDir:     stateDir,
+		pollInterval: defaultChallengePollInterval,
+	}
+}
+
+func (c *challenger) List() {
+	for _, ch := range challenges {
+		c.printer.Printf("%-22s %-8s %s\n", ch.ID, ch.TimeLimit, ch.Name)
+	}
+}
+
+func (c *challenger) Start(ctx context.Context, id string) error {
+	ch, ok := challengeByID(id)
+	if !ok {
+		return fmt.Errorf("unknown challenge `%s`", id)
+	}
+
+	state, err := c.loadState()
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return err
+	case state.PassedAt == nil && time.Since(state.StartedAt) < challengeTimeLimit(state.ID):
+		c.printer.Printf("Challenge %s is in progress, check it with `challenge status` or remove %s to abandon it\n", state.ID, c.statePath())
+		return nil
+	}
+
+	if err := c.policy.Check(ctx, c.cluster); err != nil {
+		c.printer.Printf("Refusing to start the challenge: %s\n", err)
+		return nil
+	}
+
+	if f, ok := fussByID(ch.fussID); ok && !c.policy.allows(f) {
+		c.printer.Printf("Refusing to start the challenge: it requires `%s` fuss which is not allowed\n", ch.fussID)
+		return nil
+	}
+
+	if !c.confirmer.Confirm(ctx, fmt.Sprintf("Challenge `%s` will break the cluster on purpose. Continue?", ch.Name)) {
+		c.printer.Println("Challenge is not started")
+		return nil
+	}
+
+	params, err := ch.inject(ctx, c.cluster, c.rnd)
+	if err != nil {
+		return fmt.Errorf("error injecting the fault: %w", err)
+	}
+
+	if err := c.saveState(ChallengeState{
+		ID:        ch.ID,
+		StartedAt: time.Now(),
+		Params:    params,
+	}); err != nil {
+		return err
+	}
+
+	c.printer.Printf("Challenge `%s` is started, you have %s.\n", ch.Name, ch.TimeLimit)
+	c.printer.Println(ch.Objective)
+	c.printer.Println("Check your progress with `challenge status`.")
+
+	return nil
+}
+
+func (c *challenger) Status(ctx context.Context, wait bool) error {
+	state, err := c.loadState()
+	if errors.Is(err, os.ErrNotExist) {
+		c.printer.Println("No challenge is started")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ch, ok := challengeByID(state.ID)
+	if !ok {
+		return fmt.Errorf("unknown challenge `%s` in %s", state.ID, c.statePath())
+	}
+
+	for {
+		res, err := c.check(ctx, ch, &state)
+		if err != nil {
+			return err
+		}
+
+		if !wait || res.outcome != challengeOutcomeInProgress {
+			c.printResult(ch, res)
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			c.printResult(ch, res)
+			return nil
+		case <-time.After(c.pollInterval):
+		}
+	}
+}
+
+// check runs the verifiers and records the time the challenge is passed at so
+// it's reported the same way afterwards
+func (c *challenger) check(ctx context.Context, ch Challenge, state *ChallengeState) (challengeResult, error) {
+	if state.PassedAt != nil {
+		return challengeResult{
+			outcome: challengeOutcomePassed,
+			elapsed: state.PassedAt.Sub(state.StartedAt),
+		}, nil
+	}
+
+	now := time.Now()
+	res := challengeResult{
+		outcome: challengeOutcomePassed,
+		elapsed: now.Sub(state.StartedAt),
+	}
+
+	for _, v := range ch.verifiers {
+		ok, err := v.check(ctx, c.cluster, state.Params)
+		if err != nil {
+			log.Debugf("error verifying `%s`: %s", v.name, err)
+		}
+
+		res.verifiers = append(res.verifiers, verifierResult{name: v.name, ok: ok})
+		if !ok {
+			res.outcome = challengeOutcomeInProgress
+		}
+	}
+
+	// the fix made after the time limit doesn't count
+	if res.elapsed >= ch.TimeLimit {
+		res.outcome = challengeOutcomeFailed
+		return res, nil
+	}
+
+	if res.outcome == challengeOutcomePassed {
+		state.PassedAt = &now
+		return res, c.saveState(*state)
+	}
+
+	return res, nil
+}
+
+func (c *challenger) printResult(ch Challenge, res challengeResult) {
+	c.printer.Printf("Challenge: %s\n", ch.Name)
+	for _, v := range res.verifiers {
+		mark := " "
+		if v.ok {
+			mark = "x"
+		}
+		c.printer.Printf("[%s] %s\n", mark, v.name)
+	}
+
+	elapsed := res.elapsed.Truncate(time.Second)
+	switch res.outcome {
+	case challengeOutcomePassed:
+		c.printer.Printf("PASS: fixed in %s of %s\n", elapsed, ch.TimeLimit)
+	case challengeOutcomeFailed:
+		c.printer.Printf("FAIL: not fixed within %s\n", ch.TimeLimit)
+	default:
+		c.printer.Printf("In progress: %s of %s elapsed\n", elapsed, ch.TimeLimit)
+	}
+}
+
+func (c *challenger) statePath() string {
+	return filepath.Join(c.stateDir, challengeStateFile)
+}
+
+func (c *challenger) loadState() (ChallengeState, error) {
+	data, err := os.ReadFile(c.statePath())
+	if err != nil {
+		return ChallengeState{}, err
+	}
+
+	state := ChallengeState{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return ChallengeState{}, fmt.Errorf("error decoding challenge state %s: %w", c.statePath(), err)
+	}
+
+	return state, nil
+}
+
+func (c *challenger) saveState(state ChallengeState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.statePath(), data, 0o600)
+}
+
+func challengeByID(id string) (Challenge, bool) {
+	for _, ch := range challenges {
+		if ch.ID == id {
+			return ch, true
+		}
+	}
+	return Challenge{}, false
+}
+
+func challengeTimeLimit(id string) time.Duration {
+	ch, _ := challengeByID(id)
+	return ch.TimeLimit
+}
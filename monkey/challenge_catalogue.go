@@ -0,0 +1,254 @@
+package monkey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/teran/go-collection/random"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+var challenges = []Challenge{
+	{
+		ID:        "cluster-paused",
+		Name:      "cluster is paused",
+		Objective: "Clients complain all the reads and writes hang. Make the cluster serve IO again.",
+		TimeLimit: 10 * time.Minute,
+		fussID:    "set-flag",
+		inject:    setFlagsChallenge(ceph.FlagPause),
+		verifiers: []verifier{verifyFlagsCleared, verifyHealthOK},
+	},
+	{
+		ID:        "recovery-blocked",
+		Name:      "recovery is blocked",
+		Objective: "The cluster doesn't recover from the failures any more. Make it heal itself again.",
+		TimeLimit: 15 * time.Minute,
+		fussID:    "set-flag",
+		inject:    setFlagsChallenge(ceph.FlagNoRecover, ceph.FlagNoBackfill, ceph.FlagNoRebalance),
+		verifiers: []verifier{verifyFlagsCleared, verifyPGsActiveClean, verifyHealthOK},
+	},
+	{
+		ID:        "pool-size-shrunk",
+		Name:      "pool keeps fewer replicas",
+		Objective: "One of the pools keeps fewer copies of the data than it's supposed to. Find it and restore the redundancy.",
+		TimeLimit: 20 * time.Minute,
+		fussID:    "resize-pool",
+		inject:    shrinkPoolChallenge,
+		verifiers: []verifier{verifyPoolSizeRestored, verifyPGsActiveClean, verifyHealthOK},
+	},
+	{
+		ID:        "osd-down",
+		Name:      "OSD is down",
+		Objective: "Some data is degraded. Bring the cluster back to the fully redundant state.",
+		TimeLimit: 20 * time.Minute,
+		fussID:    "stop-osd",
+		inject:    stopOSDChallenge,
+		verifiers: []verifier{verifyPGsActiveClean, verifyHealthOK},
+	},
+	{
+		ID:        "monitor-removed",
+		Name:      "monitor is removed",
+		Objective: "The cluster has lost one of its monitors. Restore the monitors and their quorum.",
+		TimeLimit: 30 * time.Minute,
+		fussID:    "remove-monitor",
+		inject:    removeMonitorChallenge,
+		verifiers: []verifier{verifyMonQuorumRestored, verifyHealthOK},
+	},
+}
+
+func setFlagsChallenge(flags ...ceph.Flag) challengeInjectFn {
+	return func(ctx context.Context, c drivers.Cluster, _ random.Random) (map[string]string, error) {
+		names := []string{}
+		for _, f := range flags {
+			if err := c.SetFlag(ctx, f); err != nil {
+				return nil, err
+			}
+			names = append(names, string(f))
+		}
+
+		return map[string]string{"flags": strings.Join(names, ",")}, nil
+	}
+}
+
+// shrinkPoolChallenge decreases the size of the random pool keeping at least
+// two replicas so no data is at risk
+func shrinkPoolChallenge(ctx context.Context, c drivers.Cluster, rnd random.Random) (map[string]string, error) {
+	pools, err := c.GetPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []ceph.Pool{}
+	for _, p := range pools {
+		if p.Size >= 3 {
+			candidates = append(candidates, p)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, errors.New("no pools with size of 3 or more are present in the cluster")
+	}
+
+	pool := candidates[rnd.Intn(len(candidates))]
+	if err := c.ResizePool(ctx, pool.PoolName, pool.Size-1); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		"pool": pool.PoolName,
+		"size": strconv.FormatUint(pool.Size, 10),
+	}, nil
+}
+
+func stopOSDChallenge(ctx context.Context, c drivers.Cluster, rnd random.Random) (map[string]string, error) {
+	ids, err := c.GetOSDIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return nil, errors.New("no OSDs are present in the cluster")
+	}
+
+	id := ids[rnd.Intn(len(ids))]
+	if err := c.StopOSDDaemon(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"osd": strconv.FormatUint(id, 10)}, nil
+}
+
+// removeMonitorChallenge removes the random monitor only if the rest could
+// keep the quorum
+func removeMonitorChallenge(ctx context.Context, c drivers.Cluster, rnd random.Random) (map[string]string, error) {
+	mons, err := c.GetMons(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(mons) < 3 {
+		return nil, fmt.Errorf("at least 3 monitors are required, %d present", len(mons))
+	}
+
+	mon := mons[rnd.Intn(len(mons))]
+	if err := c.RemoveMonitor(ctx, mon.Name); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		"mon":  mon.Name,
+		"mons": strconv.Itoa(len(mons)),
+	}, nil
+}
+
+// verifier checks the single objective of the challenge using the params
+// saved on injection
+type verifier struct {
+	name  string
+	check func(ctx context.Context, c drivers.Cluster, params map[string]string) (bool, error)
+}
+
+var verifyHealthOK = verifier{
+	name: "cluster health is HEALTH_OK",
+	check: func(ctx context.Context, c drivers.Cluster, _ map[string]string) (bool, error) {
+		health, err := c.GetHealth(ctx)
+		if err != nil {
+			return false, err
+		}
+		return health.Status == "HEALTH_OK", nil
+	},
+}
+
+var verifyPGsActiveClean = verifier{
+	name: "all PGs are active+clean",
+	check: func(ctx context.Context, c drivers.Cluster, _ map[string]string) (bool, error) {
+		pgs, err := c.ListPGs(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		for _, pg := range pgs {
+			if !pg.IsActiveClean() {
+				return false, nil
+			}
+		}
+		return true, nil
+	},
+}
+
+var verifyFlagsCleared = verifier{
+	name: "cluster flags are cleared",
+	check: func(ctx context.Context, c drivers.Cluster, params map[string]string) (bool, error) {
+		flags, err := c.GetFlags(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		for _, f := range strings.Split(params["flags"], ",") {
+			if slices.Contains(flags, ceph.Flag(f)) {
+				return false, nil
+			}
+		}
+		return true, nil
+	},
+}
+
+var verifyPoolSizeRestored = verifier{
+	name: "pool size is restored",
+	check: func(ctx context.Context, c drivers.Cluster, params map[string]string) (bool, error) {
+		size, err := strconv.ParseUint(params["size"], 10, 64)
+		if err != nil {
+			return false, fmt.Errorf("invalid pool size `%s`: %w", params["size"], err)
+		}
+
+		pools, err := c.GetPools(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		for _, p := range pools {
+			if p.PoolName == params["pool"] {
+				return p.Size >= size, nil
+			}
+		}
+		return false, fmt.Errorf("pool %s is not found", params["pool"])
+	},
+}
+
+var verifyMonQuorumRestored = verifier{
+	name: "all monitors are back in quorum",
+	check: func(ctx context.Context, c drivers.Cluster, params map[string]string) (bool, error) {
+		n, err := strconv.Atoi(params["mons"])
+		if err != nil {
+			return false, fmt.Errorf("invalid number of monitors `%s`: %w", params["mons"], err)
+		}
+
+		mons, err := c.GetMons(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		if len(mons) < n {
+			return false, nil
+		}
+
+		quorum, err := c.GetMonQuorum(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		for _, m := range mons {
+			if !slices.Contains(quorum, m.Name) {
+				return false, nil
+			}
+		}
+		return true, nil
+	},
+}
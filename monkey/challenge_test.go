@@ -0,0 +1,168 @@
+package monkey
+
+import (
+	"time"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+)
+
+func (s *cephTestSuite) newTestChallenger() *challenger {
+	return &challenger{
+		cluster:      s.cluster,
+		rnd:          s.rnd,
+		printer:      NewPrinter(),
+		confirmer:    confirmerFunc(func(string) bool { return true }),
+		policy:       SafetyPolicy{AllowedFSIDs: []string{testFSID}},
+		stateDir:     s.T().TempDir(),
+		pollInterval: time.Millisecond,
+	}
+}
+
+func (s *cephTestSuite) TestChallengeStart() {
+	c := s.newTestChallenger()
+
+	s.cluster.On("GetFSID").Return(testFSID, nil).Once()
+	s.cluster.On("GetPools").Return([]ceph.Pool{
+		{PoolName: ".mgr", Size: 2},
+		{PoolName: "rbd", Size: 3},
+	}, nil).Once()
+	s.rnd.On("Intn", 1).Return(0).Once()
+	s.cluster.On("ResizePool", "rbd", uint64(2)).Return(nil).Once()
+
+	s.Require().NoError(c.Start(s.ctx, "pool-size-shrunk"))
+
+	state, err := c.loadState()
+	s.Require().NoError(err)
+	s.Require().Equal("pool-size-shrunk", state.ID)
+	s.Require().Equal(map[string]string{"pool": "rbd", "size": "3"}, state.Params)
+	s.Require().Nil(state.PassedAt)
+
+	// the challenge in progress is not replaced
+	s.Require().NoError(c.Start(s.ctx, "cluster-paused"))
+
+	state, err = c.loadState()
+	s.Require().NoError(err)
+	s.Require().Equal("pool-size-shrunk", state.ID)
+}
+
+func (s *cephTestSuite) TestChallengeStartRefusedByPolicy() {
+	c := s.newTestChallenger()
+	c.policy.AllowedFusses = []string{"set-flag"}
+
+	s.cluster.On("GetFSID").Return(testFSID, nil).Once()
+
+	s.Require().NoError(c.Start(s.ctx, "monitor-removed"))
+	s.Require().NoFileExists(c.statePath())
+}
+
+func (s *cephTestSuite) TestChallengeStartUnknown() {
+	c := s.newTestChallenger()
+	s.Require().EqualError(c.Start(s.ctx, "format-disks"), "unknown challenge `format-disks`")
+}
+
+func (s *cephTestSuite) TestChallengeCheck() {
+	c := s.newTestChallenger()
+	ch, _ := challengeByID("recovery-blocked")
+
+	state := ChallengeState{
+		ID:        ch.ID,
+		StartedAt: time.Now().Add(-time.Minute),
+		Params:    map[string]string{"flags": "norecover,nobackfill,norebalance"},
+	}
+	s.Require().NoError(c.saveState(state))
+
+	s.cluster.On("GetFlags").Return([]ceph.Flag{"sortbitwise", ceph.FlagNoBackfill}, nil).Once()
+	s.cluster.On("ListPGs").Return([]ceph.PGStat{{PGID: "1.0", State: "active+clean"}}, nil).Once()
+	s.cluster.On("GetHealth").Return(ceph.Health{Status: "HEALTH_WARN"}, nil).Once()
+
+	res, err := c.check(s.ctx, ch, &state)
+	s.Require().NoError(err)
+	s.Require().Equal(challengeOutcomeInProgress, res.outcome)
+	s.Require().Equal([]verifierResult{
+		{name: "cluster flags are cleared", ok: false},
+		{name: "all PGs are active+clean", ok: true},
+		{name: "cluster health is HEALTH_OK", ok: false},
+	}, res.verifiers)
+
+	s.cluster.On("GetFlags").Return([]ceph.Flag{"sortbitwise"}, nil).Once()
+	s.cluster.On("ListPGs").Return([]ceph.PGStat{{PGID: "1.0", State: "active+clean+scrubbing"}}, nil).Once()
+	s.cluster.On("GetHealth").Return(ceph.Health{Status: "HEALTH_OK"}, nil).Once()
+
+	res, err = c.check(s.ctx, ch, &state)
+	s.Require().NoError(err)
+	s.Require().Equal(challengeOutcomePassed, res.outcome)
+	s.Require().GreaterOrEqual(res.elapsed, time.Minute)
+
+	// the passed challenge keeps its time without checking the cluster again
+	saved, err := c.loadState()
+	s.Require().NoError(err)
+	s.Require().NotNil(saved.PassedAt)
+
+	res, err = c.check(s.ctx, ch, &saved)
+	s.Require().NoError(err)
+	s.Require().Equal(challengeOutcomePassed, res.outcome)
+	s.Require().Equal(saved.PassedAt.Sub(saved.StartedAt), res.elapsed)
+}
+
+func (s *cephTestSuite) TestChallengeCheckTimeLimit() {
+	c := s.newTestChallenger()
+	ch, _ := challengeByID("osd-down")
+
+	state := ChallengeState{
+		ID:        ch.ID,
+		StartedAt: time.Now().Add(-ch.TimeLimit - time.Second),
+		Params:    map[string]string{"osd": "3"},
+	}
+
+	s.cluster.On("ListPGs").Return([]ceph.PGStat{{PGID: "1.0", State: "active+undersized+degraded"}}, nil).Once()
+	s.cluster.On("GetHealth").Return(ceph.Health{Status: "HEALTH_WARN"}, nil).Once()
+
+	res, err := c.check(s.ctx, ch, &state)
+	s.Require().NoError(err)
+	s.Require().Equal(challengeOutcomeFailed, res.outcome)
+}
+
+func (s *cephTestSuite) TestChallengeCheckFixedLate() {
+	c := s.newTestChallenger()
+	ch, _ := challengeByID("osd-down")
+
+	state := ChallengeState{
+		ID:        ch.ID,
+		StartedAt: time.Now().Add(-ch.TimeLimit - 5*time.Minute),
+		Params:    map[string]string{"osd": "3"},
+	}
+	s.Require().NoError(c.saveState(state))
+
+	s.cluster.On("ListPGs").Return([]ceph.PGStat{{PGID: "1.0", State: "active+clean"}}, nil).Once()
+	s.cluster.On("GetHealth").Return(ceph.Health{Status: "HEALTH_OK"}, nil).Once()
+
+	res, err := c.check(s.ctx, ch, &state)
+	s.Require().NoError(err)
+	s.Require().Equal(challengeOutcomeFailed, res.outcome)
+	s.Require().Nil(state.PassedAt)
+
+	saved, err := c.loadState()
+	s.Require().NoError(err)
+	s.Require().Nil(saved.PassedAt)
+}
+
+func (s *cephTestSuite) TestChallengeStatusWait() {
+	c := s.newTestChallenger()
+	s.Require().NoError(c.saveState(ChallengeState{
+		ID:        "monitor-removed",
+		StartedAt: time.Now(),
+		Params:    map[string]string{"mon": "ceph03", "mons": "3"},
+	}))
+
+	s.cluster.On("GetMons").Return([]ceph.Mon{{Name: "ceph01"}, {Name: "ceph02"}}, nil).Once()
+	s.cluster.On("GetHealth").Return(ceph.Health{Status: "HEALTH_WARN"}, nil).Once()
+	s.cluster.On("GetMons").Return([]ceph.Mon{{Name: "ceph01"}, {Name: "ceph02"}, {Name: "ceph03"}}, nil).Once()
+	s.cluster.On("GetMonQuorum").Return([]string{"ceph01", "ceph02", "ceph03"}, nil).Once()
+	s.cluster.On("GetHealth").Return(ceph.Health{Status: "HEALTH_OK"}, nil).Once()
+
+	s.Require().NoError(c.Status(s.ctx, true))
+
+	state, err := c.loadState()
+	s.Require().NoError(err)
+	s.Require().NotNil(state.PassedAt)
+}
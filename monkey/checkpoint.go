@@ -0,0 +1,395 @@
+package monkey
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+const (
+	checkpointVersion  = 1
+	checkpointInterval = 15 * time.Second
+)
+
+// checkpoint is the state of the game saved periodically so the game could
+// be resumed if the process dies
+type checkpoint struct {
+	Version int       `json:"version"`
+	SavedAt time.Time `json:"saved_at"`
+
+	FSID     string `json:"fsid"`
+	PoolName string `json:"pool_name"`
+
+	Interval time.Duration `json:"interval"`
+	Duration time.Duration `json:"duration"`
+	Elapsed  time.Duration `json:"elapsed"`
+
+	Level   LevelProfile    `json:"level"`
+	Profile WorkloadProfile `json:"profile"`
+
+	// Seed and Draws are the position in the random stream the fusses are
+	// picked from, nil for the game which is not seeded
+	Seed  *int64 `json:"seed,omitempty"`
+	Draws uint64 `json:"draws"`
+
+	LedgerDir   string `json:"ledger_dir"`
+	ReportDir   string `json:"report_dir"`
+	RBDWorkload bool   `json:"rbd_workload"`
+
+	CephFSDir  string            `json:"cephfs_dir,omitempty"`
+	S3Workload *S3WorkloadConfig `json:"s3_workload,omitempty"`
+
+	Scenarios     []Scenario        `json:"scenarios,omitempty"`
+	ScenariosOnly bool              `json:"scenarios_only"`
+	Scenario      *scenarioSnapshot `json:"scenario,omitempty"`
+
+	Console   bool               `json:"console"`
+	HintsUsed int                `json:"hints_used"`
+	Blind     bool               `json:"blind"`
+	Incidents []incidentSnapshot `json:"incidents,omitempty"`
+
+	// Paused keeps the game paused by the instructor paused on resume
+	Paused bool `json:"paused"`
+
+	Stats     MeasurementValue  `json:"stats"`
+	Journal   []JournalEntry    `json:"journal"`
+	Rollbacks []pendingRollback `json:"rollbacks"`
+}
+
+type scenarioSnapshot struct {
+	Scenario Scenario          `json:"scenario"`
+	PC       int               `json:"pc"`
+	Due      time.Time         `json:"due"`
+	Executed int               `json:"executed"`
+	Vars     map[string]string `json:"vars"`
+}
+
+type incidentSnapshot struct {
+	ID        string       `json:"id"`
+	FussID    string       `json:"fuss_id"`
+	Entry     JournalEntry `json:"entry"`
+	Diagnosed bool         `json:"diagnosed"`
+	Correct   bool         `json:"correct"`
+}
+
+// WithCheckpointDir enables the game state to be saved to the directory
+// periodically so the game could be resumed with Resume
+func WithCheckpointDir(dir string) Option {
+	return func(m *monkey) {
+		m.checkpointDir = dir
+	}
+}
+
+// Resume creates the monkey continuing the game from the checkpoint file: with
+// the remaining time, the same random stream position and the pending
+// rollbacks. The options are applied before the checkpoint so they couldn't
+// change the game itself, the console is dropped if the game is played
+// without it.
+func Resume(cluster drivers.Cluster, path string, printer Printer, opts ...Option) (Monkey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cp := checkpoint{}
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("error decoding checkpoint %s: %w", path, err)
+	}
+
+	if cp.Version != checkpointVersion {
+		return nil, fmt.Errorf("unsupported checkpoint version %d", cp.Version)
+	}
+
+	stats := NewStats()
+	restoreStats(stats, cp.Stats)
+
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	m := newMonkey(cluster, rnd, printer, stats, cp.Interval, cp.Duration, opts...)
+	if err := m.restore(cp); err != nil {
+		return nil, fmt.Errorf("error restoring checkpoint %s: %w", path, err)
+	}
+	m.checkpointPath = path
+
+	return m, nil
+}
+
+func (m *monkey) restore(cp checkpoint) error {
+	m.resumed = true
+	m.fsid = cp.FSID
+	m.bgIOPoolName = cp.PoolName
+	m.elapsed = cp.Elapsed
+	m.level = cp.Level
+	m.profile = cp.Profile
+	m.ledgerDir = cp.LedgerDir
+	m.reportDir = cp.ReportDir
+	m.scenarios = cp.Scenarios
+	m.scenariosOnly = cp.ScenariosOnly
+	m.journal = cp.Journal
+	m.rollbacks = cp.Rollbacks
+	m.blind = cp.Blind
+	m.paused = cp.Paused
+	m.score = score{hintsUsed: cp.HintsUsed}
+
+	if !cp.Console {
+		m.console = nil
+	}
+
+	if cp.Seed != nil {
+		m.seed = *cp.Seed
+		m.source = newCountingSource(*cp.Seed, cp.Draws)
+		m.rnd = newLockedRandom(rand.New(m.source))
+	}
+
+	if cp.RBDWorkload {
+		m.rbdWorkloadRnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	if cp.CephFSDir != "" {
+		WithCephFSWorkload(cp.CephFSDir, rand.New(rand.NewSource(time.Now().UnixNano())))(m)
+	}
+
+	if cp.S3Workload != nil {
+		opt, err := WithS3Workload(*cp.S3Workload, rand.New(rand.NewSource(time.Now().UnixNano())))
+		if err != nil {
+			return err
+		}
+		opt(m)
+	}
+
+	if s := cp.Scenario; s != nil {
+		m.scenario = &scenarioRun{
+			scenario: s.Scenario,
+			pc:       s.PC,
+			due:      s.Due,
+			executed: s.Executed,
+			vars:     s.Vars,
+		}
+	}
+
+	for _, i := range cp.Incidents {
+		m.incidents = append(m.incidents, &incident{
+			id:        i.ID,
+			fussID:    i.FussID,
+			entry:     i.Entry,
+			diagnosed: i.Diagnosed,
+			correct:   i.Correct,
+		})
+
+		m.score.incidents++
+		if i.Correct {
+			m.score.correct++
+		}
+	}
+
+	return nil
+}
+
+// resume refuses to resume the game on the cluster other than the one it's
+// been started on and asks for the confirmation once since the trainee has
+// already agreed to play the game
+func (m *monkey) resume(ctx context.Context) error {
+	if err := m.policy.Check(ctx, m.cluster); err != nil {
+		m.printer.Printf("Refusing to resume the game: %s\n", err)
+		return nil
+	}
+
+	fsid, err := m.cluster.GetFSID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if fsid != m.fsid {
+		m.printer.Printf("Refusing to resume the game: it's been started on cluster %s, not %s\n", m.fsid, fsid)
+		return nil
+	}
+
+	left := (m.duration - m.elapsed).Truncate(time.Second)
+	if !m.confirmer.Confirm(ctx, fmt.Sprintf("Resume the game on cluster %s with %s left?", fsid, left)) {
+		m.printer.Println("The game is not resumed, the checkpoint is kept")
+		return nil
+	}
+	m.played = true
+
+	m.printer.Printf("Resuming the game with %s left and %d pending rollbacks ...\n", left, len(m.rollbacks))
+
+	m.journal = append(m.journal, JournalEntry{
+		Timestamp: time.Now(),
+		Entry:     fmt.Sprintf("the game is resumed from the checkpoint with %s left", left),
+	})
+
+	return m.play(ctx)
+}
+
+// startCheckpoints saves the first checkpoint and returns true if the
+// checkpoints are enabled
+func (m *monkey) startCheckpoints(ctx context.Context) bool {
+	if m.checkpointPath == "" {
+		if m.checkpointDir == "" {
+			return false
+		}
+		m.checkpointPath = filepath.Join(m.checkpointDir, m.bgIOPoolName+".checkpoint.json")
+	}
+
+	if m.fsid == "" {
+		fsid, err := m.cluster.GetFSID(ctx)
+		if err != nil {
+			log.Warnf("error getting cluster fsid, the game is not checkpointed: %s", err)
+			return false
+		}
+		m.fsid = fsid
+	}
+
+	m.saveCheckpoint()
+	m.printer.Printf("The game is checkpointed to %s, resume it with `resume --checkpoint %s` if the process dies\n", m.checkpointPath, m.checkpointPath)
+
+	return true
+}
+
+// saveCheckpoint is called from the game loop so the state is consistent
+func (m *monkey) saveCheckpoint() {
+	if m.checkpointPath == "" {
+		return
+	}
+
+	cp := checkpoint{
+		Version:       checkpointVersion,
+		SavedAt:       time.Now(),
+		FSID:          m.fsid,
+		PoolName:      m.bgIOPoolName,
+		Interval:      m.interval,
+		Duration:      m.duration,
+		Elapsed:       m.gameElapsed(),
+		Level:         m.level,
+		Profile:       m.profile,
+		LedgerDir:     m.ledgerDir,
+		ReportDir:     m.reportDir,
+		RBDWorkload:   m.rbdWorkloadRnd != nil,
+		CephFSDir:     m.cephFSDir,
+		S3Workload:    m.s3Workload,
+		Scenarios:     m.scenarios,
+		ScenariosOnly: m.scenariosOnly,
+		Console:       m.console != nil,
+		HintsUsed:     m.score.hintsUsed,
+		Blind:         m.blind,
+		Paused:        m.paused,
+		Stats:         m.stats.Dump(),
+		Journal:       m.journal,
+		Rollbacks:     m.rollbacks,
+	}
+
+	if m.source != nil {
+		seed := m.seed
+		cp.Seed = &seed
+		cp.Draws = m.source.draws.Load()
+	}
+
+	if run := m.scenario; run != nil {
+		cp.Scenario = &scenarioSnapshot{
+			Scenario: run.scenario,
+			PC:       run.pc,
+			Due:      run.due,
+			Executed: run.executed,
+			Vars:     run.vars,
+		}
+	}
+
+	for _, i := range m.incidents {
+		cp.Incidents = append(cp.Incidents, incidentSnapshot{
+			ID:        i.id,
+			FussID:    i.fussID,
+			Entry:     i.entry,
+			Diagnosed: i.diagnosed,
+			Correct:   i.correct,
+		})
+	}
+
+	if err := writeFileAtomic(m.checkpointPath, cp); err != nil {
+		log.Warnf("error saving checkpoint: %s", err)
+	}
+}
+
+// keepFailedRollbacks saves the rollbacks failed even after the retries to
+// the checkpoint so they're retried on resume, and lists them to be reverted
+// manually if the game is not checkpointed
+func (m *monkey) keepFailedRollbacks() {
+	m.printer.Println("Failed to revert the temporary changes:")
+	for _, r := range m.rollbacks {
+		m.printer.Printf("  %s\n", r.Rollback)
+	}
+
+	if m.checkpointPath != "" {
+		m.saveCheckpoint()
+		m.printer.Printf("Resume the game with `resume --checkpoint %s` to retry reverting them\n", m.checkpointPath)
+	}
+
+	// the deferred doRollbacks is not to retry them once again
+	m.rollbacks = nil
+}
+
+// removeCheckpoint is called once the game is over so it's not resumed
+func (m *monkey) removeCheckpoint() {
+	if m.checkpointPath == "" {
+		return
+	}
+
+	if err := os.Remove(m.checkpointPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Warnf("error removing checkpoint: %s", err)
+	}
+}
+
+// writeFileAtomic replaces the file with the JSON of v so the process dying
+// in the middle of the write doesn't leave the file truncated
+func writeFileAtomic(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// restoreStats sets the stats counters to the checkpointed values. The
+// latencies are restored from the averages.
+func restoreStats(s Stats, v MeasurementValue) {
+	st, ok := s.(*stats)
+	if !ok {
+		return
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	st.writesCountTotal = v.WritesCountTotal
+	st.writesErrorsTotal = v.WritesErrorsTotal
+	st.totalWritesLatency = v.AvgWritesLatency * time.Duration(v.WritesCountTotal)
+
+	st.readsCountTotal = v.ReadsCountTotal
+	st.readsErrorsTotal = v.ReadsErrorsTotal
+	st.totalReadsLatency = v.AvgReadsLatency * time.Duration(v.ReadsCountTotal)
+
+	st.stallsCountTotal = v.StallsCountTotal
+	st.stallsDurationTotal = v.StallsDurationTotal
+	st.lostWritesTotal = v.LostWritesTotal
+
+	st.metadataOpsCountTotal = v.MetadataOpsCountTotal
+	st.metadataOpsErrorsTotal = v.MetadataOpsErrorsTotal
+	st.totalMetadataOpsLatency = v.AvgMetadataOpsLatency * time.Duration(v.MetadataOpsCountTotal)
+
+	st.deletesCountTotal = v.DeletesCountTotal
+	st.deletesErrorsTotal = v.DeletesErrorsTotal
+	st.totalDeletesLatency = v.AvgDeletesLatency * time.Duration(v.DeletesCountTotal)
+}
@@ -0,0 +1,289 @@
+package monkey
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+)
+
+func (s *cephTestSuite) TestCountingSourceReplay() {
+	src := newCountingSource(42, 0)
+	rnd := rand.New(src)
+	for range 5 {
+		rnd.Intn(100)
+	}
+	rnd.Uint32()
+	rnd.Uint64()
+
+	replayed := rand.New(newCountingSource(42, src.draws.Load()))
+	for range 5 {
+		s.Require().Equal(rnd.Int63(), replayed.Int63())
+	}
+}
+
+func (s *cephTestSuite) TestCheckpointResume() {
+	dir := s.T().TempDir()
+
+	m := s.newRunMonkey(10*time.Minute, WithSeed(42), WithCheckpointDir(dir), WithBlindMode())
+	m.fsid = testFSID
+	m.startedAt = time.Now().Add(-time.Minute)
+	m.rollbacks = []pendingRollback{
+		{Rollback: rollback{Action: rollbackActionUnsetFlag, Args: []string{"noout"}, Delay: 5 * time.Minute}, Since: m.startedAt},
+	}
+	m.journal = []JournalEntry{{Timestamp: m.startedAt.UTC(), Entry: "set noout flag"}}
+	m.newIncident("set-flag", m.journal[0])
+	m.score.hintsUsed = 1
+	m.stats.(*stats).writesCountTotal = 2
+
+	for range 3 {
+		m.rnd.Intn(10)
+	}
+
+	path := filepath.Join(dir, "chaos-monkey-1.checkpoint.json")
+	m.checkpointPath = path
+	m.saveCheckpoint()
+
+	r, err := Resume(s.cluster, path, NewPrinter())
+	s.Require().NoError(err)
+
+	resumed := r.(*monkey)
+	s.Require().True(resumed.resumed)
+	s.Require().Equal(testFSID, resumed.fsid)
+	s.Require().Equal("chaos-monkey-1", resumed.bgIOPoolName)
+	s.Require().Equal(path, resumed.checkpointPath)
+	s.Require().Equal(10*time.Minute, resumed.duration)
+	s.Require().InDelta(time.Minute, resumed.elapsed, float64(time.Second))
+	s.Require().Equal(m.journal, resumed.journal)
+	s.Require().Equal(m.rollbacks[0].Rollback, resumed.rollbacks[0].Rollback)
+	s.Require().True(m.rollbacks[0].Since.Equal(resumed.rollbacks[0].Since))
+	s.Require().Equal(m.score, resumed.score)
+	s.Require().Equal(m.incidents[0].id, resumed.incidents[0].id)
+	s.Require().Nil(resumed.console)
+	s.Require().Equal(uint64(2), resumed.stats.Dump().WritesCountTotal)
+
+	// the fusses are picked from the same random stream position
+	for range 5 {
+		s.Require().Equal(m.rnd.Intn(1000), resumed.rnd.Intn(1000))
+	}
+}
+
+func (s *cephTestSuite) TestCheckpointResumeWorkloads() {
+	dir := s.T().TempDir()
+
+	s3Opt, err := WithS3Workload(S3WorkloadConfig{
+		Endpoint:  "http://127.0.0.1:7480",
+		Region:    "us-east-1",
+		AccessKey: "access",
+		SecretKey: "secret",
+		Bucket:    "bucket",
+	}, s.rnd)
+	s.Require().NoError(err)
+
+	m := s.newRunMonkey(10*time.Minute, WithCephFSWorkload(dir, s.rnd), s3Opt)
+	m.fsid = testFSID
+	m.startedAt = time.Now().Add(-time.Minute)
+	m.paused = true
+	m.pausedAt = m.startedAt.Add(30 * time.Second)
+
+	path := filepath.Join(dir, "chaos-monkey-1.checkpoint.json")
+	m.checkpointPath = path
+	m.saveCheckpoint()
+
+	r, err := Resume(s.cluster, path, NewPrinter())
+	s.Require().NoError(err)
+
+	resumed := r.(*monkey)
+	s.Require().True(resumed.paused)
+	s.Require().InDelta(30*time.Second, resumed.elapsed, float64(time.Second))
+	s.Require().Equal(dir, resumed.cephFSDir)
+	s.Require().Equal(m.s3Workload, resumed.s3Workload)
+	s.Require().Len(resumed.workloads, 2)
+	s.Require().Equal("CephFS", resumed.workloads[0].workload.Name())
+	s.Require().Equal("S3", resumed.workloads[1].workload.Name())
+}
+
+func (s *cephTestSuite) TestAddRollbacksSavesCheckpoint() {
+	m := s.newTestMonkey()
+	m.checkpointPath = filepath.Join(s.T().TempDir(), "chaos-monkey-123.checkpoint.json")
+
+	m.addRollbacks(rollback{Action: rollbackActionUnsetFlag, Args: []string{"noout"}, Delay: time.Second})
+
+	cp := s.readCheckpoint(m.checkpointPath)
+	s.Require().Len(cp.Rollbacks, 1)
+
+	m.rollbacks[0].Since = time.Now().Add(-time.Minute)
+	s.cluster.On("UnsetFlag", ceph.Flag("noout")).Return(nil).Once()
+	m.doDueRollbacks(s.ctx)
+
+	cp = s.readCheckpoint(m.checkpointPath)
+	s.Require().Empty(cp.Rollbacks)
+}
+
+func (s *cephTestSuite) TestKeepFailedRollbacks() {
+	m := s.newTestMonkey()
+	m.printer = &bufferPrinter{}
+	m.checkpointPath = filepath.Join(s.T().TempDir(), "chaos-monkey-123.checkpoint.json")
+	m.rollbacks = []pendingRollback{
+		{Rollback: rollback{Action: rollbackActionUnsetFlag, Args: []string{"pause"}}, Since: time.Now(), Failed: true},
+	}
+
+	m.keepFailedRollbacks()
+	s.Require().Nil(m.rollbacks)
+	s.Require().Contains(m.printer.(*bufferPrinter).String(), "  unset-flag pause\n")
+
+	cp := s.readCheckpoint(m.checkpointPath)
+	s.Require().Len(cp.Rollbacks, 1)
+	s.Require().True(cp.Rollbacks[0].Failed)
+}
+
+func (s *cephTestSuite) readCheckpoint(path string) checkpoint {
+	data, err := os.ReadFile(path)
+	s.Require().NoError(err)
+
+	cp := checkpoint{}
+	s.Require().NoError(json.Unmarshal(data, &cp))
+	return cp
+}
+
+func (s *cephTestSuite) TestResumeInvalidCheckpoint() {
+	path := filepath.Join(s.T().TempDir(), "checkpoint.json")
+	s.Require().NoError(os.WriteFile(path, []byte(`{"version": 100}`), 0o600))
+
+	_, err := Resume(s.cluster, path, NewPrinter())
+	s.Require().EqualError(err, "unsupported checkpoint version 100")
+}
+
+func (s *cephTestSuite) TestRunResumed() {
+	dir := s.T().TempDir()
+	path := filepath.Join(dir, "chaos-monkey-1.checkpoint.json")
+
+	profile := DefaultWorkloadProfile()
+	profile.Mix = OpsMix{Read: 1}
+	profile.Concurrency = 1
+
+	s.Require().NoError(writeFileAtomic(path, checkpoint{
+		Version:   checkpointVersion,
+		FSID:      testFSID,
+		PoolName:  "chaos-monkey-1",
+		Interval:  30 * time.Second,
+		Duration:  10 * time.Minute,
+		Elapsed:   10*time.Minute - 200*time.Millisecond,
+		Level:     defaultLevelProfile(),
+		Profile:   profile,
+		LedgerDir: dir,
+		ReportDir: dir,
+		Journal:   []JournalEntry{{Timestamp: time.Now(), Entry: "set noout flag"}},
+		Rollbacks: []pendingRollback{
+			{Rollback: rollback{Action: rollbackActionUnsetFlag, Args: []string{"noout"}}, Since: time.Now()},
+		},
+	}))
+
+	m, err := Resume(s.cluster, path, NewPrinter(),
+		WithSafetyPolicy(SafetyPolicy{AllowedFSIDs: []string{testFSID}}),
+		WithConfirmer(confirmerFunc(func(string) bool { return true })),
+	)
+	s.Require().NoError(err)
+
+	s.cluster.On("GetFSID").Return(testFSID, nil).Twice()
+	s.cluster.On("GetPools").Return([]ceph.Pool{{PoolName: "chaos-monkey-1"}}, nil).Once()
+	s.cluster.On("GetAuth", "client.chaos-monkey-1").Return(ceph.AuthEntity{}, errors.New("not found")).Once()
+	s.cluster.On("CreateAuth", "client.chaos-monkey-1", mock.Anything).Return(ceph.AuthEntity{}, errors.New("access denied")).Once()
+	s.cluster.On("UnsetFlag", ceph.Flag("noout")).Return(nil).Once()
+	s.cluster.On("GetOSDs").Return([]ceph.OSD{{ID: 0, KbUsed: 1024, KbAvailable: 1024}}, nil).Once()
+
+	s.Require().NoError(m.Run(s.ctx))
+
+	report := s.readReport(m.(*monkey))
+	s.Require().Contains(report, "set noout flag")
+	s.Require().Contains(report, "the game is resumed from the checkpoint")
+
+	// the checkpoint is removed once the game is over
+	_, err = os.Stat(path)
+	s.Require().ErrorIs(err, os.ErrNotExist)
+}
+
+func (s *cephTestSuite) TestRunResumedRBDWorkload() {
+	dir := s.T().TempDir()
+	path := filepath.Join(dir, "chaos-monkey-1.checkpoint.json")
+
+	profile := DefaultWorkloadProfile()
+	profile.Mix = OpsMix{Read: 1}
+	profile.Concurrency = 1
+
+	s.Require().NoError(writeFileAtomic(path, checkpoint{
+		Version:     checkpointVersion,
+		FSID:        testFSID,
+		PoolName:    "chaos-monkey-1",
+		Interval:    30 * time.Second,
+		Duration:    10 * time.Minute,
+		Elapsed:     10*time.Minute - 200*time.Millisecond,
+		Level:       defaultLevelProfile(),
+		Profile:     profile,
+		LedgerDir:   dir,
+		ReportDir:   dir,
+		RBDWorkload: true,
+	}))
+
+	m, err := Resume(s.cluster, path, NewPrinter(),
+		WithSafetyPolicy(SafetyPolicy{AllowedFSIDs: []string{testFSID}}),
+		WithConfirmer(confirmerFunc(func(string) bool { return true })),
+	)
+	s.Require().NoError(err)
+
+	s.cluster.On("GetFSID").Return(testFSID, nil).Twice()
+	s.cluster.On("GetPools").Return([]ceph.Pool{{PoolName: "chaos-monkey-1"}}, nil).Once()
+	s.cluster.On("GetAuth", "client.chaos-monkey-1").Return(ceph.AuthEntity{}, errors.New("not found")).Once()
+	s.cluster.On("CreateAuth", "client.chaos-monkey-1", mock.Anything).Return(ceph.AuthEntity{}, errors.New("access denied")).Once()
+	s.cluster.On("GetOSDs").Return([]ceph.OSD{{ID: 0, KbUsed: 1024, KbAvailable: 1024}}, nil).Once()
+
+	// the images are left by the game before the checkpoint
+	image := make([]byte, rbdWorkloadImageSizeMiB*1024*1024)
+	s.cluster.On("ListRBDImages", "chaos-monkey-1").Return([]string{rbdWorkloadBaseImage, rbdWorkloadImage}, nil).Once()
+	s.cluster.On("ListRBDSnapshots", "chaos-monkey-1", rbdWorkloadImage).Return([]ceph.RBDSnapshot{}, nil).Once()
+	s.cluster.On("WriteRBDImage", "chaos-monkey-1", rbdWorkloadImage, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		copy(image[args.Get(2).(uint64):], args.Get(3).([]byte))
+	}).Return(nil).Maybe()
+	s.cluster.On("ExportRBDImage", "chaos-monkey-1", rbdWorkloadImage).Return(image, nil).Maybe()
+	s.cluster.On("CreateRBDSnapshot", "chaos-monkey-1", rbdWorkloadImage, mock.Anything).Return(nil).Maybe()
+	s.cluster.On("RemoveRBDSnapshot", "chaos-monkey-1", rbdWorkloadImage, mock.Anything).Return(nil).Maybe()
+
+	s.Require().NoError(m.Run(s.ctx))
+
+	workloads := m.(*monkey).workloads
+	s.Require().Len(workloads, 1)
+	s.Require().Equal("RBD", workloads[0].workload.Name())
+
+	stats := workloads[0].stats.Dump()
+	s.Require().NotZero(stats.WritesCountTotal)
+	s.Require().Zero(stats.ReadsErrorsTotal)
+	s.Require().Zero(stats.MetadataOpsErrorsTotal)
+}
+
+func (s *cephTestSuite) TestRunResumedOnOtherCluster() {
+	path := filepath.Join(s.T().TempDir(), "checkpoint.json")
+	s.Require().NoError(writeFileAtomic(path, checkpoint{
+		Version:  checkpointVersion,
+		FSID:     testFSID,
+		Duration: time.Minute,
+	}))
+
+	buf := &bufferPrinter{}
+	m, err := Resume(s.cluster, path, buf,
+		WithSafetyPolicy(SafetyPolicy{AllowedFSIDs: []string{testFSID, "6e7c2b4f-0c3f-11f0-9d3c-525400a1b2c3"}}),
+		WithConfirmer(confirmerFunc(func(string) bool { return true })),
+	)
+	s.Require().NoError(err)
+
+	s.cluster.On("GetFSID").Return("6e7c2b4f-0c3f-11f0-9d3c-525400a1b2c3", nil).Twice()
+
+	s.Require().NoError(m.Run(s.ctx))
+	s.Require().Contains(buf.String(), "Refusing to resume the game: it's been started on cluster "+testFSID)
+	s.Require().FileExists(path)
+}
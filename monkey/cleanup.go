@@ -0,0 +1,430 @@
+package monkey
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+const (
+	// poolApplication is the application tag set on the pools created by the
+	// monkey so they could be found by cleanup even if renamed
+	poolApplication = "chaos-monkey"
+
+	monAllowPoolDelete = "mon_allow_pool_delete"
+
+	// runningCheckpointAge is the age of the checkpoint the game is
+	// considered running by since the checkpoint is saved periodically
+	runningCheckpointAge = 3 * checkpointInterval
+)
+
+type artifactKind string
+
+const (
+	artifactKindPool           artifactKind = "pool"
+	artifactKindCrushRule      artifactKind = "crush rule"
+	artifactKindConfigOverride artifactKind = "config override"
+	artifactKindClientKey      artifactKind = "client key"
+	artifactKindBlocklistEntry artifactKind = "blocklist entry"
+	artifactKindLedger         artifactKind = "ledger file"
+	artifactKindCheckpoint     artifactKind = "checkpoint file"
+)
+
+type artifact struct {
+	kind artifactKind
+	name string
+
+	// who is the config section the override is set for
+	who string
+
+	// revert is the rollback recorded by the game to revert the change, the
+	// override is removed if nil
+	revert *rollback
+}
+
+func (a artifact) String() string {
+	if a.kind == artifactKindConfigOverride {
+		return fmt.Sprintf("%s %s for %s", a.kind, a.name, a.who)
+	}
+	return fmt.Sprintf("%s %s", a.kind, a.name)
+}
+
+type Cleaner interface {
+	Run(ctx context.Context) error
+}
+
+type cleaner struct {
+	cluster       drivers.Cluster
+	printer       Printer
+	confirmer     Confirmer
+	policy        SafetyPolicy
+	ledgerDir     string
+	checkpointDir string
+
+	// overrides are the config overrides named by the user in the form of
+	// `<who>/<name>` to be removed besides the ones recorded by the games
+	overrides []string
+}
+
+// NewCleaner creates the cleaner removing everything left in the cluster by
+// the past games. The config overrides and the blocklist entries are found in
+// the checkpoints of the games the process died during, the overrides given
+// are removed as well.
+func NewCleaner(cluster drivers.Cluster, printer Printer, confirmer Confirmer, policy SafetyPolicy, ledgerDir, checkpointDir string, overrides []string) Cleaner {
+	return &cleaner{
+		cluster:       cluster,
+		printer:       printer,
+		confirmer:     confirmer,
+		policy:        policy,
+		ledgerDir:     ledgerDir,
+		checkpointDir: checkpointDir,
+		overrides:     overrides,
+	}
+}
+
+func (c *cleaner) Run(ctx context.Context) error {
+	if err := c.policy.Check(ctx, c.cluster); err != nil {
+		c.printer.Printf("Refusing to clean up the cluster: %s\n", err)
+		return nil
+	}
+
+	artifacts, running, err := c.find(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(running) > 0 {
+		c.printer.Println("The following games are still running, their artifacts are skipped:")
+		for _, pool := range running {
+			c.printer.Printf("- %s\n", pool)
+		}
+	}
+
+	if len(artifacts) == 0 {
+		c.printer.Println("Nothing left by the past games is found")
+		return nil
+	}
+
+	c.printer.Println("The following artifacts of the past games are found:")
+	for _, a := range artifacts {
+		c.printer.Printf("- %s\n", a)
+	}
+
+	if !c.confirmer.Confirm(ctx, "Delete all of them?") {
+		c.printer.Println("Nothing is deleted")
+		return nil
+	}
+
+	if err := c.remove(ctx, artifacts); err != nil {
+		return err
+	}
+
+	c.printer.Printf("%d artifacts deleted\n", len(artifacts))
+	return nil
+}
+
+// find lists the artifacts created by the monkey: everything named with
+// artifactPrefix, pools tagged with poolApplication and the config overrides
+// and blocklist entries recorded in the checkpoints. The overrides and the
+// blocklist entries could be set by the cluster administrator as well so
+// they're never guessed by the names. The pools, client keys and ledgers of
+// the running games are skipped and their pools are returned. The checkpoints
+// are listed last so they're removed once everything else is cleaned and the
+// games aren't resumed to apply the stale rollbacks.
+func (c *cleaner) find(ctx context.Context) ([]artifact, []string, error) {
+	artifacts := []artifact{}
+
+	games, err := c.checkpointedGames(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	running := []string{}
+	recorded := []rollback{}
+	ledgers := map[string]bool{}
+	for _, g := range games {
+		if g.running {
+			running = append(running, g.PoolName)
+			continue
+		}
+
+		for _, r := range g.Rollbacks {
+			recorded = append(recorded, r.Rollback)
+		}
+		ledgers[g.PoolName] = true
+	}
+
+	pools, err := c.cluster.GetPools(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, p := range pools {
+		_, tagged := p.ApplicationMetadata[poolApplication]
+		if (!tagged && !strings.HasPrefix(p.PoolName, artifactPrefix)) || slices.Contains(running, p.PoolName) {
+			continue
+		}
+
+		artifacts = append(artifacts, artifact{kind: artifactKindPool, name: p.PoolName})
+		ledgers[p.PoolName] = true
+	}
+
+	rules, err := c.cluster.ListCrushRules(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, r := range rules {
+		if strings.HasPrefix(r, artifactPrefix) {
+			artifacts = append(artifacts, artifact{kind: artifactKindCrushRule, name: r})
+		}
+	}
+
+	overrides, err := c.findConfigOverrides(ctx, recorded)
+	if err != nil {
+		return nil, nil, err
+	}
+	artifacts = append(artifacts, overrides...)
+
+	entities, err := c.cluster.ListAuth(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, e := range entities {
+		if strings.HasPrefix(e.Entity, ioClientPrefix) && !slices.Contains(running, strings.TrimPrefix(e.Entity, "client.")) {
+			artifacts = append(artifacts, artifact{kind: artifactKindClientKey, name: e.Entity})
+		}
+	}
+
+	entries, err := c.cluster.ListBlocklist(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, e := range entries {
+		for _, r := range recorded {
+			if r.Action == rollbackActionBlocklistRemove && len(r.Args) == 1 && r.Args[0] == e.Addr {
+				artifacts = append(artifacts, artifact{kind: artifactKindBlocklistEntry, name: e.Addr})
+				break
+			}
+		}
+	}
+
+	// the ledger directory could be shared by the games on other clusters so
+	// only the ledgers of the pools found are removed
+	if c.ledgerDir != "" {
+		files, err := filepath.Glob(filepath.Join(c.ledgerDir, artifactPrefix+"*.ledger.jsonl"))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, f := range files {
+			if ledgers[strings.TrimSuffix(filepath.Base(f), ".ledger.jsonl")] {
+				artifacts = append(artifacts, artifact{kind: artifactKindLedger, name: f})
+			}
+		}
+	}
+
+	for _, g := range games {
+		if !g.running {
+			artifacts = append(artifacts, artifact{kind: artifactKindCheckpoint, name: g.path})
+		}
+	}
+
+	return artifacts, running, nil
+}
+
+// findConfigOverrides returns the overrides set by the games which are still
+// in place and the ones named by the user
+func (c *cleaner) findConfigOverrides(ctx context.Context, recorded []rollback) ([]artifact, error) {
+	named := map[artifact]bool{}
+	for _, o := range c.overrides {
+		i := strings.LastIndex(o, "/")
+		if i <= 0 || i == len(o)-1 {
+			return nil, fmt.Errorf("config override must be in the form of <who>/<name>, got `%s`", o)
+		}
+		named[artifact{kind: artifactKindConfigOverride, who: o[:i], name: o[i+1:]}] = true
+	}
+
+	opts, err := c.cluster.DumpConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	artifacts := []artifact{}
+	for _, o := range opts {
+		a := artifact{kind: artifactKindConfigOverride, name: o.Name, who: o.Who()}
+
+		// the latest recorded change is reverted first in the game, so the
+		// earliest one has the original value
+		for _, r := range recorded {
+			if (r.Action == rollbackActionSetConfig || r.Action == rollbackActionRemoveConfig) &&
+				len(r.Args) >= 2 && r.Args[0] == a.who && r.Args[1] == a.name {
+				a.revert = &r
+				break
+			}
+		}
+
+		if a.revert != nil || named[a] {
+			artifacts = append(artifacts, a)
+		}
+	}
+
+	return artifacts, nil
+}
+
+// checkpointedGame is the game checkpointed on this cluster
+type checkpointedGame struct {
+	checkpoint
+
+	path    string
+	running bool
+}
+
+// checkpointedGames returns the games checkpointed on this cluster in the
+// order of the checkpoint files
+func (c *cleaner) checkpointedGames(ctx context.Context) ([]checkpointedGame, error) {
+	if c.checkpointDir == "" {
+		return nil, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(c.checkpointDir, artifactPrefix+"*.checkpoint.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	fsid, err := c.cluster.GetFSID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	games := []checkpointedGame{}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+
+		cp := checkpoint{}
+		if err := json.Unmarshal(data, &cp); err != nil {
+			log.Warnf("error decoding checkpoint %s, skipping it: %s", f, err)
+			continue
+		}
+
+		if cp.FSID != fsid {
+			continue
+		}
+
+		games = append(games, checkpointedGame{
+			checkpoint: cp,
+			path:       f,
+			running:    time.Since(cp.SavedAt) < runningCheckpointAge,
+		})
+	}
+
+	return games, nil
+}
+
+func (c *cleaner) remove(ctx context.Context, artifacts []artifact) error {
+	hasPools := false
+	for _, a := range artifacts {
+		hasPools = hasPools || a.kind == artifactKindPool
+	}
+
+	if hasPools {
+		restore, err := c.allowPoolDelete(ctx)
+		if err != nil {
+			return err
+		}
+		defer restore()
+	}
+
+	for _, a := range artifacts {
+		log.Debugf("removing %s", a)
+
+		var err error
+		switch a.kind {
+		case artifactKindPool:
+			err = c.cluster.DeletePool(ctx, a.name)
+		case artifactKindCrushRule:
+			err = c.cluster.RemoveCrushRule(ctx, a.name)
+		case artifactKindConfigOverride:
+			if a.revert != nil {
+				err = a.revert.apply(ctx, c.cluster)
+			} else {
+				err = c.cluster.RemoveConfig(ctx, a.who, a.name)
+			}
+		case artifactKindClientKey:
+			err = c.cluster.DeleteAuth(ctx, a.name)
+		case artifactKindBlocklistEntry:
+			err = c.cluster.BlocklistRemove(ctx, a.name)
+		case artifactKindLedger, artifactKindCheckpoint:
+			err = os.Remove(a.name)
+		}
+
+		if err != nil {
+			return fmt.Errorf("error removing %s: %w", a, err)
+		}
+	}
+
+	return nil
+}
+
+// allowPoolDelete enables pool deletion on monitors and returns the function
+// putting the previous setting back
+func (c *cleaner) allowPoolDelete(ctx context.Context) (func(), error) {
+	value, err := c.cluster.GetConfig(ctx, "mon", monAllowPoolDelete)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(value) == "true" {
+		return func() {}, nil
+	}
+
+	opts, err := c.cluster.DumpConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prev, overridden := "", false
+	for _, o := range opts {
+		if o.Name == monAllowPoolDelete && o.Who() == "mon" {
+			prev, overridden = o.Value, true
+		}
+	}
+
+	if err := c.cluster.SetConfig(ctx, "mon", monAllowPoolDelete, "true"); err != nil {
+		return nil, err
+	}
+
+	// the setting must be restored even if the cleanup is interrupted
+	ctx = context.WithoutCancel(ctx)
+
+	return func() {
+		var err error
+		if overridden {
+			err = c.cluster.SetConfig(ctx, "mon", monAllowPoolDelete, prev)
+		} else {
+			err = c.cluster.RemoveConfig(ctx, "mon", monAllowPoolDelete)
+		}
+
+		if err != nil {
+			log.Warnf("error restoring %s: %s", monAllowPoolDelete, err)
+		}
+	}, nil
+}
@@ -0,0 +1,198 @@
+package monkey
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+)
+
+func (s *cephTestSuite) TestCleanerFind() {
+	dir := s.T().TempDir()
+	ledgerPath := filepath.Join(dir, "chaos-monkey-123.ledger.jsonl")
+	s.Require().NoError(os.WriteFile(ledgerPath, nil, 0o600))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "other.ledger.jsonl"), nil, 0o600))
+	// the ledgers of the games on other clusters and of the running games are
+	// kept
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "chaos-monkey-456.ledger.jsonl"), nil, 0o600))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "chaos-monkey-789.ledger.jsonl"), nil, 0o600))
+
+	checkpointPath := filepath.Join(dir, "chaos-monkey-123.checkpoint.json")
+	s.Require().NoError(writeFileAtomic(checkpointPath, checkpoint{
+		Version:  checkpointVersion,
+		SavedAt:  time.Now().Add(-time.Hour),
+		FSID:     testFSID,
+		PoolName: "chaos-monkey-123",
+		Rollbacks: []pendingRollback{
+			{Rollback: rollback{Action: rollbackActionSetConfig, Args: []string{"osd", "osd_max_backfills", "1"}}},
+			{Rollback: rollback{Action: rollbackActionRemoveConfig, Args: []string{"osd", "osd_max_backfills"}}},
+			{Rollback: rollback{Action: rollbackActionBlocklistRemove, Args: []string{"10.0.0.1:0/3710147553"}}},
+		},
+	}))
+	// the changes recorded on the other cluster are never reverted
+	s.Require().NoError(writeFileAtomic(filepath.Join(dir, "chaos-monkey-456.checkpoint.json"), checkpoint{
+		Version: checkpointVersion,
+		FSID:    "6e7c2b4f-0c3f-11f0-9d3c-525400a1b2c3",
+		Rollbacks: []pendingRollback{
+			{Rollback: rollback{Action: rollbackActionRemoveConfig, Args: []string{"osd", "osd_recovery_sleep"}}},
+		},
+	}))
+
+	// the changes of the running game are left to the game to revert
+	s.Require().NoError(writeFileAtomic(filepath.Join(dir, "chaos-monkey-789.checkpoint.json"), checkpoint{
+		Version:  checkpointVersion,
+		SavedAt:  time.Now(),
+		FSID:     testFSID,
+		PoolName: "chaos-monkey-789",
+		Rollbacks: []pendingRollback{
+			{Rollback: rollback{Action: rollbackActionRemoveConfig, Args: []string{"osd", "osd_scrub_sleep"}}},
+		},
+	}))
+
+	s.cluster.On("GetPools").Return([]ceph.Pool{
+		{PoolName: ".mgr"},
+		{PoolName: "chaos-monkey-123"},
+		{PoolName: "chaos-monkey-789"},
+		{PoolName: "renamed", ApplicationMetadata: map[string]map[string]string{"chaos-monkey": {}}},
+		{PoolName: "rbd", ApplicationMetadata: map[string]map[string]string{"rbd": {}}},
+	}, nil).Once()
+	s.cluster.On("ListCrushRules").Return([]string{"replicated_rule", "chaos-monkey-rule"}, nil).Once()
+	s.cluster.On("GetFSID").Return(testFSID, nil).Once()
+	s.cluster.On("DumpConfig").Return([]ceph.ConfigOption{
+		{Section: "osd", Name: "osd_max_backfills", Value: "16"},
+		{Section: "osd", Name: "osd_recovery_sleep", Value: "10"},
+		{Section: "osd", Name: "osd_scrub_sleep", Value: "10"},
+		{Section: "osd", Mask: "host:ceph01", Name: "osd_memory_target", Value: "1073741824"},
+		{Section: "global", Name: "public_network", Value: "10.0.0.0/24"},
+	}, nil).Once()
+	s.cluster.On("ListAuth").Return([]ceph.AuthEntity{
+		{Entity: "client.admin"},
+		{Entity: "client.chaos-monkey-123"},
+		{Entity: "client.chaos-monkey-789"},
+	}, nil).Once()
+	s.cluster.On("ListBlocklist").Return([]ceph.BlocklistEntry{
+		{Addr: "10.0.0.1:0/3710147553"},
+		{Addr: "10.0.0.2:0/3710147553"},
+	}, nil).Once()
+
+	c := &cleaner{
+		cluster:       s.cluster,
+		ledgerDir:     dir,
+		checkpointDir: dir,
+		overrides:     []string{"osd/host:ceph01/osd_memory_target"},
+	}
+	artifacts, running, err := c.find(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"chaos-monkey-789"}, running)
+	s.Require().Equal([]artifact{
+		{kind: artifactKindPool, name: "chaos-monkey-123"},
+		{kind: artifactKindPool, name: "renamed"},
+		{kind: artifactKindCrushRule, name: "chaos-monkey-rule"},
+		{kind: artifactKindConfigOverride, name: "osd_max_backfills", who: "osd", revert: &rollback{
+			Action: rollbackActionSetConfig,
+			Args:   []string{"osd", "osd_max_backfills", "1"},
+		}},
+		{kind: artifactKindConfigOverride, name: "osd_memory_target", who: "osd/host:ceph01"},
+		{kind: artifactKindClientKey, name: "client.chaos-monkey-123"},
+		{kind: artifactKindBlocklistEntry, name: "10.0.0.1:0/3710147553"},
+		{kind: artifactKindLedger, name: ledgerPath},
+		{kind: artifactKindCheckpoint, name: checkpointPath},
+	}, artifacts)
+}
+
+func (s *cephTestSuite) TestCleanerFindMalformedOverride() {
+	s.cluster.On("GetPools").Return([]ceph.Pool{}, nil).Once()
+	s.cluster.On("ListCrushRules").Return([]string{}, nil).Once()
+
+	c := &cleaner{cluster: s.cluster, overrides: []string{"osd_max_backfills"}}
+	_, _, err := c.find(s.ctx)
+	s.Require().EqualError(err, "config override must be in the form of <who>/<name>, got `osd_max_backfills`")
+}
+
+func (s *cephTestSuite) TestCleanerRefusedByPolicy() {
+	s.cluster.On("GetFSID").Return("6e7c2b4f-0c3f-11f0-9d3c-525400a1b2c3", nil).Once()
+
+	printer := &bufferPrinter{}
+	c := NewCleaner(s.cluster, printer, confirmerFunc(func(string) bool { return true }),
+		SafetyPolicy{AllowedFSIDs: []string{testFSID}}, "", "", nil)
+	s.Require().NoError(c.Run(s.ctx))
+	s.Require().Equal("Refusing to clean up the cluster: cluster 6e7c2b4f-0c3f-11f0-9d3c-525400a1b2c3 is not in the allowlist\n", printer.String())
+}
+
+func (s *cephTestSuite) TestCleanerRemove() {
+	dir := s.T().TempDir()
+	ledgerPath := filepath.Join(dir, "chaos-monkey-123.ledger.jsonl")
+	s.Require().NoError(os.WriteFile(ledgerPath, nil, 0o600))
+	checkpointPath := filepath.Join(dir, "chaos-monkey-123.checkpoint.json")
+	s.Require().NoError(os.WriteFile(checkpointPath, nil, 0o600))
+
+	s.cluster.On("GetConfig", "mon", "mon_allow_pool_delete").Return("false", nil).Once()
+	s.cluster.On("DumpConfig").Return([]ceph.ConfigOption{}, nil).Once()
+	s.cluster.On("SetConfig", "mon", "mon_allow_pool_delete", "true").Return(nil).Once()
+	s.cluster.On("DeletePool", "chaos-monkey-123").Return(nil).Once()
+	s.cluster.On("RemoveCrushRule", "chaos-monkey-rule").Return(nil).Once()
+	s.cluster.On("SetConfig", "osd", "osd_max_backfills", "1").Return(nil).Once()
+	s.cluster.On("RemoveConfig", "osd/host:ceph01", "osd_memory_target").Return(nil).Once()
+	s.cluster.On("DeleteAuth", "client.chaos-monkey-123").Return(nil).Once()
+	s.cluster.On("BlocklistRemove", "10.0.0.1:0/3710147553").Return(nil).Once()
+	s.cluster.On("RemoveConfig", "mon", "mon_allow_pool_delete").Return(nil).Once()
+
+	c := &cleaner{cluster: s.cluster, ledgerDir: dir}
+	err := c.remove(s.ctx, []artifact{
+		{kind: artifactKindPool, name: "chaos-monkey-123"},
+		{kind: artifactKindCrushRule, name: "chaos-monkey-rule"},
+		{kind: artifactKindConfigOverride, name: "osd_max_backfills", who: "osd", revert: &rollback{
+			Action: rollbackActionSetConfig,
+			Args:   []string{"osd", "osd_max_backfills", "1"},
+		}},
+		{kind: artifactKindConfigOverride, name: "osd_memory_target", who: "osd/host:ceph01"},
+		{kind: artifactKindClientKey, name: "client.chaos-monkey-123"},
+		{kind: artifactKindBlocklistEntry, name: "10.0.0.1:0/3710147553"},
+		{kind: artifactKindLedger, name: ledgerPath},
+		{kind: artifactKindCheckpoint, name: checkpointPath},
+	})
+	s.Require().NoError(err)
+	s.Require().NoFileExists(ledgerPath)
+	s.Require().NoFileExists(checkpointPath)
+}
+
+func (s *cephTestSuite) TestCleanerRemoveFailedKeepsCheckpoint() {
+	checkpointPath := filepath.Join(s.T().TempDir(), "chaos-monkey-123.checkpoint.json")
+	s.Require().NoError(os.WriteFile(checkpointPath, nil, 0o600))
+
+	s.cluster.On("DeleteAuth", "client.chaos-monkey-123").Return(errors.New("test error")).Once()
+
+	c := &cleaner{cluster: s.cluster}
+	err := c.remove(s.ctx, []artifact{
+		{kind: artifactKindClientKey, name: "client.chaos-monkey-123"},
+		{kind: artifactKindCheckpoint, name: checkpointPath},
+	})
+	s.Require().EqualError(err, "error removing client key client.chaos-monkey-123: test error")
+	s.Require().FileExists(checkpointPath)
+}
+
+func (s *cephTestSuite) TestCleanerAllowPoolDeleteRestoresOverride() {
+	s.cluster.On("GetConfig", "mon", "mon_allow_pool_delete").Return("false", nil).Once()
+	s.cluster.On("DumpConfig").Return([]ceph.ConfigOption{
+		{Section: "mon", Name: "mon_allow_pool_delete", Value: "false"},
+	}, nil).Once()
+	s.cluster.On("SetConfig", "mon", "mon_allow_pool_delete", "true").Return(nil).Once()
+
+	c := &cleaner{cluster: s.cluster}
+	restore, err := c.allowPoolDelete(s.ctx)
+	s.Require().NoError(err)
+
+	s.cluster.On("SetConfig", "mon", "mon_allow_pool_delete", "false").Return(nil).Once()
+	restore()
+}
+
+func (s *cephTestSuite) TestCleanerAllowPoolDeleteAlreadyAllowed() {
+	s.cluster.On("GetConfig", "mon", "mon_allow_pool_delete").Return("true\n", nil).Once()
+
+	c := &cleaner{cluster: s.cluster}
+	restore, err := c.allowPoolDelete(s.ctx)
+	s.Require().NoError(err)
+	restore()
+}
@@ -0,0 +1,109 @@
+package monkey
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+// Confirmer asks the operator to confirm the destructive actions
+type Confirmer interface {
+	Confirm(ctx context.Context, prompt string) bool
+}
+
+type promptConfirmer struct {
+	reader *bufio.Reader
+	writer io.Writer
+}
+
+// NewStdinConfirmer asks the questions on stdin and waits for y/n answers
+func NewStdinConfirmer() Confirmer {
+	return newPromptConfirmer(os.Stdin, os.Stdout)
+}
+
+func newPromptConfirmer(r io.Reader, w io.Writer) Confirmer {
+	return &promptConfirmer{
+		reader: bufio.NewReader(r),
+		writer: w,
+	}
+}
+
+func (c *promptConfirmer) Confirm(ctx context.Context, prompt string) bool {
+	for {
+		_, _ = fmt.Fprintf(c.writer, "%s [y/n]: ", prompt)
+
+		response, err := c.readLine(ctx)
+		if err != nil {
+			return false
+		}
+
+		response = strings.ToLower(strings.TrimSpace(response))
+
+		switch response {
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+	}
+}
+
+// readLine waits for the answer until the context is canceled, e.g. by the
+// signal
+func (c *promptConfirmer) readLine(ctx context.Context) (string, error) {
+	type answer struct {
+		line string
+		err  error
+	}
+
+	ch := make(chan answer, 1)
+	go func() {
+		line, err := c.reader.ReadString('\n')
+		ch <- answer{line: line, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case a := <-ch:
+		return a.line, a.err
+	}
+}
+
+type fsidConfirmer struct {
+	cluster drivers.Cluster
+	fsid    string
+}
+
+// NewFSIDConfirmer confirms everything without asking only if the cluster
+// fsid matches the one the operator acknowledged to be destroyed, so the
+// unattended game couldn't be pointed to the wrong cluster
+func NewFSIDConfirmer(cluster drivers.Cluster, fsid string) Confirmer {
+	return &fsidConfirmer{
+		cluster: cluster,
+		fsid:    strings.ToLower(strings.TrimSpace(fsid)),
+	}
+}
+
+func (c *fsidConfirmer) Confirm(ctx context.Context, prompt string) bool {
+	fsid, err := c.cluster.GetFSID(ctx)
+	if err != nil {
+		log.Warnf("error getting cluster fsid: %s", err)
+		return false
+	}
+
+	if strings.ToLower(fsid) != c.fsid {
+		log.Warnf("cluster fsid %s doesn't match the acknowledged one %s", fsid, c.fsid)
+		return false
+	}
+
+	log.Debugf("%s: confirmed for cluster %s", prompt, fsid)
+	return true
+}
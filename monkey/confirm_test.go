@@ -0,0 +1,39 @@
+package monkey
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+)
+
+type confirmerFunc func(prompt string) bool
+
+func (f confirmerFunc) Confirm(_ context.Context, prompt string) bool {
+	return f(prompt)
+}
+
+func (s *cephTestSuite) TestPromptConfirmer() {
+	out := &bytes.Buffer{}
+	c := newPromptConfirmer(strings.NewReader("maybe\nYes\nn\n"), out)
+
+	s.Require().True(c.Confirm(s.ctx, "Sure?"))
+	s.Require().False(c.Confirm(s.ctx, "Sure?"))
+	s.Require().Equal("Sure? [y/n]: Sure? [y/n]: Sure? [y/n]: ", out.String())
+
+	// no answer at all
+	s.Require().False(c.Confirm(s.ctx, "Sure?"))
+}
+
+func (s *cephTestSuite) TestFSIDConfirmer() {
+	c := NewFSIDConfirmer(s.cluster, " 5D6B1A3E-0C3F-11F0-9D3C-525400A1B2C3\n")
+
+	s.cluster.On("GetFSID").Return(testFSID, nil).Once()
+	s.Require().True(c.Confirm(s.ctx, "Sure?"))
+
+	s.cluster.On("GetFSID").Return("11111111-2222-3333-4444-555555555555", nil).Once()
+	s.Require().False(c.Confirm(s.ctx, "Sure?"))
+
+	s.cluster.On("GetFSID").Return("", errors.New("timed out")).Once()
+	s.Require().False(c.Confirm(s.ctx, "Sure?"))
+}
@@ -0,0 +1,63 @@
+package monkey
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+)
+
+// consoleCommand is the command typed by the trainee during the game
+type consoleCommand struct {
+	name string
+	args []string
+}
+
+// WithConsole reads the trainee commands from r during the game, one per line
+func WithConsole(r io.Reader) Option {
+	return func(m *monkey) {
+		m.console = r
+	}
+}
+
+// readConsole parses the commands from r until EOF. The channel is closed
+// once r is over.
+func readConsole(r io.Reader) <-chan consoleCommand {
+	ch := make(chan consoleCommand)
+
+	go func() {
+		defer close(ch)
+
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) == 0 {
+				continue
+			}
+
+			ch <- consoleCommand{
+				name: strings.ToLower(fields[0]),
+				args: fields[1:],
+			}
+		}
+	}()
+
+	return ch
+}
+
+// handleCommand is called from the game loop so it's safe to change the game
+// state here
+func (m *monkey) handleCommand(ctx context.Context, cmd consoleCommand) {
+	switch cmd.name {
+	case "hint", "h":
+		m.showHints(ctx)
+	case "diagnose", "d":
+		m.diagnose(cmd.args)
+	case "incidents":
+		m.printIncidents()
+	case "fusses":
+		m.printFusses()
+	default:
+		m.printer.Printf("Unknown command `%s`, available: hint, diagnose, incidents, fusses\n", cmd.name)
+	}
+}
@@ -0,0 +1,266 @@
+package monkey
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// controlCommand is the command sent by the instructor over the control
+// socket. The reply is printed to out and done is closed once the command is
+// handled.
+type controlCommand struct {
+	consoleCommand
+
+	out  Printer
+	done chan struct{}
+}
+
+// WithControlSocket listens for the instructor commands on the UNIX socket
+// during the game so the game could be adapted to the trainee
+func WithControlSocket(path string) Option {
+	return func(m *monkey) {
+		m.controlSocket = path
+	}
+}
+
+// listenControl accepts the instructor connections until ctx is done. Every
+// line of the connection is the command.
+func listenControl(ctx context.Context, path string) (<-chan controlCommand, error) {
+	// the socket could be left by the process died
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		_ = os.Remove(path)
+	}
+
+	l, err := (&net.ListenConfig{}).Listen(ctx, "unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = l.Close()
+		return nil, err
+	}
+
+	ch := make(chan controlCommand)
+
+	context.AfterFunc(ctx, func() { _ = l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveControl(ctx, conn, ch)
+		}
+	}()
+
+	return ch, nil
+}
+
+func serveControl(ctx context.Context, conn net.Conn, ch chan<- controlCommand) {
+	defer func() { _ = conn.Close() }()
+
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	out := newWriterPrinter(conn)
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		cmd := controlCommand{
+			consoleCommand: consoleCommand{
+				name: strings.ToLower(fields[0]),
+				args: fields[1:],
+			},
+			out:  out,
+			done: make(chan struct{}),
+		}
+
+		select {
+		case ch <- cmd:
+		case <-ctx.Done():
+			return
+		}
+
+		select {
+		case <-cmd.done:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// SendControl sends the command to the game listening on the control socket
+// and copies the reply to w
+func SendControl(ctx context.Context, path string, args []string, w io.Writer) error {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "unix", path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := fmt.Fprintln(conn, strings.Join(args, " ")); err != nil {
+		return err
+	}
+
+	// the game closes the connection once the command is handled
+	if err := conn.(*net.UnixConn).CloseWrite(); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, conn)
+	return err
+}
+
+// gameElapsed is the game time passed excluding the pauses
+func (m *monkey) gameElapsed() time.Duration {
+	if m.paused {
+		return m.pausedAt.Sub(m.startedAt)
+	}
+	return time.Since(m.startedAt)
+}
+
+func (m *monkey) timeLeft() time.Duration {
+	return max(m.duration-m.gameElapsed(), 0)
+}
+
+// handleControl is called from the game loop so it's safe to change the game
+// state here. The trainee is told about the changes of the game pacing but not
+// about the fusses triggered.
+func (m *monkey) handleControl(ctx context.Context, cmd controlCommand) {
+	defer close(cmd.done)
+
+	switch cmd.name {
+	case "pause":
+		if m.paused {
+			cmd.out.Println("The game is already paused")
+			return
+		}
+
+		m.paused = true
+		m.pausedAt = time.Now()
+		m.gameTimer.Stop()
+
+		m.announce(cmd.out, "The game is paused by the instructor", "the game is paused by the instructor")
+	case "resume":
+		if !m.paused {
+			cmd.out.Println("The game is not paused")
+			return
+		}
+
+		// the clock is frozen while paused so the game time and the scenario
+		// steps are shifted by the pause
+		pause := time.Since(m.pausedAt)
+		m.startedAt = m.startedAt.Add(pause)
+		if m.scenario != nil {
+			m.scenario.due = m.scenario.due.Add(pause)
+		}
+		m.paused = false
+		m.gameTimer.Reset(m.timeLeft())
+
+		m.announce(cmd.out,
+			fmt.Sprintf("The game is resumed by the instructor, %s left", m.timeLeft().Round(time.Second)),
+			fmt.Sprintf("the game is resumed by the instructor after %s pause", pause.Round(time.Second)),
+		)
+	case "extend", "shorten":
+		if len(cmd.args) != 1 {
+			cmd.out.Printf("Usage: %s <duration>\n", cmd.name)
+			return
+		}
+
+		d, err := time.ParseDuration(cmd.args[0])
+		if err != nil || d <= 0 {
+			cmd.out.Printf("Invalid duration `%s`, example: 5m\n", cmd.args[0])
+			return
+		}
+
+		if cmd.name == "extend" {
+			m.duration += d
+		} else {
+			m.duration = max(m.duration-d, m.gameElapsed())
+		}
+
+		if !m.paused {
+			m.gameTimer.Reset(m.timeLeft())
+		}
+
+		m.announce(cmd.out,
+			fmt.Sprintf("The game is %sed by the instructor, %s left", cmd.name, m.timeLeft().Round(time.Second)),
+			fmt.Sprintf("the game is %sed by %s by the instructor", cmd.name, d),
+		)
+	case "skip":
+		m.skipNext = true
+
+		cmd.out.Println("The next fuss is going to be skipped")
+	case "trigger":
+		if len(cmd.args) != 1 {
+			cmd.out.Println("Usage: trigger <fuss-id>")
+			return
+		}
+
+		f, ok := fussByID(cmd.args[0])
+		if !ok {
+			cmd.out.Printf("Unknown fuss `%s`\n", cmd.args[0])
+			return
+		}
+
+		if !m.policy.allows(f) {
+			cmd.out.Printf("Fuss `%s` is not allowed by the safety policy\n", f.id)
+			return
+		}
+
+		if !(capabilities{}).has(ctx, m.cluster, f.requires) {
+			cmd.out.Printf("Fuss `%s` is not applicable to the cluster\n", f.id)
+			return
+		}
+
+		m.journal = append(m.journal, JournalEntry{
+			Timestamp: time.Now(),
+			Entry:     fmt.Sprintf("fuss %s triggered by the instructor", f.id),
+		})
+
+		m.printer.Println("Running something dangerous in the cluster ...")
+		if err := m.runFuss(ctx, f); err != nil {
+			cmd.out.Printf("Fuss `%s` failed: %s\n", f.id, err)
+			return
+		}
+		cmd.out.Printf("Fuss `%s` is triggered\n", f.id)
+	case "status":
+		state := "running"
+		if m.paused {
+			state = "paused"
+		}
+
+		cmd.out.Printf("The game is %s: %s played, %s left\n", state, m.gameElapsed().Round(time.Second), m.timeLeft().Round(time.Second))
+		cmd.out.Printf("Pending rollbacks: %d\n", len(m.rollbacks))
+		if m.skipNext {
+			cmd.out.Println("The next fuss is going to be skipped")
+		}
+	default:
+		cmd.out.Printf("Unknown command `%s`, available: pause, resume, extend, shorten, skip, trigger, status\n", cmd.name)
+	}
+}
+
+// announce tells both the instructor and the trainee about the change and
+// records it to the journal
+func (m *monkey) announce(out Printer, message, entry string) {
+	out.Println(message)
+	m.printer.Println(message)
+
+	m.journal = append(m.journal, JournalEntry{
+		Timestamp: time.Now(),
+		Entry:     entry,
+	})
+}
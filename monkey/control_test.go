@@ -0,0 +1,144 @@
+package monkey
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+)
+
+func (s *cephTestSuite) control(m *monkey, line string) string {
+	fields := strings.Fields(line)
+	out := &bufferPrinter{}
+
+	m.handleControl(s.ctx, controlCommand{
+		consoleCommand: consoleCommand{name: fields[0], args: fields[1:]},
+		out:            out,
+		done:           make(chan struct{}),
+	})
+
+	return out.String()
+}
+
+func (s *cephTestSuite) TestHandleControl() {
+	m := s.newTestMonkey()
+	m.printer = &bufferPrinter{}
+	m.policy = SafetyPolicy{AllowedFusses: []string{"reweight-by-utilization"}}
+	m.duration = 10 * time.Minute
+	m.startedAt = time.Now().Add(-time.Minute)
+	m.gameTimer = time.NewTimer(m.timeLeft())
+	defer m.gameTimer.Stop()
+
+	s.Require().Equal("The game is not paused\n", s.control(m, "resume"))
+
+	s.Require().Equal("The game is paused by the instructor\n", s.control(m, "pause"))
+	s.Require().True(m.paused)
+	s.Require().Equal("The game is already paused\n", s.control(m, "pause"))
+
+	// the clock is frozen while paused
+	elapsed := m.gameElapsed()
+	time.Sleep(10 * time.Millisecond)
+	s.Require().Equal(elapsed, m.gameElapsed())
+	s.Require().Contains(s.control(m, "status"), "The game is paused: 1m0s played, 9m0s left")
+
+	s.Require().Equal("The game is extended by the instructor, 14m0s left\n", s.control(m, "extend 5m"))
+	s.Require().Equal(15*time.Minute, m.duration)
+
+	s.Require().Equal("Invalid duration `soon`, example: 5m\n", s.control(m, "shorten soon"))
+	s.Require().Equal("Usage: shorten <duration>\n", s.control(m, "shorten"))
+
+	// the game couldn't be shortened to the past
+	s.Require().Equal("The game is shortened by the instructor, 0s left\n", s.control(m, "shorten 1h"))
+	s.Require().Equal(elapsed, m.duration)
+
+	s.Require().Equal("The game is resumed by the instructor, 0s left\n", s.control(m, "resume"))
+	s.Require().False(m.paused)
+	s.Require().InDelta(elapsed, m.gameElapsed(), float64(time.Second))
+
+	s.Require().Equal("The next fuss is going to be skipped\n", s.control(m, "skip"))
+	s.Require().True(m.skipNext)
+
+	s.Require().Equal("Unknown fuss `format-disks`\n", s.control(m, "trigger format-disks"))
+	s.Require().Equal("Fuss `set-flag` is not allowed by the safety policy\n", s.control(m, "trigger set-flag"))
+
+	s.cluster.On("ReweightByUtilization").Return(nil).Once()
+	s.Require().Equal("Fuss `reweight-by-utilization` is triggered\n", s.control(m, "trigger reweight-by-utilization"))
+
+	s.Require().Equal(
+		"Unknown command `explode`, available: pause, resume, extend, shorten, skip, trigger, status\n",
+		s.control(m, "explode"),
+	)
+
+	entries := []string{}
+	for _, j := range m.journal {
+		entries = append(entries, j.Entry)
+	}
+	s.Require().Equal([]string{
+		"the game is paused by the instructor",
+		"the game is extended by 5m0s by the instructor",
+		"the game is shortened by 1h0m0s by the instructor",
+		"the game is resumed by the instructor after 0s pause",
+		"fuss reweight-by-utilization triggered by the instructor",
+		"run reweight-by-utilization",
+	}, entries)
+
+	// the trainee is not told which fuss is triggered
+	s.Require().NotContains(m.printer.(*bufferPrinter).String(), "reweight")
+}
+
+func (s *cephTestSuite) TestControlSocket() {
+	path := filepath.Join(s.T().TempDir(), "control.sock")
+
+	ch, err := listenControl(s.ctx, path)
+	s.Require().NoError(err)
+
+	go func() {
+		cmd := <-ch
+		cmd.out.Printf("%s %s\n", cmd.name, strings.Join(cmd.args, ","))
+		close(cmd.done)
+	}()
+
+	out := &strings.Builder{}
+	s.Require().NoError(SendControl(s.ctx, path, []string{"EXTEND", "5m"}, out))
+	s.Require().Equal("extend 5m\n", out.String())
+}
+
+func (s *cephTestSuite) TestRunPausedByInstructor() {
+	profile := DefaultWorkloadProfile()
+	profile.Mix = OpsMix{Read: 1}
+	profile.Concurrency = 1
+
+	path := filepath.Join(s.T().TempDir(), "control.sock")
+
+	m := s.newRunMonkey(
+		200*time.Millisecond,
+		WithConfirmer(confirmerFunc(func(string) bool { return true })),
+		WithWorkloadProfile(profile),
+		WithControlSocket(path),
+	)
+	s.expectGame()
+	s.cluster.On("GetOSDs").Return([]ceph.OSD{{ID: 0}}, nil).Maybe()
+
+	done := make(chan error)
+	go func() {
+		done <- m.Run(s.ctx)
+	}()
+
+	s.Require().Eventually(func() bool {
+		return SendControl(s.ctx, path, []string{"pause"}, &strings.Builder{}) == nil
+	}, time.Second, 10*time.Millisecond)
+
+	select {
+	case <-done:
+		s.FailNow("the game is over while paused")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	s.Require().NoError(SendControl(s.ctx, path, []string{"resume"}, &strings.Builder{}))
+	s.Require().NoError(<-done)
+
+	report := s.readReport(m)
+	s.Require().Contains(report, "the game is paused by the instructor")
+	s.Require().Contains(report, "the game is resumed by the instructor")
+}
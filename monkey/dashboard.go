@@ -0,0 +1,357 @@
+package monkey
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// the dashboard is drawn on the alternate screen of the terminal so the
+	// terminal content is restored once it's closed
+	ansiEnterAltScreen = "\x1b[?1049h\x1b[?25l"
+	ansiLeaveAltScreen = "\x1b[?25h\x1b[?1049l"
+	ansiHome           = "\x1b[H"
+	ansiClearLine      = "\x1b[K"
+	ansiClearBelow     = "\x1b[J"
+
+	maxDashboardChecks = 5
+	maxDashboardStates = 4
+)
+
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// ioSample is the background IO observed between two dashboard updates
+type ioSample struct {
+	opsRate    float64
+	latency    time.Duration
+	errorsRate float64
+}
+
+// Dashboard is the full screen terminal UI showing the live state of the game
+// above the tail of the game output
+type Dashboard struct {
+	mutex sync.Mutex
+	w     io.Writer
+
+	width  int
+	height int
+
+	state   GameState
+	prev    *GameState
+	samples []ioSample
+
+	lines   []string
+	partial string
+	started bool
+	closed  bool
+}
+
+// NewDashboard creates the dashboard drawn on the terminal of the size given
+// written with w
+func NewDashboard(w io.Writer, width, height int) *Dashboard {
+	return &Dashboard{
+		w:      w,
+		width:  width,
+		height: height,
+	}
+}
+
+func (d *Dashboard) Println(a ...any) {
+	d.print(fmt.Sprintln(a...))
+}
+
+func (d *Dashboard) Printf(format string, a ...any) {
+	d.print(fmt.Sprintf(format, a...))
+}
+
+// print keeps the tail of the game output to be drawn below the state or
+// writes it as is before the game is started and once the dashboard is closed
+// so the questions and the report are printed as usual
+func (d *Dashboard) print(s string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if !d.started || d.closed {
+		_, _ = io.WriteString(d.w, s)
+		return
+	}
+
+	parts := strings.Split(d.partial+s, "\n")
+	d.partial = parts[len(parts)-1]
+	d.appendLines(parts[:len(parts)-1]...)
+}
+
+func (d *Dashboard) appendLines(lines ...string) {
+	d.lines = append(d.lines, lines...)
+	if len(d.lines) > d.height {
+		d.lines = d.lines[len(d.lines)-d.height:]
+	}
+
+	d.render()
+}
+
+// LogWriter returns the writer for the logs to be shown in the game output
+// while the dashboard is drawn since anything written to the terminal besides
+// it would break the screen. The logs are written to w as is before the game
+// is started and once the dashboard is closed.
+func (d *Dashboard) LogWriter(w io.Writer) io.Writer {
+	return &dashboardLogWriter{d: d, w: w}
+}
+
+type dashboardLogWriter struct {
+	d *Dashboard
+	w io.Writer
+}
+
+func (l *dashboardLogWriter) Write(p []byte) (int, error) {
+	l.d.mutex.Lock()
+	defer l.d.mutex.Unlock()
+
+	if !l.d.started || l.d.closed {
+		return l.w.Write(p)
+	}
+
+	l.d.appendLines(strings.Split(strings.TrimRight(string(p), "\n"), "\n")...)
+	return len(p), nil
+}
+
+func (d *Dashboard) Update(s GameState) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.closed {
+		return
+	}
+
+	if d.prev != nil {
+		d.samples = append(d.samples, newIOSample(*d.prev, s))
+		if n := d.graphWidth(); len(d.samples) > n {
+			d.samples = d.samples[len(d.samples)-n:]
+		}
+	}
+	d.prev = &s
+	d.state = s
+
+	if !d.started {
+		d.started = true
+		_, _ = io.WriteString(d.w, ansiEnterAltScreen)
+	}
+
+	d.render()
+}
+
+// Close restores the terminal, the game output is printed as is afterwards
+func (d *Dashboard) Close() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.closed {
+		return
+	}
+	d.closed = true
+
+	if d.started {
+		_, _ = io.WriteString(d.w, ansiLeaveAltScreen)
+	}
+
+	if d.partial != "" {
+		_, _ = io.WriteString(d.w, d.partial)
+	}
+}
+
+func newIOSample(prev, cur GameState) ioSample {
+	seconds := cur.At.Sub(prev.At).Seconds()
+	if seconds <= 0 {
+		return ioSample{}
+	}
+
+	p, c := prev.Stats, cur.Stats
+
+	ops := (c.WritesCountTotal - p.WritesCountTotal) + (c.ReadsCountTotal - p.ReadsCountTotal)
+	errs := (c.WritesErrorsTotal - p.WritesErrorsTotal) + (c.ReadsErrorsTotal - p.ReadsErrorsTotal)
+
+	s := ioSample{
+		opsRate:    float64(ops) / seconds,
+		errorsRate: float64(errs) / seconds,
+	}
+
+	// the latency of the interval is derived from the averages since the
+	// game start
+	if ops > 0 {
+		total := c.AvgWritesLatency*time.Duration(c.WritesCountTotal) + c.AvgReadsLatency*time.Duration(c.ReadsCountTotal) -
+			p.AvgWritesLatency*time.Duration(p.WritesCountTotal) - p.AvgReadsLatency*time.Duration(p.ReadsCountTotal)
+		s.latency = max(total/time.Duration(ops), 0)
+	}
+
+	return s
+}
+
+func (d *Dashboard) graphWidth() int {
+	return max(d.width-30, 10)
+}
+
+func (d *Dashboard) render() {
+	lines := d.stateLines()
+
+	lines = append(lines, strings.Repeat("─", d.width))
+
+	// the rest of the screen is the tail of the game output
+	if n := d.height - len(lines); n > 0 {
+		tail := d.lines
+		if len(tail) > n {
+			tail = tail[len(tail)-n:]
+		}
+		lines = append(lines, tail...)
+	}
+
+	if len(lines) > d.height {
+		lines = lines[:d.height]
+	}
+
+	b := &strings.Builder{}
+	b.WriteString(ansiHome)
+	for i, l := range lines {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(truncateRunes(l, d.width))
+		b.WriteString(ansiClearLine)
+	}
+	b.WriteString(ansiClearBelow)
+
+	_, _ = io.WriteString(d.w, b.String())
+}
+
+func (d *Dashboard) stateLines() []string {
+	s := d.state
+
+	title := "ceph-chaos-monkey"
+	if s.Level != "" {
+		title += fmt.Sprintf(" (%s)", s.Level)
+	}
+	if s.Paused {
+		title += "  [PAUSED]"
+	}
+
+	next := s.NextIn.Round(time.Second).String()
+	if s.Paused {
+		next = "paused"
+	}
+
+	lines := []string{
+		title,
+		fmt.Sprintf("Time left: %s  Played: %s  Next fuss in: %s  Pending rollbacks: %d",
+			s.Left.Round(time.Second), s.Played.Round(time.Second), next, s.Pending),
+		"",
+	}
+
+	lines = append(lines, clusterLines(s.Cluster, s.At)...)
+	lines = append(lines, "")
+
+	graph := func(v func(ioSample) float64) string {
+		values := make([]float64, 0, len(d.samples))
+		for _, smp := range d.samples {
+			values = append(values, v(smp))
+		}
+		return sparkline(values)
+	}
+
+	var last ioSample
+	if len(d.samples) > 0 {
+		last = d.samples[len(d.samples)-1]
+	}
+
+	lines = append(lines,
+		fmt.Sprintf("IO rate  %8.1f/s  %s", last.opsRate, graph(func(s ioSample) float64 { return s.opsRate })),
+		fmt.Sprintf("Latency  %8.3fs  %s", last.latency.Seconds(), graph(func(s ioSample) float64 { return s.latency.Seconds() })),
+		fmt.Sprintf("Errors   %8.1f/s  %s", last.errorsRate, graph(func(s ioSample) float64 { return s.errorsRate })),
+		fmt.Sprintf("Writes succeeded: %.2f%%  Reads succeeded: %.2f%%  Stalls: %d (%s)  Lost writes: %d",
+			s.Stats.WritesSuccessPercent*100, s.Stats.ReadsSuccessPercent*100,
+			s.Stats.StallsCountTotal, s.Stats.StallsDurationTotal.Round(time.Second), s.Stats.LostWritesTotal),
+	)
+
+	return lines
+}
+
+func clusterLines(c *ClusterState, now time.Time) []string {
+	if c == nil {
+		return []string{"Health: checking ..."}
+	}
+
+	lines := []string{
+		fmt.Sprintf("Health: %s (updated %s ago)", c.Health, now.Sub(c.UpdatedAt).Round(time.Second)),
+	}
+
+	for i, check := range c.Checks {
+		if i == maxDashboardChecks {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(c.Checks)-i))
+			break
+		}
+		lines = append(lines, "  "+check)
+	}
+
+	// the most common PG states first
+	states := make([]string, 0, len(c.PGStates))
+	for st := range c.PGStates {
+		states = append(states, st)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if c.PGStates[states[i]] != c.PGStates[states[j]] {
+			return c.PGStates[states[i]] > c.PGStates[states[j]]
+		}
+		return states[i] < states[j]
+	})
+
+	summary := []string{}
+	for i, st := range states {
+		if i == maxDashboardStates {
+			other := 0
+			for _, st := range states[i:] {
+				other += c.PGStates[st]
+			}
+			summary = append(summary, fmt.Sprintf("%d other", other))
+			break
+		}
+		summary = append(summary, fmt.Sprintf("%d %s", c.PGStates[st], st))
+	}
+
+	pgs := fmt.Sprintf("PGs: %d", c.PGsTotal)
+	if len(summary) > 0 {
+		pgs += " (" + strings.Join(summary, ", ") + ")"
+	}
+
+	return append(lines,
+		pgs,
+		fmt.Sprintf("OSDs: %d total, %d up, %d in", c.OSDsTotal, c.OSDsUp, c.OSDsIn),
+	)
+}
+
+// sparkline draws the values scaled to the maximum one
+func sparkline(values []float64) string {
+	var top float64
+	for _, v := range values {
+		top = max(top, v)
+	}
+
+	b := &strings.Builder{}
+	for _, v := range values {
+		i := 0
+		if top > 0 {
+			i = int(v / top * float64(len(sparkBars)-1))
+		}
+		b.WriteRune(sparkBars[i])
+	}
+	return b.String()
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
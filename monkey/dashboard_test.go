@@ -0,0 +1,206 @@
+package monkey
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+)
+
+func (s *cephTestSuite) TestSparkline() {
+	s.Require().Equal("", sparkline(nil))
+	s.Require().Equal("▁▁", sparkline([]float64{0, 0}))
+	s.Require().Equal("▁▄█", sparkline([]float64{0, 5, 10}))
+}
+
+func (s *cephTestSuite) TestNewIOSample() {
+	now := time.Now()
+
+	prev := GameState{At: now, Stats: MeasurementValue{
+		WritesCountTotal: 10, AvgWritesLatency: 10 * time.Millisecond,
+		ReadsCountTotal: 10, AvgReadsLatency: 10 * time.Millisecond,
+	}}
+	cur := GameState{At: now.Add(2 * time.Second), Stats: MeasurementValue{
+		WritesCountTotal: 20, WritesErrorsTotal: 2, AvgWritesLatency: 20 * time.Millisecond,
+		ReadsCountTotal: 10, AvgReadsLatency: 10 * time.Millisecond,
+	}}
+
+	s.Require().Equal(ioSample{
+		opsRate:    5,
+		latency:    30 * time.Millisecond,
+		errorsRate: 1,
+	}, newIOSample(prev, cur))
+}
+
+func (s *cephTestSuite) TestDashboard() {
+	out := &strings.Builder{}
+	d := NewDashboard(out, 80, 30)
+
+	// the output is printed as is till the game is started
+	d.Println("Sure???")
+	s.Require().Equal("Sure???\n", out.String())
+	out.Reset()
+
+	now := time.Now()
+	d.Update(GameState{At: now, Level: LevelBeginner, Left: 10 * time.Minute, NextIn: 30 * time.Second})
+	s.Require().True(strings.HasPrefix(out.String(), ansiEnterAltScreen))
+
+	d.Println("Tick! Running something dangerous in the cluster ...")
+
+	d.Update(GameState{
+		At:      now.Add(time.Second),
+		Level:   LevelBeginner,
+		Paused:  true,
+		Played:  time.Minute,
+		Left:    9 * time.Minute,
+		Pending: 1,
+		Stats:   MeasurementValue{WritesCountTotal: 4, WritesErrorsTotal: 1},
+		Cluster: &ClusterState{
+			UpdatedAt: now,
+			Health:    "HEALTH_WARN",
+			Checks:    []string{"OSDMAP_FLAGS: noout flag(s) set"},
+			PGsTotal:  3,
+			PGStates:  map[string]int{"active+clean": 2, "active+undersized+degraded": 1},
+			OSDsTotal: 3,
+			OSDsUp:    3,
+			OSDsIn:    2,
+		},
+	})
+
+	screen := out.String()
+	for _, line := range []string{
+		"ceph-chaos-monkey (beginner)  [PAUSED]",
+		"Time left: 9m0s  Played: 1m0s  Next fuss in: paused  Pending rollbacks: 1",
+		"Health: HEALTH_WARN (updated 1s ago)",
+		"  OSDMAP_FLAGS: noout flag(s) set",
+		"PGs: 3 (2 active+clean, 1 active+undersized+degraded)",
+		"OSDs: 3 total, 3 up, 2 in",
+		"IO rate       4.0/s  █",
+		"Errors        1.0/s  █",
+		"Tick! Running something dangerous in the cluster ...",
+	} {
+		s.Require().Contains(screen, line+ansiClearLine)
+	}
+
+	out.Reset()
+	d.Close()
+	d.Println("Game is over!")
+	s.Require().Equal(ansiLeaveAltScreen+"Game is over!\n", out.String())
+}
+
+func (s *cephTestSuite) TestFetchClusterState() {
+	s.cluster.On("GetHealth").Return(ceph.Health{
+		Status: "HEALTH_WARN",
+		Checks: map[string]ceph.HealthCheck{
+			"PG_DEGRADED":  {Summary: ceph.HealthCheckSummary{Message: "Degraded data redundancy"}},
+			"OSDMAP_FLAGS": {Summary: ceph.HealthCheckSummary{Message: "noout flag(s) set"}},
+		},
+	}, nil).Once()
+	s.cluster.On("ListPGs").Return([]ceph.PGStat{}, errors.New("timed out")).Once()
+	s.cluster.On("GetOSDs").Return([]ceph.OSD{
+		{ID: 0, State: []string{"exists", "up"}, Reweight: 1},
+		{ID: 1, State: []string{"exists", "up"}},
+		{ID: 2, State: []string{"exists"}, Reweight: 1},
+	}, nil).Once()
+
+	state := fetchClusterState(s.ctx, s.cluster)
+	s.Require().Equal("HEALTH_WARN", state.Health)
+	s.Require().Equal([]string{"OSDMAP_FLAGS: noout flag(s) set", "PG_DEGRADED: Degraded data redundancy"}, state.Checks)
+	s.Require().Zero(state.PGsTotal)
+	s.Require().Equal(3, state.OSDsTotal)
+	s.Require().Equal(2, state.OSDsUp)
+	s.Require().Equal(2, state.OSDsIn)
+}
+
+func (s *cephTestSuite) TestNextFussIn() {
+	now := time.Now()
+
+	m := s.newTestMonkey()
+	m.interval = time.Minute
+	m.startedAt = now
+	m.nextTickAt = now.Add(time.Minute)
+	s.Require().Equal(time.Minute, m.nextFussIn(now))
+
+	// the ticks during the warmup are skipped
+	m.level = LevelProfile{Warmup: 90 * time.Second}
+	s.Require().Equal(2*time.Minute, m.nextFussIn(now))
+}
+
+// recordingUI records the states the game has been shown with
+type recordingUI struct {
+	bufferPrinter
+
+	states []GameState
+	closed bool
+}
+
+func (u *recordingUI) Update(s GameState) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	u.states = append(u.states, s)
+}
+
+func (u *recordingUI) Close() {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	u.closed = true
+	_, _ = u.buf.WriteString("closed\n")
+}
+
+func (s *cephTestSuite) TestRunWithUI() {
+	profile := DefaultWorkloadProfile()
+	profile.Mix = OpsMix{Read: 1}
+	profile.Concurrency = 1
+
+	ui := &recordingUI{}
+	m := s.newRunMonkey(
+		1500*time.Millisecond,
+		WithConfirmer(confirmerFunc(func(string) bool { return true })),
+		WithWorkloadProfile(profile),
+		WithUI(ui),
+	)
+	s.expectGame()
+	s.cluster.On("GetHealth").Return(ceph.Health{Status: "HEALTH_OK"}, nil).Maybe()
+	s.cluster.On("ListPGs").Return([]ceph.PGStat{{PGID: "1.0", State: "active+clean"}}, nil).Maybe()
+	s.cluster.On("GetOSDs").Return([]ceph.OSD{{ID: 0, State: []string{"up"}, Reweight: 1}}, nil).Maybe()
+
+	s.Require().NoError(m.Run(s.ctx))
+
+	s.Require().True(ui.closed)
+	s.Require().GreaterOrEqual(len(ui.states), 2)
+	s.Require().Nil(ui.states[0].Cluster)
+	s.Require().InDelta(1500*time.Millisecond, ui.states[0].Played+ui.states[0].Left, float64(time.Millisecond))
+
+	last := ui.states[len(ui.states)-1]
+	s.Require().NotNil(last.Cluster)
+	s.Require().Equal("HEALTH_OK", last.Cluster.Health)
+
+	// the report is printed once the UI is closed
+	out := ui.String()
+	s.Require().Less(strings.Index(out, "closed\n"), strings.Index(out, "Game is over!"))
+}
+
+func (s *cephTestSuite) TestDashboardLogWriter() {
+	out := &strings.Builder{}
+	logs := &strings.Builder{}
+	d := NewDashboard(out, 80, 30)
+	w := d.LogWriter(logs)
+
+	_, err := w.Write([]byte("level=warning msg=\"before the game\"\n"))
+	s.Require().NoError(err)
+	s.Require().Equal("level=warning msg=\"before the game\"\n", logs.String())
+
+	d.Update(GameState{At: time.Now(), Left: 10 * time.Minute})
+	logs.Reset()
+
+	_, err = w.Write([]byte("level=warning msg=\"during the game\"\n"))
+	s.Require().NoError(err)
+	s.Require().Empty(logs.String())
+	s.Require().Contains(out.String(), "level=warning msg=\"during the game\"\x1b[K")
+
+	d.Close()
+	_, err = w.Write([]byte("level=warning msg=\"after the game\"\n"))
+	s.Require().NoError(err)
+	s.Require().Equal("level=warning msg=\"after the game\"\n", logs.String())
+}
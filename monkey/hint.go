@@ -0,0 +1,150 @@
+package monkey
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// hintTimeout bounds the health request since the hint is shown from the game
+// loop
+const hintTimeout = 10 * time.Second
+
+// hint explains the health check and suggests where to look next without
+// telling the exact fix
+type hint struct {
+	explanation string
+	commands    []string
+}
+
+// hints are keyed by the health check codes as reported in `ceph health`
+var hints = map[string]hint{
+	"OSDMAP_FLAGS": {
+		explanation: "One or more cluster-wide flags are set, they could stop IO, recovery, rebalancing or scrubbing.",
+		commands:    []string{"ceph osd dump | grep flags", "ceph health detail"},
+	},
+	"OSD_FLAGS": {
+		explanation: "Flags are set on the specific OSDs, hosts or CRUSH nodes rather than cluster-wide.",
+		commands:    []string{"ceph health detail", "ceph osd tree"},
+	},
+	"OSD_DOWN": {
+		explanation: "Some OSDs are marked down: the daemon is stopped, crashed or can't reach its peers.",
+		commands:    []string{"ceph osd tree down", "ceph orch ps --daemon-type osd", "ceph crash ls-new"},
+	},
+	"OSD_HOST_DOWN": {
+		explanation: "All the OSDs on the host are down, the host or its network is likely the issue.",
+		commands:    []string{"ceph osd tree down", "ceph orch host ls"},
+	},
+	"OSD_NEARFULL": {
+		explanation: "OSDs are above the nearfull ratio. Either they're really filling up or the ratio is too low.",
+		commands:    []string{"ceph osd df", "ceph osd dump | grep ratio"},
+	},
+	"OSD_BACKFILLFULL": {
+		explanation: "OSDs are above the backfillfull ratio so no data is backfilled to them.",
+		commands:    []string{"ceph osd df", "ceph osd dump | grep ratio"},
+	},
+	"OSD_FULL": {
+		explanation: "OSDs are above the full ratio and the cluster refuses writes.",
+		commands:    []string{"ceph osd df", "ceph osd dump | grep ratio"},
+	},
+	"OSD_OUT_OF_ORDER_FULL": {
+		explanation: "The full ratios are not ascending: nearfull < backfillfull < full is expected.",
+		commands:    []string{"ceph osd dump | grep ratio"},
+	},
+	"PG_AVAILABILITY": {
+		explanation: "Some PGs are inactive and the data in them can't be read or written.",
+		commands:    []string{"ceph pg dump_stuck inactive", "ceph pg <pgid> query"},
+	},
+	"PG_DEGRADED": {
+		explanation: "Some objects have fewer copies than the pool size, usually because OSDs are down or out.",
+		commands:    []string{"ceph pg dump_stuck degraded", "ceph osd tree"},
+	},
+	"PG_DAMAGED": {
+		explanation: "Scrubbing found inconsistent PGs: some copies of the objects don't match.",
+		commands:    []string{"ceph health detail", "rados list-inconsistent-obj <pgid>"},
+	},
+	"OSD_SCRUB_ERRORS": {
+		explanation: "Scrubbing found errors in the stored data.",
+		commands:    []string{"ceph health detail", "rados list-inconsistent-pg <pool>"},
+	},
+	"OBJECT_MISPLACED": {
+		explanation: "Objects are not where CRUSH wants them and are being moved. Check what changed the placement.",
+		commands:    []string{"ceph osd tree", "ceph osd df", "ceph balancer status"},
+	},
+	"POOL_TOO_FEW_PGS": {
+		explanation: "The pool has fewer PGs than recommended for the data it stores.",
+		commands:    []string{"ceph osd pool autoscale-status", "ceph osd pool ls detail"},
+	},
+	"POOL_NO_REDUNDANCY": {
+		explanation: "The pool keeps a single copy of the data.",
+		commands:    []string{"ceph osd pool ls detail"},
+	},
+	"MON_DOWN": {
+		explanation: "Some monitors are out of quorum, losing more of them would stop the cluster.",
+		commands:    []string{"ceph mon stat", "ceph quorum_status", "ceph orch ps --daemon-type mon"},
+	},
+	"MDS_ALL_DOWN": {
+		explanation: "No MDS daemon is active so the filesystem is offline.",
+		commands:    []string{"ceph fs status", "ceph fs dump"},
+	},
+	"FS_DEGRADED": {
+		explanation: "The filesystem has fewer active MDS ranks than expected.",
+		commands:    []string{"ceph fs status", "ceph fs dump"},
+	},
+	"SLOW_OPS": {
+		explanation: "Requests are taking too long on some daemons.",
+		commands:    []string{"ceph health detail", "ceph daemon osd.<id> dump_ops_in_flight"},
+	},
+}
+
+// showHints explains the active health checks. Every hint shown costs the
+// score points.
+func (m *monkey) showHints(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, hintTimeout)
+	defer cancel()
+
+	health, err := m.cluster.GetHealth(ctx)
+	if err != nil {
+		log.Debugf("error getting health: %s", err)
+		m.printer.Println("Unable to get the cluster health, try again later")
+		return
+	}
+
+	if len(health.Checks) == 0 {
+		m.printer.Printf("No health checks are active (%s), no hint this time\n", health.Status)
+		return
+	}
+
+	codes := []string{}
+	for code := range health.Checks {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	m.score.hintsUsed++
+
+	m.printer.Printf("Hint (-%d points):\n", hintPenalty)
+	for _, code := range codes {
+		m.printer.Printf("%s: %s\n", code, health.Checks[code].Summary.Message)
+
+		h, ok := hints[code]
+		if !ok {
+			m.printer.Println("  No hint for this one, start with `ceph health detail`")
+			continue
+		}
+
+		m.printer.Printf("  %s\n", h.explanation)
+		for _, c := range h.commands {
+			m.printer.Printf("  $ %s\n", c)
+		}
+	}
+
+	m.journal = append(m.journal, JournalEntry{
+		Timestamp: time.Now(),
+		Entry:     fmt.Sprintf("hint requested for %s", strings.Join(codes, ", ")),
+	})
+}
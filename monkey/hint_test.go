@@ -0,0 +1,54 @@
+package monkey
+
+import (
+	"strings"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+)
+
+func (s *cephTestSuite) TestShowHints() {
+	m := s.newTestMonkey()
+	m.printer = NewPrinter()
+
+	s.cluster.On("GetHealth").Return(ceph.Health{
+		Status: "HEALTH_WARN",
+		Checks: map[string]ceph.HealthCheck{
+			"PG_DEGRADED":  {Summary: ceph.HealthCheckSummary{Message: "Degraded data redundancy"}},
+			"OSDMAP_FLAGS": {Summary: ceph.HealthCheckSummary{Message: "noout flag(s) set"}},
+		},
+	}, nil).Once()
+
+	m.showHints(s.ctx)
+	s.Require().Equal(1, m.score.hintsUsed)
+	s.Require().Equal(90, m.score.points())
+	s.Require().Equal("hint requested for OSDMAP_FLAGS, PG_DEGRADED", m.journal[0].Entry)
+}
+
+func (s *cephTestSuite) TestShowHintsNoChecks() {
+	m := s.newTestMonkey()
+	m.printer = NewPrinter()
+
+	s.cluster.On("GetHealth").Return(ceph.Health{Status: "HEALTH_OK"}, nil).Once()
+
+	m.showHints(s.ctx)
+	s.Require().Equal(0, m.score.hintsUsed)
+	s.Require().Empty(m.journal)
+}
+
+func (s *cephTestSuite) TestScorePoints() {
+	s.Require().Equal(100, score{}.points())
+	s.Require().Equal(70, score{hintsUsed: 3}.points())
+	s.Require().Equal(0, score{hintsUsed: 20}.points())
+}
+
+func (s *cephTestSuite) TestReadConsole() {
+	commands := []consoleCommand{}
+	for cmd := range readConsole(strings.NewReader("HINT\n\n  diagnose a1 set-flag \n")) {
+		commands = append(commands, cmd)
+	}
+
+	s.Require().Equal([]consoleCommand{
+		{name: "hint", args: []string{}},
+		{name: "diagnose", args: []string{"a1", "set-flag"}},
+	}, commands)
+}
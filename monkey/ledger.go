@@ -0,0 +1,257 @@
+package monkey
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/teran/go-collection/random"
+)
+
+type ledgerOp string
+
+const (
+	ledgerOpAck    ledgerOp = "ack"
+	ledgerOpDelete ledgerOp = "delete"
+	ledgerOpLost   ledgerOp = "lost"
+)
+
+// ledgerEntry is the object the background IO got acknowledged for
+type ledgerEntry struct {
+	Object  string    `json:"object"`
+	Pool    string    `json:"pool,omitempty"`
+	Size    uint64    `json:"size,omitempty"`
+	SHA256  string    `json:"sha256,omitempty"`
+	AckedAt time.Time `json:"acked_at,omitzero"`
+
+	// Lost is set once the object is found missing or corrupt during the
+	// game, the entry is kept for the final audit
+	Lost bool `json:"-"`
+}
+
+type ledgerRecord struct {
+	Op ledgerOp `json:"op"`
+	ledgerEntry
+}
+
+// ledger keeps track of the acknowledged writes in the append-only JSON lines
+// file so the readers could sample the objects without listing the pool and
+// the lost writes could be detected
+type ledger struct {
+	mutex *sync.Mutex
+	path  string
+	file  *os.File
+
+	names   []string
+	index   map[string]int
+	entries map[string]ledgerEntry
+	total   uint64
+}
+
+// openLedger opens the ledger file creating it if not exists. The records
+// already present in the file are replayed.
+func openLedger(path string) (*ledger, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &ledger{
+		mutex:   &sync.Mutex{},
+		path:    path,
+		file:    f,
+		index:   map[string]int{},
+		entries: map[string]ledgerEntry{},
+	}
+
+	if err := l.replay(f); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("error reading ledger %s: %w", path, err)
+	}
+
+	return l, nil
+}
+
+func (l *ledger) replay(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		rec := ledgerRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return err
+		}
+
+		switch rec.Op {
+		case ledgerOpAck:
+			l.addEntry(rec.ledgerEntry)
+		case ledgerOpDelete:
+			l.removeEntry(rec.Object)
+		case ledgerOpLost:
+			l.markLostEntry(rec.Object)
+		default:
+			return fmt.Errorf("unknown ledger operation: `%s`", rec.Op)
+		}
+	}
+
+	return scanner.Err()
+}
+
+func (l *ledger) add(e ledgerEntry) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if err := l.write(ledgerRecord{Op: ledgerOpAck, ledgerEntry: e}); err != nil {
+		return err
+	}
+
+	l.addEntry(e)
+	return nil
+}
+
+func (l *ledger) remove(name string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if err := l.write(ledgerRecord{Op: ledgerOpDelete, ledgerEntry: ledgerEntry{Object: name}}); err != nil {
+		return err
+	}
+
+	l.removeEntry(name)
+	return nil
+}
+
+// markLost marks the entry as lost and reports whether it wasn't marked before
+func (l *ledger) markLost(name string) (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	e, ok := l.entries[name]
+	if !ok || e.Lost {
+		return false, nil
+	}
+
+	if err := l.write(ledgerRecord{Op: ledgerOpLost, ledgerEntry: ledgerEntry{Object: name}}); err != nil {
+		return false, err
+	}
+
+	l.markLostEntry(name)
+	return true, nil
+}
+
+// random returns the random entry which is not lost yet
+func (l *ledger) random(rnd random.Random) (ledgerEntry, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if len(l.names) == 0 {
+		return ledgerEntry{}, false
+	}
+
+	return l.entries[l.names[rnd.Intn(len(l.names))]], true
+}
+
+func (l *ledger) footprint() uint64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.total
+}
+
+// list returns all the entries including the lost ones
+func (l *ledger) list() []ledgerEntry {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	entries := []ledgerEntry{}
+	for _, e := range l.entries {
+		entries = append(entries, e)
+	}
+
+	slices.SortFunc(entries, func(a, b ledgerEntry) int {
+		return a.AckedAt.Compare(b.AckedAt)
+	})
+
+	return entries
+}
+
+func (l *ledger) close() error {
+	return l.file.Close()
+}
+
+// destroy closes and removes the ledger file
+func (l *ledger) destroy() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if err := l.file.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+		return err
+	}
+
+	return os.Remove(l.path)
+}
+
+func (l *ledger) write(rec ledgerRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	_, err = l.file.Write(append(data, '\n'))
+	return err
+}
+
+func (l *ledger) addEntry(e ledgerEntry) {
+	if _, ok := l.entries[e.Object]; ok {
+		return
+	}
+
+	l.entries[e.Object] = e
+	l.index[e.Object] = len(l.names)
+	l.names = append(l.names, e.Object)
+	l.total += e.Size
+}
+
+func (l *ledger) removeEntry(name string) {
+	e, ok := l.entries[name]
+	if !ok {
+		return
+	}
+
+	l.unindex(name)
+	l.total -= e.Size
+	delete(l.entries, name)
+}
+
+func (l *ledger) markLostEntry(name string) {
+	e, ok := l.entries[name]
+	if !ok {
+		return
+	}
+
+	e.Lost = true
+	l.entries[name] = e
+	l.unindex(name)
+}
+
+// unindex excludes the entry from sampling
+func (l *ledger) unindex(name string) {
+	idx, ok := l.index[name]
+	if !ok {
+		return
+	}
+
+	last := l.names[len(l.names)-1]
+	l.names[idx] = last
+	l.index[last] = idx
+	l.names = l.names[:len(l.names)-1]
+	delete(l.index, name)
+}
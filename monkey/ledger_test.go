@@ -0,0 +1,87 @@
+package monkey
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teran/go-collection/random"
+)
+
+func TestLedger(t *testing.T) {
+	r := require.New(t)
+
+	rnd := random.NewMock()
+	defer rnd.AssertExpectations(t)
+
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	now := time.Date(2025, 4, 8, 10, 0, 0, 0, time.UTC)
+
+	l, err := openLedger(path)
+	r.NoError(err)
+
+	_, ok := l.random(rnd)
+	r.False(ok)
+
+	r.NoError(l.add(ledgerEntry{Object: "obj1", Pool: "pool", Size: 10, SHA256: "obj1", AckedAt: now}))
+	r.NoError(l.add(ledgerEntry{Object: "obj2", Pool: "pool", Size: 20, SHA256: "obj2", AckedAt: now.Add(time.Second)}))
+	r.NoError(l.add(ledgerEntry{Object: "obj2", Pool: "pool", Size: 20, SHA256: "obj2", AckedAt: now.Add(time.Second)}))
+	r.NoError(l.add(ledgerEntry{Object: "obj3", Pool: "pool", Size: 30, SHA256: "obj3", AckedAt: now.Add(2 * time.Second)}))
+	r.Equal(uint64(60), l.footprint())
+
+	r.NoError(l.remove("obj1"))
+	r.NoError(l.remove("unknown"))
+	r.Equal(uint64(50), l.footprint())
+
+	isNew, err := l.markLost("obj2")
+	r.NoError(err)
+	r.True(isNew)
+
+	isNew, err = l.markLost("obj2")
+	r.NoError(err)
+	r.False(isNew)
+
+	// lost entry is not sampled anymore
+	rnd.On("Intn", 1).Return(0).Once()
+	e, ok := l.random(rnd)
+	r.True(ok)
+	r.Equal("obj3", e.Object)
+
+	r.NoError(l.close())
+
+	// the state is restored from the file
+	l, err = openLedger(path)
+	r.NoError(err)
+	defer func() { r.NoError(l.close()) }()
+
+	r.Equal([]ledgerEntry{
+		{Object: "obj2", Pool: "pool", Size: 20, SHA256: "obj2", AckedAt: now.Add(time.Second), Lost: true},
+		{Object: "obj3", Pool: "pool", Size: 30, SHA256: "obj3", AckedAt: now.Add(2 * time.Second)},
+	}, l.list())
+	r.Equal(uint64(50), l.footprint())
+}
+
+func TestLedgerCorrupted(t *testing.T) {
+	r := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	r.NoError(os.WriteFile(path, []byte(`{"op":"unknown","object":"obj1"}`+"\n"), 0o644))
+
+	_, err := openLedger(path)
+	r.Error(err)
+}
+
+func TestLedgerDestroy(t *testing.T) {
+	r := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+
+	l, err := openLedger(path)
+	r.NoError(err)
+	r.NoError(l.add(ledgerEntry{Object: "obj1", Pool: "pool", Size: 10, SHA256: "obj1"}))
+
+	r.NoError(l.destroy())
+	r.NoFileExists(path)
+}
@@ -0,0 +1,167 @@
+package monkey
+
+import (
+	"fmt"
+	"maps"
+	"time"
+)
+
+type Level string
+
+const (
+	LevelBeginner     Level = "beginner"
+	LevelIntermediate Level = "intermediate"
+	LevelExpert       Level = "expert"
+)
+
+// LevelProfile describes what the game at the difficulty level is like
+type LevelProfile struct {
+	Level Level
+
+	// Fusses maps the IDs of the fusses offered at the level to their
+	// relative weights. All the fusses but the level-only ones are offered
+	// with the same weight if nil.
+	Fusses map[string]uint
+
+	// Interval and Duration are the defaults for the game
+	Interval time.Duration
+	Duration time.Duration
+
+	// MinInterval and MaxDuration limit the game settings
+	MinInterval time.Duration
+	MaxDuration time.Duration
+
+	// Warmup is the time since the game start without fusses so the trainee
+	// could get familiar with the cluster
+	Warmup time.Duration
+
+	// MinOSDs and MinMons are checked before the game so the fusses of the
+	// level are recoverable in the cluster
+	MinOSDs int
+	MinMons int
+}
+
+// beginnerFusses are flags and ratios only: nothing is lost and everything is
+// fixed with a single command
+var beginnerFusses = map[string]uint{
+	"set-flag":               3,
+	"unset-flag":             1,
+	"set-group-flag":         2,
+	"unset-group-flag":       1,
+	"set-nearfull-ratio":     2,
+	"set-backfillfull-ratio": 2,
+	"set-full-ratio":         2,
+}
+
+// intermediateFusses add daemons going down, data movement and config
+var intermediateFusses = mergeWeights(beginnerFusses, map[string]uint{
+	"stop-osd":                 3,
+	"change-pg-num":            2,
+	"resize-pool":              1,
+	"drain-host":               1,
+	"reweight-by-utilization":  1,
+	"set-osd-reweight":         2,
+	"set-osd-primary-affinity": 1,
+	"deep-scrub-pg":            1,
+	"set-balancer-mode":        1,
+	"disable-balancer":         1,
+	"set-config-option":        2,
+	"freeze-cluster-io":        1,
+	"fail-mds":                 1,
+	"set-max-mds":              1,
+	"stop-rgw-daemon":          1,
+	"restart-rgw-daemon":       1,
+	"set-tiny-bucket-quota":    1,
+	"lock-rbd-image":           1,
+	"resize-rbd-image":         1,
+	"blocklist-own-client":     1,
+	"strip-own-client-caps":    1,
+})
+
+// expertFusses add the ones which could lose the data or quorum if handled
+// wrong
+var expertFusses = mergeWeights(intermediateFusses, map[string]uint{
+	"remove-monitor":               1,
+	"destroy-osd":                  1,
+	"set-osd-crush-weight":         2,
+	"add-bogus-upmap":              2,
+	"inject-data-error":            2,
+	"make-filesystem-not-joinable": 1,
+	"take-filesystem-down":         1,
+	"make-zone-read-only":          1,
+	"remove-rbd-snapshot":          1,
+	"flatten-rbd-clone":            1,
+	"blocklist-rbd-watcher":        1,
+	"strip-client-caps":            1,
+})
+
+var levels = map[Level]LevelProfile{
+	LevelBeginner: {
+		Level:       LevelBeginner,
+		Fusses:      beginnerFusses,
+		Interval:    5 * time.Minute,
+		Duration:    30 * time.Minute,
+		MinInterval: 2 * time.Minute,
+		MaxDuration: time.Hour,
+		Warmup:      5 * time.Minute,
+		MinOSDs:     1,
+		MinMons:     1,
+	},
+	LevelIntermediate: {
+		Level:       LevelIntermediate,
+		Fusses:      intermediateFusses,
+		Interval:    2 * time.Minute,
+		Duration:    45 * time.Minute,
+		MinInterval: time.Minute,
+		MaxDuration: time.Hour,
+		Warmup:      2 * time.Minute,
+		MinOSDs:     3,
+		MinMons:     1,
+	},
+	LevelExpert: {
+		Level:       LevelExpert,
+		Fusses:      expertFusses,
+		Interval:    time.Minute,
+		Duration:    time.Hour,
+		MinInterval: 30 * time.Second,
+		MaxDuration: time.Hour,
+		MinOSDs:     3,
+		MinMons:     3,
+	},
+}
+
+// defaultLevelProfile offers all the fusses with the historical limits
+func defaultLevelProfile() LevelProfile {
+	return LevelProfile{
+		MinInterval: 30 * time.Second,
+		MaxDuration: time.Hour,
+		MinOSDs:     1,
+	}
+}
+
+// GetLevelProfile returns the built-in profile of the difficulty level
+func GetLevelProfile(l Level) (LevelProfile, error) {
+	p, ok := levels[l]
+	if !ok {
+		return LevelProfile{}, fmt.Errorf("unknown level `%s`", l)
+	}
+	return p, nil
+}
+
+// weight returns the relative weight of the fuss at the level, zero if it's
+// not offered
+func (p LevelProfile) weight(f fuss) uint {
+	if p.Fusses == nil {
+		if f.levelOnly {
+			return 0
+		}
+		return 1
+	}
+	return p.Fusses[f.id]
+}
+
+func mergeWeights(base, extra map[string]uint) map[string]uint {
+	m := maps.Clone(base)
+	maps.Copy(m, extra)
+	return m
+}
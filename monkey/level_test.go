@@ -0,0 +1,112 @@
+package monkey
+
+import (
+	"github.com/teran/ceph-chaos-monkey/ceph"
+)
+
+func (s *cephTestSuite) TestLevelFussesAreInCatalogue() {
+	for _, l := range []Level{LevelBeginner, LevelIntermediate, LevelExpert} {
+		p, err := GetLevelProfile(l)
+		s.Require().NoError(err)
+
+		for id, weight := range p.Fusses {
+			_, ok := fussByID(id)
+			s.Require().True(ok, "%s: %s", l, id)
+			s.Require().NotZero(weight, "%s: %s", l, id)
+		}
+	}
+
+	_, err := GetLevelProfile("godlike")
+	s.Require().Error(err)
+}
+
+func (s *cephTestSuite) TestLevelsAreGradual() {
+	beginner, _ := GetLevelProfile(LevelBeginner)
+	intermediate, _ := GetLevelProfile(LevelIntermediate)
+	expert, _ := GetLevelProfile(LevelExpert)
+
+	for id := range beginner.Fusses {
+		s.Require().Contains(intermediate.Fusses, id)
+	}
+
+	for id := range intermediate.Fusses {
+		s.Require().Contains(expert.Fusses, id)
+	}
+
+	s.Require().NotContains(beginner.Fusses, "destroy-osd")
+	s.Require().NotContains(intermediate.Fusses, "destroy-osd")
+	s.Require().Contains(expert.Fusses, "destroy-osd")
+}
+
+func (s *cephTestSuite) TestDefaultLevelSkipsLevelOnlyFusses() {
+	p := defaultLevelProfile()
+
+	for _, id := range []string{"stop-osd", "set-osd-crush-weight", "inject-data-error"} {
+		f, ok := fussByID(id)
+		s.Require().True(ok, id)
+		s.Require().Zero(p.weight(f), id)
+	}
+
+	f, _ := fussByID("set-flag")
+	s.Require().Equal(uint(1), p.weight(f))
+
+	expert, _ := GetLevelProfile(LevelExpert)
+	f, _ = fussByID("inject-data-error")
+	s.Require().NotZero(expert.weight(f))
+}
+
+func (s *cephTestSuite) TestDoSomeFussWeighted() {
+	m := s.newTestMonkey()
+	m.level = LevelProfile{Fusses: map[string]uint{
+		"set-flag":   3,
+		"unset-flag": 1,
+	}}
+
+	// set-flag takes [0, 3) and unset-flag takes [3, 4)
+	s.rnd.On("Intn", 4).Return(3).Once()
+	s.rnd.On("Intn", len(cephFlags)).Return(3).Once()
+	s.cluster.On("UnsetFlag", ceph.FlagNoOut).Return(nil).Once()
+
+	s.Require().NoError(m.doSomeFuss(s.ctx))
+	s.Require().Equal("unset random flag", m.journal[0].Entry)
+}
+
+func (s *cephTestSuite) TestDoSomeFussProbesCapabilityOnce() {
+	m := s.newTestMonkey()
+	m.level = LevelProfile{Fusses: map[string]uint{
+		"set-flag":           1,
+		"stop-rgw-daemon":    1,
+		"restart-rgw-daemon": 1,
+	}}
+
+	s.cluster.On("ListRGWDaemons").Return([]ceph.Daemon{}, nil).Once()
+	s.rnd.On("Intn", 1).Return(0).Once()
+	s.rnd.On("Intn", len(cephFlags)).Return(3).Once()
+	s.cluster.On("SetFlag", ceph.FlagNoOut).Return(nil).Once()
+
+	s.Require().NoError(m.doSomeFuss(s.ctx))
+	s.Require().Equal("set random flag", m.journal[0].Entry)
+}
+
+func (s *cephTestSuite) TestPreflightCheckLevelRequiresMons() {
+	m := s.newTestMonkey()
+	m.printer = NewPrinter()
+	m.level, _ = GetLevelProfile(LevelExpert)
+
+	s.cluster.On("GetHealth").Return(ceph.Health{Status: "HEALTH_OK"}, nil).Once()
+	s.cluster.On("GetOSDs").Return([]ceph.OSD{{ID: 0}, {ID: 1}, {ID: 2}}, nil).Once()
+	s.cluster.On("GetMons").Return([]ceph.Mon{{Name: "a"}}, nil).Once()
+
+	s.Require().False(m.preflightCheck(s.ctx))
+}
+
+func (s *cephTestSuite) TestPreflightCheckLevelRequiresOSDs() {
+	m := s.newTestMonkey()
+	m.printer = NewPrinter()
+	m.level, _ = GetLevelProfile(LevelIntermediate)
+
+	s.cluster.On("GetHealth").Return(ceph.Health{Status: "HEALTH_OK"}, nil).Once()
+	s.cluster.On("GetOSDs").Return([]ceph.OSD{{ID: 0}}, nil).Once()
+
+	s.Require().False(m.preflightCheck(s.ctx))
+}
@@ -1,22 +1,43 @@
 package monkey
 
 import (
-	"bufio"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/teran/go-collection/random"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/teran/ceph-chaos-monkey/ceph"
 	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+	"github.com/teran/ceph-chaos-monkey/s3"
 )
 
+const (
+	// artifactPrefix is used to name everything created by the monkey in the
+	// cluster
+	artifactPrefix = "chaos-monkey-"
+
+	ioClientPrefix = "client." + artifactPrefix
+
+	fussTimeout               = 2 * time.Minute
+	bgIOOpTimeout             = 30 * time.Second
+	backgroundShutdownTimeout = time.Minute
+)
+
+var errChecksumMismatch = errors.New("checksum mismatch")
+
 type Monkey interface {
 	Run(ctx context.Context) error
 }
@@ -35,27 +56,287 @@ type monkey struct {
 	rnd          random.Random
 	bgIOPoolName string
 
-	journal []JournalEntry
+	// ioRnd is used by the background IO so the fusses stream is kept
+	// reproducible when seeded
+	ioRnd random.Random
+
+	// ioCluster is used by the background IO and is set to the throwaway
+	// client on the game start
+	ioCluster drivers.Cluster
+
+	policy    SafetyPolicy
+	confirmer Confirmer
+	level     LevelProfile
+
+	profile   WorkloadProfile
+	ledgerDir string
+	ledger    *ledger
+	reportDir string
+
+	workloads []workloadRun
+
+	// rbdWorkloadRnd enables the RBD workload in the background IO pool if
+	// set. The workload is created on the game start once the pool is ready.
+	rbdWorkloadRnd random.Random
+
+	// cephFSDir and s3Workload are the settings of the workloads to be
+	// checkpointed
+	cephFSDir  string
+	s3Workload *S3WorkloadConfig
+
+	// scenarios are played alongside the random fusses or instead of them
+	// if scenariosOnly is set. Only one scenario is played at a time.
+	scenarios     []Scenario
+	scenariosOnly bool
+	scenario      *scenarioRun
+
+	// console is read for the trainee commands during the game if set
+	console io.Reader
+	score   score
+
+	// blind hides the fusses behind the incident IDs until the trainee
+	// diagnoses them
+	blind     bool
+	incidents []*incident
+
+	// seed and source are set for the seeded game so the random stream
+	// position could be checkpointed
+	seed   int64
+	source *countingSource
+
+	// checkpointDir enables the game checkpoints to be saved to
+	// checkpointPath, it's set from the checkpoint on resume
+	checkpointDir  string
+	checkpointPath string
+	fsid           string
+
+	// startedAt is shifted back by elapsed when the game is resumed
+	startedAt time.Time
+	elapsed   time.Duration
+	resumed   bool
+
+	// controlSocket is listened for the instructor commands steering the
+	// game, the game clock is frozen while paused
+	controlSocket string
+	controlC      <-chan controlCommand
+	gameTimer     *time.Timer
+	paused        bool
+	pausedAt      time.Time
+	skipNext      bool
+
+	// ui shows the live state of the game if set, nextTickAt is the time of
+	// the next fuss tick for its countdown
+	ui         UI
+	nextTickAt time.Time
+
+	// played is set once the game is confirmed and the cluster passed the
+	// preflight check
+	played bool
+
+	journal   []JournalEntry
+	rollbacks []pendingRollback
+}
+
+type Option func(*monkey)
+
+// WithWorkload adds the background workload to be run during the game
+func WithWorkload(w Workload) Option {
+	return func(m *monkey) {
+		m.workloads = append(m.workloads, workloadRun{
+			workload: w,
+			stats:    NewStats(),
+		})
+	}
+}
+
+// WithWorkloadProfile sets the profile of the background RADOS IO
+func WithWorkloadProfile(p WorkloadProfile) Option {
+	return func(m *monkey) {
+		m.profile = p
+	}
+}
+
+// WithSafetyPolicy sets the policy the cluster is checked against before the
+// game. The default one refuses any cluster.
+func WithSafetyPolicy(p SafetyPolicy) Option {
+	return func(m *monkey) {
+		m.policy = p
+	}
+}
+
+// WithLevel sets the difficulty level of the game
+func WithLevel(p LevelProfile) Option {
+	return func(m *monkey) {
+		m.level = p
+	}
+}
+
+// WithConfirmer sets the way the game is confirmed by the operator, the
+// questions are asked on stdin by default
+func WithConfirmer(c Confirmer) Option {
+	return func(m *monkey) {
+		m.confirmer = c
+	}
 }
 
+// WithLedgerDir sets the directory to keep the ledger of acknowledged writes in
+func WithLedgerDir(dir string) Option {
+	return func(m *monkey) {
+		m.ledgerDir = dir
+	}
+}
+
+// WithReportDir sets the directory to save the final report of the game to
+func WithReportDir(dir string) Option {
+	return func(m *monkey) {
+		m.reportDir = dir
+	}
+}
+
+// WithScenarios adds the scenarios to be played during the game
+func WithScenarios(scenarios ...Scenario) Option {
+	return func(m *monkey) {
+		m.scenarios = append(m.scenarios, scenarios...)
+	}
+}
+
+// WithScenariosOnly disables the random fusses so only the scenarios are
+// played
+func WithScenariosOnly() Option {
+	return func(m *monkey) {
+		m.scenariosOnly = true
+	}
+}
+
+// WithSeed makes the fusses and the scenarios picked from the random stream
+// seeded with seed so the same game could be played on several clusters. The
+// background IO keeps using the random passed to New.
+func WithSeed(seed int64) Option {
+	return func(m *monkey) {
+		m.seed = seed
+		m.source = newCountingSource(seed, 0)
+		m.rnd = newLockedRandom(rand.New(m.source))
+	}
+}
+
+// WithRBDWorkload enables the workload writing and verifying blocks of the RBD
+// image in the background IO pool
+func WithRBDWorkload(rnd random.Random) Option {
+	return func(m *monkey) {
+		m.rbdWorkloadRnd = rnd
+	}
+}
+
+// WithCephFSWorkload enables the file workload in the directory on the mounted
+// CephFS. The directory is checkpointed so the resumed game runs it too.
+func WithCephFSWorkload(root string, rnd random.Random) Option {
+	return func(m *monkey) {
+		m.cephFSDir = root
+		WithWorkload(NewCephFSWorkload(root, rnd))(m)
+	}
+}
+
+// WithS3Workload enables the object workload against the bucket of the RADOS
+// Gateway. The settings including the credentials are checkpointed so the
+// resumed game runs it too.
+func WithS3Workload(cfg S3WorkloadConfig, rnd random.Random) (Option, error) {
+	client, err := s3.New(cfg.Endpoint, cfg.Region, cfg.AccessKey, cfg.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(m *monkey) {
+		m.s3Workload = &cfg
+		WithWorkload(NewS3Workload(client, cfg.Bucket, rnd))(m)
+	}, nil
+}
+
+type fussFn func(context.Context, drivers.Cluster, random.Random) ([]rollback, error)
+
 type fuss struct {
+	id   string
 	name string
-	fn   func(context.Context, drivers.Cluster, random.Random) error
+	fn   fussFn
+
+	// requires is checked before offering the fuss if set
+	requires *capability
+
+	// levelOnly keeps the fuss out of the game played without the level, it's
+	// offered only by the levels listing it
+	levelOnly bool
+}
+
+// capability is the cluster feature required by the fusses, it's probed once
+// per pick of the fuss however many fusses require it
+type capability struct {
+	probe func(context.Context, drivers.Cluster) bool
 }
 
-func New(cluster drivers.Cluster, rnd random.Random, printer Printer, stats Stats, interval time.Duration, duration time.Duration) Monkey {
-	return &monkey{
+var (
+	filesystemsCapability = &capability{probe: hasFilesystems}
+	rgwDaemonsCapability  = &capability{probe: hasRGWDaemons}
+	rbdImagesCapability   = &capability{probe: hasRBDImages}
+)
+
+// capabilities keeps the results of the probes made during the pick
+type capabilities map[*capability]bool
+
+func (cs capabilities) has(ctx context.Context, c drivers.Cluster, required *capability) bool {
+	if required == nil {
+		return true
+	}
+
+	v, ok := cs[required]
+	if !ok {
+		v = required.probe(ctx, c)
+		cs[required] = v
+	}
+	return v
+}
+
+// noRollback wraps the fuss which changes are left for the trainee to revert
+func noRollback(fn func(context.Context, drivers.Cluster, random.Random) error) fussFn {
+	return func(ctx context.Context, c drivers.Cluster, rnd random.Random) ([]rollback, error) {
+		return nil, fn(ctx, c, rnd)
+	}
+}
+
+func New(cluster drivers.Cluster, rnd random.Random, printer Printer, stats Stats, interval time.Duration, duration time.Duration, opts ...Option) Monkey {
+	return newMonkey(cluster, rnd, printer, stats, interval, duration, opts...)
+}
+
+func newMonkey(cluster drivers.Cluster, rnd random.Random, printer Printer, stats Stats, interval time.Duration, duration time.Duration, opts ...Option) *monkey {
+	rnd = newLockedRandom(rnd)
+
+	m := &monkey{
 		cluster:      cluster,
 		duration:     duration,
 		interval:     interval,
 		printer:      printer,
 		rnd:          rnd,
+		ioRnd:        rnd,
 		stats:        stats,
-		bgIOPoolName: fmt.Sprintf("chaos-monkey-%d", rnd.Uint32()*rnd.Uint32()),
+		bgIOPoolName: fmt.Sprintf("%s%d", artifactPrefix, rnd.Uint32()*rnd.Uint32()),
+		ioCluster:    cluster,
+		profile:      DefaultWorkloadProfile(),
+		ledgerDir:    os.TempDir(),
+		reportDir:    os.TempDir(),
+		confirmer:    NewStdinConfirmer(),
+		level:        defaultLevelProfile(),
 	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
 }
 
 func (m *monkey) Run(ctx context.Context) error {
+	if m.resumed {
+		return m.resume(ctx)
+	}
+
 	m.printer.Println(`This software is designed to train Ceph engineers to recover Ceph clusters in
 various ways by interacting with Ceph components and data to trigger errors
 in the cluster. Therefore it could damage the data stored within the cluster
@@ -64,17 +345,30 @@ and that's why there are some limitations where you can run ceph-chaos-monkey:
 * >0 && <=10 OSD daemons
 * <=500 GB of raw space
 
-These restrictions are hardcoded and cannot be changed in runtime but anyway
-if you have such a small clusters with important data please check twice where
-you're running ceph-chaos-monkey.`)
+These restrictions are hardcoded and cannot be changed in runtime. On top of
+that the cluster fsid must be in the allowlist and the cluster must not carry
+the production marker so please check twice where you're running
+ceph-chaos-monkey.`)
 	m.printer.Println()
 
-	if m.interval < 30*time.Second || m.duration > time.Hour {
-		m.printer.Println("Games are limited with interval >=30s and duration <=1h")
-		fmt.Println()
+	if m.interval < m.level.MinInterval || m.duration > m.level.MaxDuration {
+		m.printer.Printf("Games are limited with interval >=%s and duration <=%s\n", m.level.MinInterval, m.level.MaxDuration)
+		m.printer.Println()
+		return nil
+	}
+
+	if err := m.policy.Check(ctx, m.cluster); err != nil {
+		m.printer.Printf("Refusing to run the game: %s\n", err)
 		return nil
 	}
 
+	for _, sc := range m.scenarios {
+		if err := m.policy.checkScenario(sc); err != nil {
+			m.printer.Printf("Refusing to run the game: %s\n", err)
+			return nil
+		}
+	}
+
 	questions := []string{
 		"Your Ceph cluster could be permanently damaged, are you sure you want to proceed?",
 		"The data in your Ceph cluster could be permanently lost, are you still sure to proceed?",
@@ -87,7 +381,7 @@ you're running ceph-chaos-monkey.`)
 	m.printer.Println()
 
 	for _, q := range questions {
-		if !askForConfirmation(q) {
+		if !m.confirmer.Confirm(ctx, q) {
 			m.printer.Println()
 			m.printer.Println("Ain't brave enough for this? No worries, get back later")
 
@@ -100,255 +394,923 @@ you're running ceph-chaos-monkey.`)
 	if ok := m.preflightCheck(ctx); !ok {
 		return nil
 	}
+	m.played = true
 
 	m.printer.Printf(
 		"Huh... that's what you wanted, let's go! Waiting %d seconds for the first action ...\n",
-		int(m.interval.Seconds()),
+		int(max(m.interval, m.level.Warmup).Seconds()),
 	)
 
-	ctx, cancel := context.WithTimeout(ctx, m.duration)
+	if m.level.Level != "" {
+		m.journal = append(m.journal, JournalEntry{
+			Timestamp: time.Now(),
+			Entry:     fmt.Sprintf("the game is played at %s level", m.level.Level),
+		})
+	}
+
+	if m.source != nil {
+		m.journal = append(m.journal, JournalEntry{
+			Timestamp: time.Now(),
+			Entry:     fmt.Sprintf("the fusses are picked with the random seeded with %d", m.seed),
+		})
+	}
+
+	return m.play(ctx)
+}
+
+// play runs the game confirmed or resumed from the checkpoint till the end
+func (m *monkey) play(ctx context.Context) error {
+	m.startedAt = time.Now().Add(-m.elapsed)
+
+	// the game is over once the timer fires, ctx is canceled then to stop the
+	// background IO
+	m.gameTimer = time.NewTimer(m.duration - m.elapsed)
+	defer m.gameTimer.Stop()
+
+	// the game paused by the instructor is resumed from the checkpoint still
+	// paused till the instructor resumes it
+	if m.paused {
+		m.pausedAt = m.startedAt.Add(m.elapsed)
+		m.gameTimer.Stop()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	go func(ctx context.Context) { _ = m.doBackgroundIO(ctx) }(ctx)
+	if err := m.setupIOPool(ctx); err != nil {
+		log.Debugf("error creating background IO pool: %s", err)
+	}
+
+	ledgerPath := filepath.Join(m.ledgerDir, m.bgIOPoolName+".ledger.jsonl")
+	l, err := openLedger(ledgerPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = l.close() }()
+	m.ledger = l
+
+	if m.elapsed == 0 {
+		m.journal = append(m.journal, JournalEntry{
+			Timestamp: time.Now(),
+			Entry:     fmt.Sprintf("acknowledged writes are recorded to the ledger at %s", ledgerPath),
+		})
+	}
+
+	deleteIOClient := m.setupIOClient(ctx)
+	defer deleteIOClient()
+
+	if m.rbdWorkloadRnd != nil {
+		WithWorkload(newRBDWorkload(m.ioCluster, m.bgIOPoolName, m.rbdWorkloadRnd))(m)
+	}
+
+	if m.elapsed == 0 {
+		m.journal = append(m.journal, JournalEntry{
+			Timestamp: time.Now(),
+			Entry:     fmt.Sprintf("background IO profile: %s", m.profile),
+		})
+	}
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func(ctx context.Context) {
+		defer wg.Done()
+		_ = m.doBackgroundIO(ctx)
+	}(ctx)
+
+	for _, w := range m.workloads {
+		wg.Add(1)
+		go func(ctx context.Context, w workloadRun) {
+			defer wg.Done()
+			if err := w.workload.Run(ctx, w.stats); err != nil {
+				log.Debugf("error running %s workload: %s", w.workload.Name(), err)
+			}
+		}(ctx, w)
+	}
 
 	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	m.nextTickAt = time.Now().Add(m.interval)
+
+	rollbackTicker := time.NewTicker(time.Second)
+	defer rollbackTicker.Stop()
+
+	// time-boxed changes must be reverted even if the game is canceled
+	defer m.doRollbacks(ctx)
+
+	var commands <-chan consoleCommand
+	if m.console != nil {
+		m.printer.Println("Type `hint` and press Enter to get the hint on the active health checks")
+		if m.blind {
+			m.printer.Println("Type `diagnose <incident> <fuss-id>` once you know what the incident is, `fusses` lists the IDs")
+		}
+		commands = readConsole(m.console)
+	}
+
+	var controls <-chan controlCommand
+	if m.controlSocket != "" {
+		controls, err = listenControl(ctx, m.controlSocket)
+		if err != nil {
+			log.Warnf("error listening control socket, the game couldn't be steered: %s", err)
+		}
+	}
+
+	var checkpointC <-chan time.Time
+	if m.startCheckpoints(ctx) {
+		checkpointTicker := time.NewTicker(checkpointInterval)
+		defer checkpointTicker.Stop()
+		checkpointC = checkpointTicker.C
+	}
+
+	clusterState := &atomic.Pointer[ClusterState]{}
+	if m.ui != nil {
+		wg.Add(1)
+		go func(ctx context.Context) {
+			defer wg.Done()
+			m.watchCluster(ctx, clusterState)
+		}(ctx)
+		m.updateUI(nil)
+	}
+
+	interrupted := false
 
 outer:
 	for {
 		select {
-		case <-ctx.Done():
-			if err := ctx.Err(); err != context.DeadlineExceeded {
-				return err
+		case cmd, ok := <-commands:
+			if !ok {
+				commands = nil
+				continue
 			}
-
+			m.handleCommand(ctx, cmd)
+		case cmd := <-controls:
+			m.handleControl(ctx, cmd)
+		case cmd := <-m.controlC:
+			m.handleControl(ctx, cmd)
+		case <-rollbackTicker.C:
+			m.doDueRollbacks(ctx)
+			if !m.paused {
+				m.doDueScenarioSteps(ctx)
+			}
+			if m.ui != nil {
+				m.updateUI(clusterState.Load())
+			}
+		case <-checkpointC:
+			m.saveCheckpoint()
+		case <-m.gameTimer.C:
+			break outer
+		case <-ctx.Done():
+			interrupted = true
 			break outer
 		case <-ticker.C:
+			m.nextTickAt = time.Now().Add(m.interval)
+			if m.paused || m.gameElapsed() < m.level.Warmup {
+				continue
+			}
+
+			if m.skipNext {
+				m.skipNext = false
+				m.printer.Println("Tick! Skipping this one ...")
+				m.journal = append(m.journal, JournalEntry{
+					Timestamp: time.Now(),
+					Entry:     "the fuss is skipped by the instructor",
+				})
+				continue
+			}
+
+			if m.scenario == nil && len(m.scenarios) > 0 {
+				m.printer.Println("Tick! Starting the scenario of dangerous things in the cluster ...")
+				m.startScenario()
+				continue
+			}
+
+			if m.scenariosOnly {
+				continue
+			}
+
 			m.printer.Println("Tick! Running something dangerous in the cluster ...")
 			if err := m.doSomeFuss(ctx); err != nil {
-				if err != context.DeadlineExceeded {
-					log.Debugf("error doSomeFuss(): %s", err)
-					continue
-				}
-
-				break outer
+				log.Debugf("error doSomeFuss(): %s", err)
 			}
 		}
 	}
 
+	cancel()
+	m.waitBackground(wg)
+
+	if m.ui != nil {
+		m.ui.Close()
+	}
+
+	report, err := m.openReport()
+	if err != nil {
+		log.Warnf("error creating report file, the report is printed only: %s", err)
+	}
+
 	m.printer.Println()
-	m.printer.Println("Game is over! Go check your cluster if it's still alive :-)")
+	if interrupted {
+		m.printer.Println("Game is interrupted! Go check your cluster if it's still alive :-)")
+	} else {
+		m.printer.Println("Game is over! Go check your cluster if it's still alive :-)")
+	}
+	m.printer.Println()
+
+	m.doRollbacks(ctx)
+	if len(m.rollbacks) > 0 {
+		m.keepFailedRollbacks()
+	} else {
+		m.removeCheckpoint()
+	}
+
+	if osds, err := m.cluster.GetOSDs(context.WithoutCancel(ctx)); err != nil {
+		log.Debugf("error getting OSDs: %s", err)
+	} else {
+		m.printer.Println("OSD weights distribution after the game:")
+		m.printOSDWeights(osds)
+		m.printer.Println()
+	}
 	m.printer.Println()
 
 	s := m.stats.Dump()
-	m.printer.Printf("Avg Reads latency = %.3fs\n", s.AvgReadsLatency.Seconds())
-	m.printer.Printf("Avg Writes latency = %.3fs\n", s.AvgWritesLatency.Seconds())
-	m.printer.Printf("Read operations succeeded = %.2f%%\n", s.ReadsSuccessPercent*100)
-	m.printer.Printf("Write operations succeeded = %.2f%%\n", s.ReadsSuccessPercent*100)
+	m.printStats(s)
+	m.printer.Printf("IO stalls caused by freezes = %d (%.0fs total)\n", s.StallsCountTotal, s.StallsDurationTotal.Seconds())
+	m.printer.Printf("Acknowledged writes found lost during the game = %d\n", s.LostWritesTotal)
+
+	for _, w := range m.workloads {
+		m.printer.Println()
+		m.printer.Printf("%s workload:\n", w.workload.Name())
+		m.printStats(w.stats.Dump())
+	}
+
+	m.printer.Println()
+	m.printer.Println("Auditing the data written during the game ...")
+	audit := m.audit(ctx)
+	m.printAudit(audit)
+
+	// the ledger is kept for the investigation if anything went wrong
+	if !interrupted && audit.Intact == audit.Total {
+		m.removeLedger()
+	}
+
+	m.printer.Println()
+	m.printScore()
 
 	m.printer.Println()
 	m.printer.Println("Here's the journal of your adventure during the game:")
 	for _, j := range m.journal {
-		fmt.Printf("- %s: %s\n", j.Timestamp.Format(time.RFC3339), j.Entry)
+		m.printer.Printf("- %s: %s\n", j.Timestamp.Format(time.RFC3339), j.Entry)
+	}
+
+	if report != nil {
+		m.closeReport(report)
 	}
 
 	return nil
 }
 
+// removeLedger removes the ledger file once the game is finished cleanly
+func (m *monkey) removeLedger() {
+	if err := m.ledger.destroy(); err != nil {
+		log.Warnf("error removing ledger: %s", err)
+	}
+}
+
+// waitBackground waits for the background IO and workloads to finish their
+// in-flight operations
+func (m *monkey) waitBackground(wg *sync.WaitGroup) {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(backgroundShutdownTimeout):
+		log.Warnf("background IO didn't finish in %s, not waiting for it anymore", backgroundShutdownTimeout)
+	}
+}
+
+// fusses is the catalogue of everything the monkey could do to the cluster.
+// The IDs are stable so they could be referred from the policy files.
+var fusses = []fuss{
+	{
+		id:   "set-flag",
+		name: "set random flag",
+		fn:   setRandomFlag,
+	},
+	{
+		id:   "unset-flag",
+		name: "unset random flag",
+		fn:   noRollback(unsetRandomFlag),
+	},
+	{
+		id:   "destroy-osd",
+		name: "destroy random OSD",
+		fn:   noRollback(destroyRandomOSD),
+	},
+	{
+		id:        "stop-osd",
+		name:      "stop random OSD daemon",
+		fn:        noRollback(stopRandomOSDDaemon),
+		levelOnly: true,
+	},
+	{
+		id:   "resize-pool",
+		name: "randomly resize random pool",
+		fn:   noRollback(randomlyResizeRandomPool),
+	},
+	{
+		id:   "change-pg-num",
+		name: "randomly change pg_num for random pool",
+		fn:   noRollback(randomlyChangePGNumForRandomPool),
+	},
+	{
+		id:   "reweight-by-utilization",
+		name: "run reweight-by-utilization",
+		fn:   noRollback(reweightByUtilization),
+	},
+	{
+		id:   "set-osd-reweight",
+		name: "set random reweight for random OSD",
+		fn:   noRollback(setRandomOSDReweight),
+	},
+	{
+		id:   "set-osd-primary-affinity",
+		name: "set random primary-affinity for random OSD",
+		fn:   noRollback(setRandomOSDPrimaryAffinity),
+	},
+	{
+		id:        "set-osd-crush-weight",
+		name:      "set random CRUSH weight for random OSD",
+		fn:        noRollback(setRandomOSDCrushWeight),
+		levelOnly: true,
+	},
+	{
+		id:   "set-nearfull-ratio",
+		name: "set random value for nearfull-ratio",
+		fn:   noRollback(setRandomNearFullRatio),
+	},
+	{
+		id:   "set-backfillfull-ratio",
+		name: "set random value for backfillfull-ratio",
+		fn:   noRollback(setRandomBackfillfullRatio),
+	},
+	{
+		id:   "set-full-ratio",
+		name: "set random value for full-ratio",
+		fn:   noRollback(setRandomFullRatio),
+	},
+	{
+		id:   "remove-monitor",
+		name: "remove random monitor",
+		fn:   noRollback(removeRandomMonitor),
+	},
+	{
+		id:   "drain-host",
+		name: "drain random host",
+		fn:   noRollback(drainRandomHost),
+	},
+	{
+		id:   "set-group-flag",
+		name: "set random flag for random group",
+		fn:   noRollback(setRandomFlagForRandomGroup),
+	},
+	{
+		id:   "unset-group-flag",
+		name: "unset random flag from random group",
+		fn:   noRollback(unsetRandomFlagFromRandomGroup),
+	},
+	{
+		id:   "deep-scrub-pg",
+		name: "run deep-scrub for random PG",
+		fn:   noRollback(deepScrubRandomPG),
+	},
+	{
+		id:   "add-bogus-upmap",
+		name: "add bogus upmap for random PG",
+		fn:   noRollback(addBogusUpmapForRandomPG),
+	},
+	{
+		id:        "inject-data-error",
+		name:      "corrupt random object copy",
+		fn:        injectDataErrorIntoRandomObject,
+		levelOnly: true,
+	},
+	{
+		id:   "set-balancer-mode",
+		name: "set random balancer mode",
+		fn:   noRollback(setRandomBalancerMode),
+	},
+	{
+		id:   "disable-balancer",
+		name: "turn balancer off",
+		fn:   noRollback(disableBalancer),
+	},
+	{
+		id:   "freeze-cluster-io",
+		name: "freeze cluster IO for a while",
+		fn:   freezeClusterIO,
+	},
+	{
+		id:       "fail-mds",
+		name:     "fail random active MDS",
+		fn:       noRollback(failRandomActiveMDS),
+		requires: filesystemsCapability,
+	},
+	{
+		id:       "set-max-mds",
+		name:     "set random max_mds for random filesystem",
+		fn:       noRollback(setRandomMaxMDSForRandomFilesystem),
+		requires: filesystemsCapability,
+	},
+	{
+		id:       "make-filesystem-not-joinable",
+		name:     "make random filesystem not joinable",
+		fn:       noRollback(makeRandomFilesystemNotJoinable),
+		requires: filesystemsCapability,
+	},
+	{
+		id:       "take-filesystem-down",
+		name:     "take random filesystem down",
+		fn:       noRollback(takeRandomFilesystemDown),
+		requires: filesystemsCapability,
+	},
+	{
+		id:       "stop-rgw-daemon",
+		name:     "stop random RGW daemon for a while",
+		fn:       stopRandomRGWDaemon,
+		requires: rgwDaemonsCapability,
+	},
+	{
+		id:       "restart-rgw-daemon",
+		name:     "restart random RGW daemon",
+		fn:       noRollback(restartRandomRGWDaemon),
+		requires: rgwDaemonsCapability,
+	},
+	{
+		id:       "set-tiny-bucket-quota",
+		name:     "set tiny quota for random bucket",
+		fn:       noRollback(setTinyQuotaForRandomBucket),
+		requires: rgwDaemonsCapability,
+	},
+	{
+		id:       "make-zone-read-only",
+		name:     "make random zone read-only",
+		fn:       makeRandomZoneReadOnly,
+		requires: rgwDaemonsCapability,
+	},
+	{
+		id:       "lock-rbd-image",
+		name:     "lock random RBD image for a while",
+		fn:       lockRandomRBDImage,
+		requires: rbdImagesCapability,
+	},
+	{
+		id:       "remove-rbd-snapshot",
+		name:     "remove random RBD snapshot",
+		fn:       noRollback(removeRandomRBDSnapshot),
+		requires: rbdImagesCapability,
+	},
+	{
+		id:       "flatten-rbd-clone",
+		name:     "flatten random RBD clone",
+		fn:       noRollback(flattenRandomRBDClone),
+		requires: rbdImagesCapability,
+	},
+	{
+		id:       "resize-rbd-image",
+		name:     "resize random RBD image",
+		fn:       noRollback(resizeRandomRBDImage),
+		requires: rbdImagesCapability,
+	},
+	{
+		id:       "blocklist-rbd-watcher",
+		name:     "blocklist random RBD image watcher",
+		fn:       blocklistRandomRBDWatcher,
+		requires: rbdImagesCapability,
+	},
+	{
+		id:   "set-config-option",
+		name: "set harmful value for random config option",
+		fn:   setRandomConfigOption,
+	},
+	{
+		id:   "blocklist-own-client",
+		name: "blocklist hosts of chaos monkey client",
+		fn:   blocklistOwnClient,
+	},
+	{
+		id:   "strip-own-client-caps",
+		name: "strip caps from chaos monkey client",
+		fn:   stripMonkeyClientCaps,
+	},
+	{
+		id:   "strip-client-caps",
+		name: "strip caps from random client",
+		fn:   stripRandomClientCaps,
+	},
+}
+
 func (m *monkey) doSomeFuss(ctx context.Context) error {
-	cases := []fuss{
-		{
-			name: "set random flag",
-			fn:   setRandomFlag,
-		},
-		{
-			name: "unset random flag",
-			fn:   unsetRandomFlag,
-		},
-		{
-			name: "destroy random OSD",
-			fn:   destroyRandomOSD,
-		},
-		{
-			name: "randomly resize random pool",
-			fn:   randomlyResizeRandomPool,
-		},
-		{
-			name: "randomly change pg_num for random pool",
-			fn:   randomlyChangePGNumForRandomPool,
-		},
-		{
-			name: "run reweight-by-utilization",
-			fn:   reweightByUtilization,
-		},
-		{
-			name: "set random value for nearfull-ratio",
-			fn:   setRandomNearFullRatio,
-		},
-		{
-			name: "set random value for backfillfull-ratio",
-			fn:   setRandomBackfillfullRatio,
-		},
-		{
-			name: "set random value for full-ratio",
-			fn:   setRandomFullRatio,
-		},
-		{
-			name: "remove random monitor",
-			fn:   removeRandomMonitor,
-		},
-		{
-			name: "drain random host",
-			fn:   drainRandomHost,
-		},
-		{
-			name: "set random flag for random group",
-			fn:   setRandomFlagForRandomGroup,
-		},
-		{
-			name: "unset random flag from random group",
-			fn:   unsetRandomFlagFromRandomGroup,
-		},
-		{
-			name: "run deep-scrub for random PG",
-			fn:   deepScrubRandomPG,
-		},
-	}
-
-	c := cases[m.rnd.Intn(len(cases))]
+	available := []fuss{}
+	probed := capabilities{}
+	var total uint
+	for _, c := range fusses {
+		if !m.policy.allows(c) || m.level.weight(c) == 0 {
+			continue
+		}
 
-	m.journal = append(m.journal, JournalEntry{
+		if probed.has(ctx, m.cluster, c.requires) {
+			available = append(available, c)
+			total += m.level.weight(c)
+		}
+	}
+
+	if len(available) == 0 {
+		log.Debugf("no fusses allowed by the policy are applicable to the cluster")
+		return nil
+	}
+
+	// the fuss is picked with the probability proportional to its weight at
+	// the level
+	n := uint(m.rnd.Intn(int(total)))
+	c := available[0]
+	for _, f := range available {
+		if n < m.level.weight(f) {
+			c = f
+			break
+		}
+		n -= m.level.weight(f)
+	}
+
+	return m.runFuss(ctx, c)
+}
+
+// runFuss triggers the fuss and records its rollbacks
+func (m *monkey) runFuss(ctx context.Context, c fuss) error {
+	entry := JournalEntry{
 		Timestamp: time.Now(),
 		Entry:     c.name,
-	})
+	}
+	m.journal = append(m.journal, entry)
+
+	if m.blind {
+		m.newIncident(c.id, entry)
+	}
+
+	// the fuss in flight is let to finish even if the game is interrupted so
+	// its rollbacks are recorded
+	fussCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), fussTimeout)
+	defer cancel()
 
-	err := c.fn(ctx, m.cluster, m.rnd)
-	if err != context.DeadlineExceeded {
+	rollbacks, err := c.fn(fussCtx, m.cluster, m.rnd)
+	m.addRollbacks(rollbacks...)
+	if err != nil {
 		m.journal = append(m.journal, JournalEntry{
 			Timestamp: time.Now(),
 			Entry:     fmt.Sprintf("cluster operations are failing (during %s)", c.name),
 		})
 	}
 
-	return c.fn(ctx, m.cluster, m.rnd)
+	return err
 }
 
-func (m *monkey) doBackgroundIO(ctx context.Context) error {
-	g, ctx := errgroup.WithContext(ctx)
+// addRollbacks checkpoints the game right away so the changes are reverted
+// on resume even if the process dies before the next checkpoint
+func (m *monkey) addRollbacks(rollbacks ...rollback) {
+	if len(rollbacks) == 0 {
+		return
+	}
 
-	g.Go(func() error {
-		return m.doBackgroundIOReads(ctx)
-	})
+	now := time.Now()
+	for _, r := range rollbacks {
+		m.rollbacks = append(m.rollbacks, pendingRollback{
+			Rollback: r,
+			Since:    now,
+		})
+	}
 
-	g.Go(func() error {
-		return m.doBackgroundIOWrites(ctx)
-	})
+	m.saveCheckpoint()
+}
 
-	return g.Wait()
+// doDueRollbacks reverts the time-boxed changes which window is over. The
+// failed ones are kept pending to be retried on the next tick: the cluster
+// could be barely responding during the game.
+func (m *monkey) doDueRollbacks(ctx context.Context) {
+	now := time.Now()
+
+	pending := []pendingRollback{}
+	for i := len(m.rollbacks) - 1; i >= 0; i-- {
+		r := m.rollbacks[i]
+		if r.isDue(now) && m.applyRollback(ctx, &r) == nil {
+			continue
+		}
+		pending = append([]pendingRollback{r}, pending...)
+	}
+
+	if len(pending) == len(m.rollbacks) {
+		m.rollbacks = pending
+		return
+	}
+
+	// the reverted changes must not be reverted once again on resume
+	m.rollbacks = pending
+	m.saveCheckpoint()
 }
 
-func (m *monkey) doBackgroundIOReads(ctx context.Context) error {
+// doRollbacks reverts all the pending changes in reverse order so the
+// original value is restored even if the same setting was changed twice.
+// The failed ones are retried till rollbackTimeout and left in m.rollbacks.
+// It's safe to call it multiple times and with the context already canceled.
+func (m *monkey) doRollbacks(ctx context.Context) {
+	if len(m.rollbacks) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+	defer cancel()
+
+	m.printer.Println("Reverting temporary changes made during the game ...")
+
 	for {
+		failed := []pendingRollback{}
+		for i := len(m.rollbacks) - 1; i >= 0; i-- {
+			r := m.rollbacks[i]
+			if err := m.applyRollback(ctx, &r); err != nil {
+				failed = append([]pendingRollback{r}, failed...)
+			}
+		}
+
+		m.rollbacks = failed
+		if len(m.rollbacks) == 0 {
+			return
+		}
+
+		t := time.NewTimer(rollbackRetryInterval)
 		select {
 		case <-ctx.Done():
-			if err := ctx.Err(); err != context.DeadlineExceeded {
-				return err
-			}
-			return nil
-		default:
-			pools, err := m.cluster.GetPools(ctx)
-			if err != nil {
-				log.Debug("error getting pool list")
-				continue
-			}
+			t.Stop()
+			return
+		case <-t.C:
+		}
+	}
+}
 
-			isPoolExists := false
-			for _, p := range pools {
-				if p.PoolName == m.bgIOPoolName {
-					isPoolExists = true
-				}
-			}
+// applyRollback journals the failure once so the retries don't flood the
+// journal
+func (m *monkey) applyRollback(ctx context.Context, r *pendingRollback) error {
+	if err := r.Rollback.apply(ctx, m.cluster); err != nil {
+		log.Debugf("error applying rollback `%s`: %s", r.Rollback, err)
+
+		if !r.Failed {
+			r.Failed = true
+			m.journal = append(m.journal, JournalEntry{
+				Timestamp: time.Now(),
+				Entry:     fmt.Sprintf("failed to revert temporary change: %s, retrying", r.Rollback),
+			})
+		}
+		return err
+	}
 
-			if !isPoolExists {
-				log.Tracef("pool %s is not exists yet", m.bgIOPoolName)
-				continue
-			}
+	if r.Rollback.Stall {
+		m.stats.ObserveStall(time.Since(r.Since))
+	}
 
-			start := time.Now()
-			objs, err := m.cluster.ListRADOSObjects(ctx, m.bgIOPoolName)
-			if err != nil {
-				m.stats.ObserveRead(time.Since(start), err)
-				continue
-			}
+	m.journal = append(m.journal, JournalEntry{
+		Timestamp: time.Now(),
+		Entry:     fmt.Sprintf("reverted temporary change: %s", r.Rollback),
+	})
+	return nil
+}
 
-			if len(objs) == 0 {
-				continue
-			}
+// setupIOClient creates the throwaway client the background IO is performed
+// on behalf of, so auth related fusses affect the IO stats. The returned
+// function deletes the client once the game is over.
+func (m *monkey) setupIOClient(ctx context.Context) func() {
+	name := "client." + m.bgIOPoolName
+
+	// the client of the resumed game is reused: its caps could be stripped by
+	// the fuss pending the rollback so get-or-create would fail and leave the
+	// client behind
+	var (
+		entity ceph.AuthEntity
+		err    error
+	)
+	if m.resumed {
+		entity, err = m.cluster.GetAuth(ctx, name)
+	}
+	if !m.resumed || err != nil {
+		entity, err = m.cluster.CreateAuth(ctx, name, map[string]string{
+			"mon": "profile rbd",
+			"osd": "profile rbd pool=" + m.bgIOPoolName,
+		})
+	}
+	if err != nil {
+		log.Debugf("error creating background IO client, falling back to the default one: %s", err)
+		return func() {}
+	}
 
-			obj := objs[m.rnd.Intn(len(objs))]
+	client, remove, err := m.cluster.AsClient(ctx, entity)
+	if err != nil {
+		log.Debugf("error setting up background IO client, falling back to the default one: %s", err)
+		remove = func(context.Context) error { return nil }
+	} else {
+		m.ioCluster = client
 
-			data, err := m.cluster.ReadRADOSObject(ctx, m.bgIOPoolName, obj)
-			if err != nil {
-				m.stats.ObserveRead(time.Since(start), err)
-				continue
-			}
+		m.journal = append(m.journal, JournalEntry{
+			Timestamp: time.Now(),
+			Entry:     fmt.Sprintf("background IO is performed on behalf of %s", entity.Entity),
+		})
+	}
 
-			hasher := sha256.New()
-			if _, err := hasher.Write(data); err != nil {
-				return err
-			}
+	// the client is deleted even if the game is interrupted
+	ctx = context.WithoutCancel(ctx)
 
-			if obj != hex.EncodeToString(hasher.Sum(nil)) {
-				m.stats.ObserveRead(time.Since(start), err)
-				continue
-			}
-			m.stats.ObserveRead(time.Since(start), nil)
+	return func() {
+		ctx, cancel := context.WithTimeout(ctx, rollbackTimeout)
+		defer cancel()
+
+		if err := remove(ctx); err != nil {
+			log.Warnf("error removing background IO client keyring: %s", err)
+		}
+
+		if err := m.cluster.DeleteAuth(ctx, entity.Entity); err != nil {
+			log.Warnf("error deleting background IO client %s: %s", entity.Entity, err)
 		}
 	}
 }
 
-func (m *monkey) doBackgroundIOWrites(ctx context.Context) error {
+func (m *monkey) setupIOPool(ctx context.Context) error {
 	pools, err := m.cluster.GetPools(ctx)
 	if err != nil {
 		return err
 	}
 
-	isPoolExists := false
 	for _, v := range pools {
 		if v.PoolName == m.bgIOPoolName {
-			isPoolExists = true
-			break
+			return nil
 		}
 	}
 
-	if !isPoolExists {
-		if err := m.cluster.CreateDefaultPool(ctx, m.bgIOPoolName); err != nil {
-			return err
-		}
+	if err := m.cluster.CreateDefaultPool(ctx, m.bgIOPoolName); err != nil {
+		return err
 	}
 
+	return m.cluster.EnablePoolApplication(ctx, m.bgIOPoolName, poolApplication)
+}
+
+func (m *monkey) doBackgroundIO(ctx context.Context) error {
+	limiter := newRateLimiter(m.profile.OpsPerSecond)
+	defer limiter.stop()
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	for range m.profile.Concurrency {
+		g.Go(func() error {
+			return m.doBackgroundIOWorker(ctx, limiter)
+		})
+	}
+
+	return g.Wait()
+}
+
+// doBackgroundIOWorker performs the operations at the limiter pace, the
+// unlimited worker backs off once the operations are failing or there's
+// nothing to read or delete
+func (m *monkey) doBackgroundIOWorker(ctx context.Context, limiter *rateLimiter) error {
+	backoff := &idleBackoff{}
+
 	for {
-		select {
-		case <-ctx.Done():
-			if err := ctx.Err(); err != context.DeadlineExceeded {
+		if err := limiter.wait(ctx); err != nil {
+			if err != context.DeadlineExceeded {
 				return err
 			}
 			return nil
-		default:
-			buf := make([]byte, m.rnd.Intn(1024*1024*1024))
-			if _, err := m.rnd.Read(buf); err != nil {
-				return err
-			}
+		}
+
+		ok := m.doBackgroundIOOp(ctx)
+		if limiter != nil {
+			continue
+		}
 
-			hasher := sha256.New()
-			if _, err := hasher.Write(buf); err != nil {
+		if err := backoff.wait(ctx, ok); err != nil {
+			if err != context.DeadlineExceeded {
 				return err
 			}
+			return nil
+		}
+	}
+}
+
+// doBackgroundIOOp performs the operation picked by the profile and returns
+// true if it's succeeded. The operation is let to finish or time out even if
+// the game is over so its result is recorded to the ledger.
+func (m *monkey) doBackgroundIOOp(ctx context.Context) bool {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), bgIOOpTimeout)
+	defer cancel()
+
+	switch m.profile.Mix.pick(m.ioRnd) {
+	case ioOpRead:
+		return m.doBackgroundIORead(ctx)
+	case ioOpWrite:
+		return m.doBackgroundIOWrite(ctx)
+	case ioOpDelete:
+		return m.doBackgroundIODelete(ctx)
+	}
+
+	return false
+}
+
+func (m *monkey) doBackgroundIORead(ctx context.Context) bool {
+	e, ok := m.ledger.random(m.ioRnd)
+	if !ok {
+		return false
+	}
+
+	start := time.Now()
+	data, err := m.ioCluster.ReadRADOSObject(ctx, e.Pool, e.Object)
+	if err == nil {
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != e.SHA256 {
+			err = fmt.Errorf("object %s: %w", e.Object, errChecksumMismatch)
+		}
+	}
+	m.stats.ObserveRead(time.Since(start), err)
+
+	if errors.Is(err, drivers.ErrNotFound) || errors.Is(err, errChecksumMismatch) {
+		isNew, lerr := m.ledger.markLost(e.Object)
+		if lerr != nil {
+			log.Debugf("error writing ledger: %s", lerr)
+		}
+
+		if isNew {
+			m.stats.ObserveLostWrite()
+		}
+	}
+
+	return err == nil
+}
+
+func (m *monkey) doBackgroundIOWrite(ctx context.Context) bool {
+	if m.ledger.footprint() >= uint64(m.profile.MaxFootprint) {
+		return m.doBackgroundIODelete(ctx)
+	}
+
+	buf := make([]byte, m.profile.ObjectSize.sample(m.ioRnd))
+	if _, err := m.ioRnd.Read(buf); err != nil {
+		log.Debugf("error generating object data: %s", err)
+		return false
+	}
 
-			start := time.Now()
-			err = m.cluster.CreateRADOSObject(ctx, m.bgIOPoolName, hex.EncodeToString(hasher.Sum(nil)), buf)
-			m.stats.ObserveWrite(time.Since(start), err)
+	sum := sha256.Sum256(buf)
+	name := hex.EncodeToString(sum[:])
+
+	start := time.Now()
+	err := m.ioCluster.CreateRADOSObject(ctx, m.bgIOPoolName, name, buf)
+	m.stats.ObserveWrite(time.Since(start), err)
+	if err != nil {
+		return false
+	}
+
+	err = m.ledger.add(ledgerEntry{
+		Object:  name,
+		Pool:    m.bgIOPoolName,
+		Size:    uint64(len(buf)),
+		SHA256:  name,
+		AckedAt: time.Now(),
+	})
+	if err != nil {
+		log.Debugf("error writing ledger: %s", err)
+	}
+
+	return true
+}
+
+func (m *monkey) doBackgroundIODelete(ctx context.Context) bool {
+	e, ok := m.ledger.random(m.ioRnd)
+	if !ok {
+		return false
+	}
+
+	// the object is removed from the ledger before the deletion so the
+	// concurrent readers don't consider it lost
+	if err := m.ledger.remove(e.Object); err != nil {
+		log.Debugf("error writing ledger: %s", err)
+		return false
+	}
+
+	start := time.Now()
+	err := m.ioCluster.DeleteRADOSObject(ctx, e.Pool, e.Object)
+	m.stats.ObserveDelete(time.Since(start), err)
+	if err != nil {
+		// the object is likely still there so it's kept tracked
+		if lerr := m.ledger.add(e); lerr != nil {
+			log.Debugf("error writing ledger: %s", lerr)
 		}
+		return false
 	}
+
+	return true
 }
 
 func (m *monkey) preflightCheck(ctx context.Context) bool {
@@ -364,11 +1326,24 @@ func (m *monkey) preflightCheck(ctx context.Context) bool {
 		return false
 	}
 
-	if len(osds) == 0 || len(osds) > 10 {
-		m.printer.Printf("OSDs count must be >0 && <=10, you have: %d\n", len(osds))
+	if len(osds) < max(m.level.MinOSDs, 1) || len(osds) > 10 {
+		m.printer.Printf("OSDs count must be >=%d && <=10, you have: %d\n", max(m.level.MinOSDs, 1), len(osds))
 		return false
 	}
 
+	if m.level.MinMons > 0 {
+		mons, err := m.cluster.GetMons(ctx)
+		if err != nil {
+			m.printer.Println("Can't do a preflight check, sorry ...")
+			return false
+		}
+
+		if len(mons) < m.level.MinMons {
+			m.printer.Printf("Monitors count must be >=%d at %s level, you have: %d\n", m.level.MinMons, m.level.Level, len(mons))
+			return false
+		}
+	}
+
 	var total uint64
 	for _, osd := range osds {
 		total += (osd.KbUsed + osd.KbAvailable)
@@ -379,6 +1354,10 @@ func (m *monkey) preflightCheck(ctx context.Context) bool {
 		return false
 	}
 
+	m.printer.Println("OSD weights distribution before the game:")
+	m.printOSDWeights(osds)
+	m.printer.Println()
+
 	m.journal = append(m.journal, JournalEntry{
 		Timestamp: time.Now(),
 		Entry: fmt.Sprintf(
@@ -390,24 +1369,28 @@ func (m *monkey) preflightCheck(ctx context.Context) bool {
 	return true
 }
 
-func askForConfirmation(prompt string) bool {
-	reader := bufio.NewReader(os.Stdin)
-
-	for {
-		fmt.Printf("%s [y/n]: ", prompt)
+func (m *monkey) printStats(s MeasurementValue) {
+	m.printer.Printf("Avg Reads latency = %.3fs\n", s.AvgReadsLatency.Seconds())
+	m.printer.Printf("Avg Writes latency = %.3fs\n", s.AvgWritesLatency.Seconds())
+	m.printer.Printf("Read operations succeeded = %.2f%%\n", s.ReadsSuccessPercent*100)
+	m.printer.Printf("Write operations succeeded = %.2f%%\n", s.WritesSuccessPercent*100)
 
-		response, err := reader.ReadString('\n')
-		if err != nil {
-			return false
-		}
+	if s.MetadataOpsCountTotal > 0 {
+		m.printer.Printf("Avg Metadata operations latency = %.3fs\n", s.AvgMetadataOpsLatency.Seconds())
+		m.printer.Printf("Metadata operations succeeded = %.2f%%\n", s.MetadataOpsSuccessPercent*100)
+	}
 
-		response = strings.ToLower(strings.TrimSpace(response))
+	if s.DeletesCountTotal > 0 {
+		m.printer.Printf("Avg Deletes latency = %.3fs\n", s.AvgDeletesLatency.Seconds())
+		m.printer.Printf("Delete operations succeeded = %.2f%%\n", s.DeletesSuccessPercent*100)
+	}
+}
 
-		switch response {
-		case "y", "yes":
-			return true
-		case "n", "no":
-			return false
-		}
+func (m *monkey) printOSDWeights(osds []ceph.OSD) {
+	for _, osd := range osds {
+		m.printer.Printf(
+			"- osd.%d (%s): crush weight = %.4f, reweight = %.4f, primary affinity = %.4f\n",
+			osd.ID, strings.Join(osd.State, ","), osd.CrushWeight, osd.Reweight, osd.PrimaryAffinity,
+		)
 	}
 }
@@ -0,0 +1,276 @@
+package monkey
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+func (s *cephTestSuite) newTestMonkey() *monkey {
+	profile := DefaultWorkloadProfile()
+	profile.ObjectSize = ObjectSizeProfile{Distribution: SizeDistributionFixed, Size: 4}
+	profile.MaxFootprint = 4
+
+	l, err := openLedger(filepath.Join(s.T().TempDir(), "ledger.jsonl"))
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { _ = l.close() })
+
+	return &monkey{
+		cluster:      s.cluster,
+		ioCluster:    s.cluster,
+		rnd:          s.rnd,
+		ioRnd:        s.rnd,
+		stats:        NewStats(),
+		profile:      profile,
+		ledger:       l,
+		bgIOPoolName: "chaos-monkey-123",
+	}
+}
+
+func (s *cephTestSuite) TestDoBackgroundIOWriteAndDelete() {
+	m := s.newTestMonkey()
+
+	sum := sha256.Sum256([]byte("data"))
+	name := hex.EncodeToString(sum[:])
+
+	s.rnd.On("Read").Return([]byte("data"), 4, nil).Once()
+	s.cluster.On("CreateRADOSObject", "chaos-monkey-123", name, []byte("data")).Return(nil).Once()
+
+	m.doBackgroundIOWrite(s.ctx)
+	s.Require().Equal(uint64(4), m.ledger.footprint())
+
+	// the footprint limit is reached so the write turns into delete
+	s.rnd.On("Intn", 1).Return(0).Once()
+	s.cluster.On("DeleteRADOSObject", "chaos-monkey-123", name).Return(nil).Once()
+
+	m.doBackgroundIOWrite(s.ctx)
+	s.Require().Zero(m.ledger.footprint())
+
+	v := m.stats.Dump()
+	s.Require().Equal(uint64(1), v.WritesCountTotal)
+	s.Require().Equal(uint64(1), v.DeletesCountTotal)
+	s.Require().Zero(v.MetadataOpsCountTotal)
+}
+
+func (s *cephTestSuite) TestDoBackgroundIODeleteError() {
+	m := s.newTestMonkey()
+
+	s.Require().NoError(m.ledger.add(ledgerEntry{Object: "obj1", Pool: "chaos-monkey-123", Size: 4, SHA256: "obj1"}))
+
+	s.rnd.On("Intn", 1).Return(0).Once()
+	s.cluster.On("DeleteRADOSObject", "chaos-monkey-123", "obj1").Return(errors.New("test error")).Once()
+
+	s.Require().False(m.doBackgroundIODelete(s.ctx))
+
+	// the object failed to delete is kept in the ledger
+	s.Require().Equal(uint64(4), m.ledger.footprint())
+	s.Require().Equal(uint64(1), m.stats.Dump().DeletesErrorsTotal)
+}
+
+func (s *cephTestSuite) TestDoBackgroundIORead() {
+	m := s.newTestMonkey()
+
+	names := []string{}
+	for _, data := range []string{"intact", "missing", "corrupt"} {
+		sum := sha256.Sum256([]byte(data))
+		name := hex.EncodeToString(sum[:])
+		names = append(names, name)
+		s.Require().NoError(m.ledger.add(ledgerEntry{Object: name, Pool: "chaos-monkey-123", Size: 1, SHA256: name}))
+	}
+
+	s.rnd.On("Intn", 3).Return(0).Once()
+	s.cluster.On("ReadRADOSObject", "chaos-monkey-123", names[0]).Return([]byte("intact"), nil).Once()
+	m.doBackgroundIORead(s.ctx)
+
+	s.rnd.On("Intn", 3).Return(1).Once()
+	s.cluster.On("ReadRADOSObject", "chaos-monkey-123", names[1]).Return([]byte(nil), fmt.Errorf("object: %w", drivers.ErrNotFound)).Once()
+	m.doBackgroundIORead(s.ctx)
+
+	// the lost object is replaced with the last one on sampling
+	s.rnd.On("Intn", 2).Return(1).Once()
+	s.cluster.On("ReadRADOSObject", "chaos-monkey-123", names[2]).Return([]byte("garbage"), nil).Once()
+	m.doBackgroundIORead(s.ctx)
+
+	v := m.stats.Dump()
+	s.Require().Equal(uint64(3), v.ReadsCountTotal)
+	s.Require().Equal(uint64(2), v.ReadsErrorsTotal)
+	s.Require().Equal(uint64(2), v.LostWritesTotal)
+}
+
+func (s *cephTestSuite) newRunMonkey(duration time.Duration, opts ...Option) *monkey {
+	s.rnd.On("Uint32").Return(uint32(1)).Times(2)
+
+	dir := s.T().TempDir()
+	opts = append([]Option{
+		WithSafetyPolicy(SafetyPolicy{AllowedFSIDs: []string{testFSID}}),
+		WithLedgerDir(dir),
+		WithReportDir(dir),
+	}, opts...)
+
+	return New(s.cluster, s.rnd, NewPrinter(), NewStats(), 30*time.Second, duration, opts...).(*monkey)
+}
+
+// expectGame sets the expectations for the game which fusses are never
+// triggered
+func (s *cephTestSuite) expectGame() {
+	osds := []ceph.OSD{{ID: 0, KbUsed: 1024, KbAvailable: 1024}}
+
+	s.cluster.On("GetFSID").Return(testFSID, nil).Once()
+	s.cluster.On("GetHealth").Return(ceph.Health{Status: "HEALTH_OK"}, nil).Once()
+	s.cluster.On("GetOSDs").Return(osds, nil).Twice()
+	s.cluster.On("GetPools").Return([]ceph.Pool{}, nil).Once()
+	s.cluster.On("CreateDefaultPool", "chaos-monkey-1").Return(nil).Once()
+	s.cluster.On("EnablePoolApplication", "chaos-monkey-1", "chaos-monkey").Return(nil).Once()
+	s.cluster.On("CreateAuth", "client.chaos-monkey-1", mock.Anything).Return(ceph.AuthEntity{}, errors.New("access denied")).Once()
+	s.rnd.On("Intn", 1).Return(0).Maybe()
+}
+
+func (s *cephTestSuite) readReport(m *monkey) string {
+	data, err := os.ReadFile(filepath.Join(m.reportDir, "chaos-monkey-1.report.txt"))
+	s.Require().NoError(err)
+	return string(data)
+}
+
+func (s *cephTestSuite) TestRunRefusedByPolicy() {
+	m := s.newRunMonkey(time.Second, WithConfirmer(confirmerFunc(func(string) bool {
+		s.FailNow("must not be asked")
+		return false
+	})))
+
+	s.cluster.On("GetFSID").Return("11111111-2222-3333-4444-555555555555", nil).Once()
+
+	s.Require().NoError(m.Run(s.ctx))
+}
+
+func (s *cephTestSuite) TestRunNotConfirmed() {
+	asked := 0
+	m := s.newRunMonkey(time.Second, WithConfirmer(confirmerFunc(func(string) bool {
+		asked++
+		return asked < 3
+	})))
+
+	s.cluster.On("GetFSID").Return(testFSID, nil).Once()
+
+	s.Require().NoError(m.Run(s.ctx))
+	s.Require().Equal(3, asked)
+}
+
+func (s *cephTestSuite) TestRun() {
+	profile := DefaultWorkloadProfile()
+	profile.Mix = OpsMix{Read: 1}
+	profile.Concurrency = 1
+
+	m := s.newRunMonkey(
+		200*time.Millisecond,
+		WithConfirmer(confirmerFunc(func(string) bool { return true })),
+		WithWorkloadProfile(profile),
+	)
+	s.expectGame()
+
+	s.Require().NoError(m.Run(s.ctx))
+	// the game is finished cleanly so the ledger is not needed anymore
+	s.Require().NoFileExists(filepath.Join(m.ledgerDir, "chaos-monkey-1.ledger.jsonl"))
+
+	report := s.readReport(m)
+	s.Require().Contains(report, "Game is over!")
+	s.Require().Contains(report, "Here's the journal of your adventure during the game:")
+}
+
+func (s *cephTestSuite) TestRunWithConsole() {
+	profile := DefaultWorkloadProfile()
+	profile.Mix = OpsMix{Read: 1}
+	profile.Concurrency = 1
+
+	m := s.newRunMonkey(
+		200*time.Millisecond,
+		WithConfirmer(confirmerFunc(func(string) bool { return true })),
+		WithWorkloadProfile(profile),
+		WithConsole(strings.NewReader("hint\n")),
+	)
+	s.expectGame()
+	s.cluster.On("GetHealth").Return(ceph.Health{
+		Status: "HEALTH_WARN",
+		Checks: map[string]ceph.HealthCheck{"OSDMAP_FLAGS": {}},
+	}, nil).Once()
+
+	s.Require().NoError(m.Run(s.ctx))
+
+	report := s.readReport(m)
+	s.Require().Contains(report, "Score: 90 of 100 (1 hints used, -10 each)")
+	s.Require().Contains(report, "hint requested for OSDMAP_FLAGS")
+}
+
+func (s *cephTestSuite) TestRunInterrupted() {
+	profile := DefaultWorkloadProfile()
+	profile.Mix = OpsMix{Read: 1}
+	profile.Concurrency = 1
+
+	m := s.newRunMonkey(
+		time.Minute,
+		WithConfirmer(confirmerFunc(func(string) bool { return true })),
+		WithWorkloadProfile(profile),
+	)
+	s.expectGame()
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	s.Require().NoError(m.Run(ctx))
+	s.Require().Contains(s.readReport(m), "Game is interrupted!")
+	s.Require().FileExists(filepath.Join(m.ledgerDir, "chaos-monkey-1.ledger.jsonl"))
+}
+
+func (s *cephTestSuite) TestSetupIOClient() {
+	m := s.newTestMonkey()
+	m.ioCluster = nil
+
+	entity := ceph.AuthEntity{Entity: "client.chaos-monkey-123", Key: "AQBJSOln"}
+	removed := false
+
+	s.cluster.On("CreateAuth", "client.chaos-monkey-123", map[string]string{
+		"mon": "profile rbd",
+		"osd": "profile rbd pool=chaos-monkey-123",
+	}).Return(entity, nil).Once()
+	s.cluster.On("AsClient", entity).Return(s.cluster, func(context.Context) error {
+		removed = true
+		return nil
+	}, nil).Once()
+
+	deleteIOClient := m.setupIOClient(s.ctx)
+	s.Require().Equal(s.cluster, m.ioCluster)
+
+	s.cluster.On("DeleteAuth", "client.chaos-monkey-123").Return(nil).Once()
+	deleteIOClient()
+	s.Require().True(removed)
+}
+
+func (s *cephTestSuite) TestSetupIOClientResumed() {
+	m := s.newTestMonkey()
+	m.ioCluster = nil
+	m.resumed = true
+
+	// the caps are stripped by the fuss, the client is reused as is
+	entity := ceph.AuthEntity{Entity: "client.chaos-monkey-123", Key: "AQBJSOln", Caps: map[string]string{"mon": "allow r"}}
+
+	s.cluster.On("GetAuth", "client.chaos-monkey-123").Return(entity, nil).Once()
+	s.cluster.On("AsClient", entity).Return(s.cluster, func(context.Context) error { return nil }, nil).Once()
+
+	deleteIOClient := m.setupIOClient(s.ctx)
+	s.Require().Equal(s.cluster, m.ioCluster)
+
+	s.cluster.On("DeleteAuth", "client.chaos-monkey-123").Return(nil).Once()
+	deleteIOClient()
+}
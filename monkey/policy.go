@@ -0,0 +1,231 @@
+package monkey
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+// SafetyPolicy describes the clusters the game is allowed to be run against
+// and what the monkey is allowed to do there
+type SafetyPolicy struct {
+	// AllowedFSIDs is the allowlist of the clusters. The game is refused on
+	// any cluster which fsid is not there.
+	AllowedFSIDs []string
+
+	// ProductionConfigKey refuses the game on the cluster which has the key
+	// set in the monitors config-key store. Empty to disable.
+	ProductionConfigKey string
+
+	// ProductionPoolApplication refuses the game on the cluster which has any
+	// pool tagged with the application. Empty to disable.
+	ProductionPoolApplication string
+
+	// AllowedFusses is the list of the fuss IDs the monkey is allowed to
+	// trigger. All of them are allowed if nil.
+	AllowedFusses []string
+}
+
+type policyFile struct {
+	AllowedFusses []string `yaml:"allowed_fusses"`
+}
+
+// LoadPolicyPublicKey reads the PEM encoded ed25519 public key the allowlist
+// and policy files are signed with
+func LoadPolicyPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("%s is not a PEM encoded public key", path)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing public key %s: %w", path, err)
+	}
+
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an ed25519 public key", path)
+	}
+
+	return pub, nil
+}
+
+// LoadFSIDAllowlist reads the allowlist file with one cluster fsid per line.
+// Empty lines and the lines started with `#` are ignored. The file must be
+// signed with the key unless it's nil.
+func LoadFSIDAllowlist(path string, key ed25519.PublicKey) ([]string, error) {
+	data, err := readPolicyFile(path, key)
+	if err != nil {
+		return nil, err
+	}
+
+	fsids := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fsids = append(fsids, strings.ToLower(line))
+	}
+
+	return fsids, scanner.Err()
+}
+
+// LoadAllowedFusses reads the list of allowed fuss IDs from the YAML policy
+// file. The file must be signed with the key unless it's nil.
+func LoadAllowedFusses(path string, key ed25519.PublicKey) ([]string, error) {
+	data, err := readPolicyFile(path, key)
+	if err != nil {
+		return nil, err
+	}
+
+	p := policyFile{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("error decoding policy file: %w", err)
+	}
+
+	if len(p.AllowedFusses) == 0 {
+		return nil, errors.New("policy file must allow at least one fuss")
+	}
+
+	for _, id := range p.AllowedFusses {
+		if _, ok := fussByID(id); !ok {
+			return nil, fmt.Errorf("unknown fuss `%s` in policy file", id)
+		}
+	}
+
+	return p.AllowedFusses, nil
+}
+
+// readPolicyFile reads the file refusing the ones writable by anyone but the
+// owner: the policy is only as trustworthy as the file it's kept in. If the
+// key is set the file must also carry the valid detached signature in the
+// file with `.sig` suffix.
+func readPolicyFile(path string, key ed25519.PublicKey) ([]byte, error) {
+	fp, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = fp.Close() }()
+
+	fi, err := fp.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if fi.Mode().Perm()&0o022 != 0 {
+		return nil, fmt.Errorf("%s must not be writable by group or others, has %s", path, fi.Mode().Perm())
+	}
+
+	data, err := io.ReadAll(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	if key == nil {
+		return data, nil
+	}
+
+	sig, err := os.ReadFile(path + ".sig")
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s signature: %w", path, err)
+	}
+
+	if !ed25519.Verify(key, data, sig) {
+		return nil, fmt.Errorf("%s signature doesn't match the policy public key", path)
+	}
+
+	return data, nil
+}
+
+// Check returns the error describing why the game must not be run against
+// the cluster or nil if it's allowed
+func (p SafetyPolicy) Check(ctx context.Context, c drivers.Cluster) error {
+	fsid, err := c.GetFSID(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting cluster fsid: %w", err)
+	}
+
+	if !slices.Contains(p.AllowedFSIDs, strings.ToLower(fsid)) {
+		return fmt.Errorf("cluster %s is not in the allowlist", fsid)
+	}
+
+	if p.ProductionConfigKey != "" {
+		_, err := c.GetConfigKey(ctx, p.ProductionConfigKey)
+		if err == nil {
+			return fmt.Errorf("cluster %s is marked as production with `%s` config key", fsid, p.ProductionConfigKey)
+		}
+		if !errors.Is(err, drivers.ErrNotFound) {
+			return fmt.Errorf("error checking production marker: %w", err)
+		}
+	}
+
+	if p.ProductionPoolApplication != "" {
+		pools, err := c.GetPools(ctx)
+		if err != nil {
+			return fmt.Errorf("error checking production marker: %w", err)
+		}
+
+		for _, pool := range pools {
+			if _, ok := pool.ApplicationMetadata[p.ProductionPoolApplication]; ok {
+				return fmt.Errorf(
+					"cluster %s is marked as production with `%s` application on pool %s",
+					fsid, p.ProductionPoolApplication, pool.PoolName,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p SafetyPolicy) allows(f fuss) bool {
+	return p.AllowedFusses == nil || slices.Contains(p.AllowedFusses, f.id)
+}
+
+// checkScenario returns the error if any step of the scenario is not allowed
+func (p SafetyPolicy) checkScenario(s Scenario) error {
+	if p.AllowedFusses == nil {
+		return nil
+	}
+
+	for _, step := range s.Steps {
+		id := step.fussID()
+		if id != "" && !slices.Contains(p.AllowedFusses, id) {
+			return fmt.Errorf("scenario %s: step `%s` requires `%s` fuss which is not allowed", s.Name, step, id)
+		}
+	}
+
+	return nil
+}
+
+func fussByID(id string) (fuss, bool) {
+	for _, f := range fusses {
+		if f.id == id {
+			return f, true
+		}
+	}
+	return fuss{}, false
+}
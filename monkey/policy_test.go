@@ -0,0 +1,173 @@
+package monkey
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+const testFSID = "5d6b1a3e-0c3f-11f0-9d3c-525400a1b2c3"
+
+func (s *cephTestSuite) writePolicyFile(name, content string, perm os.FileMode) string {
+	path := filepath.Join(s.T().TempDir(), name)
+	s.Require().NoError(os.WriteFile(path, []byte(content), perm))
+	s.Require().NoError(os.Chmod(path, perm))
+	return path
+}
+
+func (s *cephTestSuite) TestLoadFSIDAllowlist() {
+	path := s.writePolicyFile("allowlist", "# training clusters\n\n5D6B1A3E-0C3F-11F0-9D3C-525400A1B2C3\n  other  \n", 0o600)
+
+	fsids, err := LoadFSIDAllowlist(path, nil)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{testFSID, "other"}, fsids)
+}
+
+func (s *cephTestSuite) TestLoadFSIDAllowlistWritableByOthers() {
+	path := s.writePolicyFile("allowlist", testFSID+"\n", 0o666)
+
+	_, err := LoadFSIDAllowlist(path, nil)
+	s.Require().Error(err)
+}
+
+func (s *cephTestSuite) TestLoadAllowedFusses() {
+	path := s.writePolicyFile("policy.yaml", "allowed_fusses:\n  - set-flag\n  - drain-host\n", 0o600)
+
+	ids, err := LoadAllowedFusses(path, nil)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"set-flag", "drain-host"}, ids)
+}
+
+func (s *cephTestSuite) TestLoadSignedPolicy() {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	s.Require().NoError(err)
+
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	s.Require().NoError(err)
+	keyPath := s.writePolicyFile("policy.pub", string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), 0o644)
+
+	key, err := LoadPolicyPublicKey(keyPath)
+	s.Require().NoError(err)
+	s.Require().Equal(pub, key)
+
+	content := "allowed_fusses:\n  - set-flag\n"
+	path := s.writePolicyFile("policy.yaml", content, 0o600)
+
+	// the signature is required once the key is set
+	_, err = LoadAllowedFusses(path, key)
+	s.Require().ErrorContains(err, "signature")
+
+	s.Require().NoError(os.WriteFile(path+".sig", ed25519.Sign(priv, []byte(content)), 0o644))
+	ids, err := LoadAllowedFusses(path, key)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"set-flag"}, ids)
+
+	s.Require().NoError(os.WriteFile(path, []byte("allowed_fusses:\n  - destroy-osd\n"), 0o600))
+	_, err = LoadAllowedFusses(path, key)
+	s.Require().ErrorContains(err, "signature doesn't match")
+
+	allowlist := s.writePolicyFile("allowlist", testFSID+"\n", 0o600)
+	s.Require().NoError(os.WriteFile(allowlist+".sig", ed25519.Sign(priv, []byte(testFSID+"\n")), 0o644))
+	fsids, err := LoadFSIDAllowlist(allowlist, key)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{testFSID}, fsids)
+}
+
+func (s *cephTestSuite) TestLoadPolicyPublicKeyNotEd25519() {
+	_, err := LoadPolicyPublicKey(s.writePolicyFile("policy.pub", "not a key\n", 0o644))
+	s.Require().Error(err)
+}
+
+func (s *cephTestSuite) TestLoadAllowedFussesErrors() {
+	for name, content := range map[string]string{
+		"unknown fuss":  "allowed_fusses:\n  - format-disks\n",
+		"unknown field": "allowed_fusses:\n  - set-flag\ndenied_fusses: []\n",
+		"empty":         "allowed_fusses: []\n",
+	} {
+		s.Run(name, func() {
+			_, err := LoadAllowedFusses(s.writePolicyFile("policy.yaml", content, 0o600), nil)
+			s.Require().Error(err)
+		})
+	}
+}
+
+func (s *cephTestSuite) TestFussIDsAreUnique() {
+	seen := map[string]bool{}
+	for _, f := range fusses {
+		s.Require().NotEmpty(f.id, f.name)
+		s.Require().False(seen[f.id], f.id)
+		seen[f.id] = true
+	}
+}
+
+func (s *cephTestSuite) TestSafetyPolicyCheck() {
+	p := SafetyPolicy{
+		AllowedFSIDs:              []string{testFSID},
+		ProductionConfigKey:       "production",
+		ProductionPoolApplication: "production",
+	}
+
+	s.cluster.On("GetFSID").Return(testFSID, nil).Once()
+	s.cluster.On("GetConfigKey", "production").Return("", drivers.ErrNotFound).Once()
+	s.cluster.On("GetPools").Return([]ceph.Pool{
+		{PoolName: "rbd", ApplicationMetadata: map[string]map[string]string{"rbd": {}}},
+	}, nil).Once()
+
+	s.Require().NoError(p.Check(s.ctx, s.cluster))
+}
+
+func (s *cephTestSuite) TestSafetyPolicyCheckNotAllowlisted() {
+	s.cluster.On("GetFSID").Return(testFSID, nil).Once()
+
+	err := SafetyPolicy{}.Check(s.ctx, s.cluster)
+	s.Require().ErrorContains(err, "is not in the allowlist")
+}
+
+func (s *cephTestSuite) TestSafetyPolicyCheckProductionConfigKey() {
+	p := SafetyPolicy{AllowedFSIDs: []string{testFSID}, ProductionConfigKey: "production"}
+
+	s.cluster.On("GetFSID").Return(testFSID, nil).Once()
+	s.cluster.On("GetConfigKey", "production").Return("yes", nil).Once()
+
+	err := p.Check(s.ctx, s.cluster)
+	s.Require().ErrorContains(err, "marked as production")
+}
+
+func (s *cephTestSuite) TestSafetyPolicyCheckConfigKeyError() {
+	p := SafetyPolicy{AllowedFSIDs: []string{testFSID}, ProductionConfigKey: "production"}
+
+	s.cluster.On("GetFSID").Return(testFSID, nil).Once()
+	s.cluster.On("GetConfigKey", "production").Return("", errors.New("permission denied")).Once()
+
+	err := p.Check(s.ctx, s.cluster)
+	s.Require().ErrorContains(err, "error checking production marker")
+}
+
+func (s *cephTestSuite) TestSafetyPolicyCheckProductionPoolApplication() {
+	p := SafetyPolicy{AllowedFSIDs: []string{testFSID}, ProductionPoolApplication: "production"}
+
+	s.cluster.On("GetFSID").Return(testFSID, nil).Once()
+	s.cluster.On("GetPools").Return([]ceph.Pool{
+		{PoolName: "volumes", ApplicationMetadata: map[string]map[string]string{"rbd": {}, "production": {}}},
+	}, nil).Once()
+
+	err := p.Check(s.ctx, s.cluster)
+	s.Require().ErrorContains(err, "application on pool volumes")
+}
+
+func (s *cephTestSuite) TestDoSomeFussAllowedByPolicy() {
+	m := s.newTestMonkey()
+	m.policy = SafetyPolicy{AllowedFusses: []string{"set-flag"}}
+
+	s.rnd.On("Intn", 1).Return(0).Once()
+	s.rnd.On("Intn", len(cephFlags)).Return(3).Once()
+	s.cluster.On("SetFlag", ceph.FlagNoOut).Return(nil).Once()
+
+	s.Require().NoError(m.doSomeFuss(s.ctx))
+}
@@ -1,6 +1,10 @@
 package monkey
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"strings"
+)
 
 type Printer interface {
 	Println(a ...any)
@@ -20,3 +24,51 @@ func (p *printer) Println(a ...any) {
 func (p *printer) Printf(format string, a ...any) {
 	fmt.Printf(format, a...)
 }
+
+type writerPrinter struct {
+	w io.Writer
+}
+
+// newWriterPrinter prints to w instead of stdout, e.g. to the control socket
+// connection
+func newWriterPrinter(w io.Writer) Printer {
+	return &writerPrinter{w: w}
+}
+
+func (p *writerPrinter) Println(a ...any) {
+	_, _ = fmt.Fprintln(p.w, a...)
+}
+
+func (p *writerPrinter) Printf(format string, a ...any) {
+	_, _ = fmt.Fprintf(p.w, format, a...)
+}
+
+type prefixedPrinter struct {
+	printer Printer
+	prefix  string
+}
+
+// newPrefixedPrinter prefixes every line with the name so the output of the
+// games played at the same time could be told apart
+func newPrefixedPrinter(p Printer, name string) Printer {
+	return &prefixedPrinter{
+		printer: p,
+		prefix:  "[" + name + "] ",
+	}
+}
+
+func (p *prefixedPrinter) Println(a ...any) {
+	p.print(fmt.Sprintln(a...))
+}
+
+func (p *prefixedPrinter) Printf(format string, a ...any) {
+	p.print(fmt.Sprintf(format, a...))
+}
+
+func (p *prefixedPrinter) print(s string) {
+	for _, line := range strings.SplitAfter(s, "\n") {
+		if line != "" {
+			p.printer.Printf("%s%s", p.prefix, line)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package monkey
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/alecthomas/units"
+	"github.com/teran/go-collection/random"
+	"gopkg.in/yaml.v3"
+)
+
+type SizeDistribution string
+
+const (
+	SizeDistributionFixed     SizeDistribution = "fixed"
+	SizeDistributionUniform   SizeDistribution = "uniform"
+	SizeDistributionLogNormal SizeDistribution = "lognormal"
+)
+
+// WorkloadProfile describes the background RADOS IO performed during the game
+type WorkloadProfile struct {
+	ObjectSize ObjectSizeProfile `yaml:"object_size"`
+	Mix        OpsMix            `yaml:"mix"`
+
+	// OpsPerSecond is the target rate of all the workers together, zero
+	// means no limit with the idle workers backing off
+	OpsPerSecond float64 `yaml:"ops_per_second"`
+	Concurrency  int     `yaml:"concurrency"`
+
+	// MaxFootprint limits the amount of data written to the pool: the
+	// writes are turned into deletes once it's reached
+	MaxFootprint units.Base2Bytes `yaml:"max_footprint"`
+}
+
+type ObjectSizeProfile struct {
+	Distribution SizeDistribution `yaml:"distribution"`
+
+	// Size is the object size for fixed distribution and the median for
+	// lognormal one
+	Size units.Base2Bytes `yaml:"size"`
+
+	// Min and Max are the bounds for uniform and lognormal distributions
+	Min units.Base2Bytes `yaml:"min"`
+	Max units.Base2Bytes `yaml:"max"`
+
+	// Sigma is the standard deviation of the logarithm of object size for
+	// lognormal distribution
+	Sigma float64 `yaml:"sigma"`
+}
+
+// OpsMix is the relative weights of the operations
+type OpsMix struct {
+	Read   uint `yaml:"read"`
+	Write  uint `yaml:"write"`
+	Delete uint `yaml:"delete"`
+}
+
+type ioOp int
+
+const (
+	ioOpRead ioOp = iota
+	ioOpWrite
+	ioOpDelete
+)
+
+// DefaultWorkloadProfile returns the profile suitable for the small training
+// clusters
+func DefaultWorkloadProfile() WorkloadProfile {
+	return WorkloadProfile{
+		ObjectSize: ObjectSizeProfile{
+			Distribution: SizeDistributionLogNormal,
+			Size:         256 * units.KiB,
+			Min:          4 * units.KiB,
+			Max:          4 * units.MiB,
+			Sigma:        1,
+		},
+		Mix: OpsMix{
+			Read:   60,
+			Write:  35,
+			Delete: 5,
+		},
+		OpsPerSecond: 20,
+		Concurrency:  4,
+		MaxFootprint: 1 * units.GiB,
+	}
+}
+
+// LoadWorkloadProfile reads the profile from YAML file. The options missing
+// in the file are taken from the default profile.
+func LoadWorkloadProfile(path string) (WorkloadProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return WorkloadProfile{}, err
+	}
+
+	p := DefaultWorkloadProfile()
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil {
+		return WorkloadProfile{}, fmt.Errorf("error parsing workload profile %s: %w", path, err)
+	}
+
+	return p, p.Validate()
+}
+
+func (p WorkloadProfile) Validate() error {
+	if err := p.ObjectSize.Validate(); err != nil {
+		return err
+	}
+
+	if p.Mix.Read+p.Mix.Write+p.Mix.Delete == 0 {
+		return errors.New("at least one operation must have non-zero weight in the mix")
+	}
+
+	if p.OpsPerSecond < 0 {
+		return errors.New("ops per second must not be negative")
+	}
+
+	if p.Concurrency < 1 {
+		return errors.New("concurrency must be at least 1")
+	}
+
+	if p.MaxFootprint <= 0 {
+		return errors.New("max footprint must be positive")
+	}
+
+	return nil
+}
+
+func (p WorkloadProfile) String() string {
+	rate := "unlimited"
+	if p.OpsPerSecond > 0 {
+		rate = fmt.Sprintf("%g ops/s", p.OpsPerSecond)
+	}
+
+	return fmt.Sprintf(
+		"%s object sizes, read/write/delete mix %d/%d/%d, %s, %d workers, footprint up to %s",
+		p.ObjectSize.Distribution, p.Mix.Read, p.Mix.Write, p.Mix.Delete, rate, p.Concurrency, p.MaxFootprint,
+	)
+}
+
+func (p ObjectSizeProfile) Validate() error {
+	switch p.Distribution {
+	case SizeDistributionFixed:
+		if p.Size <= 0 {
+			return errors.New("object size must be positive for fixed distribution")
+		}
+	case SizeDistributionUniform, SizeDistributionLogNormal:
+		if p.Min <= 0 || p.Max < p.Min {
+			return fmt.Errorf("object size bounds must be positive with max >= min for %s distribution", p.Distribution)
+		}
+
+		if p.Distribution == SizeDistributionLogNormal && (p.Size <= 0 || p.Sigma < 0) {
+			return errors.New("object size median must be positive and sigma must not be negative for lognormal distribution")
+		}
+	default:
+		return fmt.Errorf("unknown object size distribution: `%s`", p.Distribution)
+	}
+
+	return nil
+}
+
+// sample returns the random object size in bytes
+func (p ObjectSizeProfile) sample(rnd random.Random) int {
+	switch p.Distribution {
+	case SizeDistributionUniform:
+		return int(p.Min) + rnd.Intn(int(p.Max-p.Min)+1)
+	case SizeDistributionLogNormal:
+		size := math.Exp(math.Log(float64(p.Size)) + p.Sigma*normFloat64(rnd))
+		return int(math.Min(math.Max(size, float64(p.Min)), float64(p.Max)))
+	}
+
+	return int(p.Size)
+}
+
+func (m OpsMix) pick(rnd random.Random) ioOp {
+	n := uint(rnd.Intn(int(m.Read + m.Write + m.Delete)))
+
+	switch {
+	case n < m.Read:
+		return ioOpRead
+	case n < m.Read+m.Write:
+		return ioOpWrite
+	}
+
+	return ioOpDelete
+}
+
+// normFloat64 returns the standard normally distributed value using
+// Box-Muller transform since random.Random has no NormFloat64()
+func normFloat64(rnd random.Random) float64 {
+	u1 := 1 - rnd.Float64()
+	u2 := rnd.Float64()
+
+	return math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+}
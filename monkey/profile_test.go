@@ -0,0 +1,114 @@
+package monkey
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/units"
+	"github.com/stretchr/testify/require"
+	"github.com/teran/go-collection/random"
+)
+
+func TestLoadWorkloadProfile(t *testing.T) {
+	r := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "profile.yaml")
+	r.NoError(os.WriteFile(path, []byte(`
+object_size:
+  distribution: uniform
+  min: 1KiB
+  max: 1MiB
+mix:
+  read: 1
+  write: 1
+  delete: 0
+concurrency: 8
+max_footprint: 10GiB
+`), 0o644))
+
+	p, err := LoadWorkloadProfile(path)
+	r.NoError(err)
+
+	expected := DefaultWorkloadProfile()
+	expected.ObjectSize.Distribution = SizeDistributionUniform
+	expected.ObjectSize.Min = units.KiB
+	expected.ObjectSize.Max = units.MiB
+	expected.Mix = OpsMix{Read: 1, Write: 1}
+	expected.Concurrency = 8
+	expected.MaxFootprint = 10 * units.GiB
+	r.Equal(expected, p)
+}
+
+func TestLoadWorkloadProfileUnknownField(t *testing.T) {
+	r := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "profile.yaml")
+	r.NoError(os.WriteFile(path, []byte("object_sizes: {}\n"), 0o644))
+
+	_, err := LoadWorkloadProfile(path)
+	r.Error(err)
+}
+
+func TestWorkloadProfileValidate(t *testing.T) {
+	r := require.New(t)
+
+	r.NoError(DefaultWorkloadProfile().Validate())
+
+	tcs := map[string]func(p *WorkloadProfile){
+		"unknown distribution": func(p *WorkloadProfile) { p.ObjectSize.Distribution = "normal" },
+		"fixed without size":   func(p *WorkloadProfile) { p.ObjectSize = ObjectSizeProfile{Distribution: SizeDistributionFixed} },
+		"max below min":        func(p *WorkloadProfile) { p.ObjectSize.Max = p.ObjectSize.Min - 1 },
+		"negative sigma":       func(p *WorkloadProfile) { p.ObjectSize.Sigma = -1 },
+		"empty mix":            func(p *WorkloadProfile) { p.Mix = OpsMix{} },
+		"negative rate":        func(p *WorkloadProfile) { p.OpsPerSecond = -1 },
+		"no workers":           func(p *WorkloadProfile) { p.Concurrency = 0 },
+		"no footprint":         func(p *WorkloadProfile) { p.MaxFootprint = 0 },
+	}
+
+	for name, fn := range tcs {
+		t.Run(name, func(t *testing.T) {
+			p := DefaultWorkloadProfile()
+			fn(&p)
+			require.Error(t, p.Validate())
+		})
+	}
+}
+
+func TestObjectSizeProfileSample(t *testing.T) {
+	r := require.New(t)
+	rnd := random.GetRand()
+
+	fixed := ObjectSizeProfile{Distribution: SizeDistributionFixed, Size: 4 * units.KiB}
+	r.Equal(4096, fixed.sample(rnd))
+
+	uniform := ObjectSizeProfile{Distribution: SizeDistributionUniform, Min: units.KiB, Max: 2 * units.KiB}
+	lognormal := ObjectSizeProfile{Distribution: SizeDistributionLogNormal, Size: 64 * units.KiB, Min: units.KiB, Max: units.MiB, Sigma: 2}
+	for range 1000 {
+		v := uniform.sample(rnd)
+		r.GreaterOrEqual(v, 1024)
+		r.LessOrEqual(v, 2048)
+
+		v = lognormal.sample(rnd)
+		r.GreaterOrEqual(v, 1024)
+		r.LessOrEqual(v, 1024*1024)
+	}
+}
+
+func TestOpsMixPick(t *testing.T) {
+	r := require.New(t)
+
+	rnd := random.NewMock()
+	defer rnd.AssertExpectations(t)
+
+	mix := OpsMix{Read: 2, Write: 1, Delete: 1}
+
+	rnd.On("Intn", 4).Return(1).Once()
+	r.Equal(ioOpRead, mix.pick(rnd))
+
+	rnd.On("Intn", 4).Return(2).Once()
+	r.Equal(ioOpWrite, mix.pick(rnd))
+
+	rnd.On("Intn", 4).Return(3).Once()
+	r.Equal(ioOpDelete, mix.pick(rnd))
+}
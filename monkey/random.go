@@ -0,0 +1,124 @@
+package monkey
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+
+	"github.com/teran/go-collection/random"
+)
+
+var _ random.Random = (*lockedRandom)(nil)
+
+// lockedRandom makes random.Random safe to be shared by the fusses and the
+// background IO workers
+type lockedRandom struct {
+	mutex *sync.Mutex
+	rnd   random.Random
+}
+
+func newLockedRandom(rnd random.Random) random.Random {
+	return &lockedRandom{
+		mutex: &sync.Mutex{},
+		rnd:   rnd,
+	}
+}
+
+func (r *lockedRandom) Int63() int64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rnd.Int63()
+}
+
+func (r *lockedRandom) Uint32() uint32 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rnd.Uint32()
+}
+
+func (r *lockedRandom) Int31() int32 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rnd.Int31()
+}
+
+func (r *lockedRandom) Int() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rnd.Int()
+}
+
+func (r *lockedRandom) Int63n(n int64) int64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rnd.Int63n(n)
+}
+
+func (r *lockedRandom) Int31n(n int32) int32 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rnd.Int31n(n)
+}
+
+func (r *lockedRandom) Intn(n int) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rnd.Intn(n)
+}
+
+func (r *lockedRandom) Float64() float64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rnd.Float64()
+}
+
+func (r *lockedRandom) Float32() float32 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rnd.Float32()
+}
+
+func (r *lockedRandom) Perm(n int) []int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rnd.Perm(n)
+}
+
+func (r *lockedRandom) Read(buf []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rnd.Read(buf)
+}
+
+// countingSource counts the values drawn from the seeded source so the stream
+// position could be checkpointed and restored by replaying the source
+type countingSource struct {
+	src   rand.Source64
+	draws atomic.Uint64
+}
+
+func newCountingSource(seed int64, draws uint64) *countingSource {
+	src := rand.NewSource(seed).(rand.Source64)
+	for range draws {
+		src.Uint64()
+	}
+
+	s := &countingSource{src: src}
+	s.draws.Store(draws)
+	return s
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws.Add(1)
+	return s.src.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.draws.Add(1)
+	return s.src.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.draws.Store(0)
+	s.src.Seed(seed)
+}
@@ -0,0 +1,72 @@
+package monkey
+
+import (
+	"context"
+	"time"
+)
+
+// rateLimiter spreads the operations of all the workers evenly to keep the
+// target rate. nil limiter doesn't limit anything.
+type rateLimiter struct {
+	ticker *time.Ticker
+}
+
+func newRateLimiter(opsPerSecond float64) *rateLimiter {
+	if opsPerSecond <= 0 {
+		return nil
+	}
+
+	return &rateLimiter{
+		ticker: time.NewTicker(max(time.Duration(float64(time.Second)/opsPerSecond), time.Microsecond)),
+	}
+}
+
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-l.ticker.C:
+		return nil
+	}
+}
+
+func (l *rateLimiter) stop() {
+	if l != nil {
+		l.ticker.Stop()
+	}
+}
+
+// idleBackoff slows down the unlimited worker which has nothing to do or
+// keeps failing so it doesn't spin. The delay doubles on every idle operation
+// and is reset by the successful one.
+type idleBackoff struct {
+	delay time.Duration
+}
+
+const (
+	minIdleBackoff = 10 * time.Millisecond
+	maxIdleBackoff = time.Second
+)
+
+func (b *idleBackoff) wait(ctx context.Context, ok bool) error {
+	if ok {
+		b.delay = 0
+		return nil
+	}
+
+	b.delay = min(max(2*b.delay, minIdleBackoff), maxIdleBackoff)
+
+	t := time.NewTimer(b.delay)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
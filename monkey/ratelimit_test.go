@@ -0,0 +1,61 @@
+package monkey
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdleBackoff(t *testing.T) {
+	r := require.New(t)
+
+	ctx := context.Background()
+	b := &idleBackoff{}
+
+	r.NoError(b.wait(ctx, false))
+	r.Equal(minIdleBackoff, b.delay)
+
+	r.NoError(b.wait(ctx, false))
+	r.Equal(2*minIdleBackoff, b.delay)
+
+	r.NoError(b.wait(ctx, true))
+	r.Zero(b.delay)
+
+	b.delay = maxIdleBackoff
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	r.ErrorIs(b.wait(ctx, false), context.Canceled)
+	r.Equal(maxIdleBackoff, b.delay)
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	r := require.New(t)
+
+	l := newRateLimiter(0)
+	r.Nil(l)
+	r.NoError(l.wait(context.Background()))
+	l.stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	r.ErrorIs(l.wait(ctx), context.DeadlineExceeded)
+}
+
+func TestRunWorkloadOpsBacksOff(t *testing.T) {
+	r := require.New(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	calls := 0
+	r.NoError(runWorkloadOps(ctx, func() bool {
+		calls++
+		return false
+	}))
+
+	// 10ms, 20ms and 40ms back off delays fit into 100ms
+	r.LessOrEqual(calls, 5)
+}
@@ -0,0 +1,54 @@
+package monkey
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// teePrinter prints to the underlying printer and writes the same output to
+// the report file
+type teePrinter struct {
+	Printer
+	w io.Writer
+}
+
+func (p *teePrinter) Println(a ...any) {
+	p.Printer.Println(a...)
+	_, _ = fmt.Fprintln(p.w, a...)
+}
+
+func (p *teePrinter) Printf(format string, a ...any) {
+	p.Printer.Printf(format, a...)
+	_, _ = fmt.Fprintf(p.w, format, a...)
+}
+
+// openReport creates the file the final report is persisted to and makes the
+// printer write to it as well
+func (m *monkey) openReport() (*os.File, error) {
+	fp, err := os.Create(filepath.Join(m.reportDir, m.bgIOPoolName+".report.txt"))
+	if err != nil {
+		return nil, err
+	}
+
+	m.printer = &teePrinter{Printer: m.printer, w: fp}
+
+	return fp, nil
+}
+
+func (m *monkey) closeReport(fp *os.File) {
+	if p, ok := m.printer.(*teePrinter); ok {
+		m.printer = p.Printer
+	}
+
+	if err := fp.Close(); err != nil {
+		log.Warnf("error writing report file: %s", err)
+		return
+	}
+
+	m.printer.Println()
+	m.printer.Printf("The report is saved to %s\n", fp.Name())
+}
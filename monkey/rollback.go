@@ -0,0 +1,131 @@
+package monkey
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+const (
+	rollbackTimeout       = 2 * time.Minute
+	rollbackRetryInterval = time.Second
+)
+
+type rollbackAction string
+
+const (
+	rollbackActionSetConfig       rollbackAction = "set-config"
+	rollbackActionRemoveConfig    rollbackAction = "remove-config"
+	rollbackActionBlocklistRemove rollbackAction = "blocklist-remove"
+	rollbackActionSetAuthCaps     rollbackAction = "set-auth-caps"
+	rollbackActionUnsetFlag       rollbackAction = "unset-flag"
+	rollbackActionStartDaemon     rollbackAction = "start-daemon"
+	rollbackActionRestartDaemon   rollbackAction = "restart-daemon"
+	rollbackActionSetZoneReadOnly rollbackAction = "set-zone-read-only"
+	rollbackActionUnlockRBDImage  rollbackAction = "unlock-rbd-image"
+)
+
+// rollback describes how to revert a change made by a fuss. It's a plain data
+// instead of a closure so it could be printed and persisted.
+type rollback struct {
+	Action rollbackAction `json:"action"`
+	Args   []string       `json:"args"`
+
+	// Delay makes the change time-boxed: it's reverted once the delay is
+	// passed instead of the end of the game
+	Delay time.Duration `json:"delay,omitempty"`
+
+	// Stall marks the change freezing the cluster IO till it's reverted so
+	// the freeze duration is reported in the stats
+	Stall bool `json:"stall,omitempty"`
+}
+
+type pendingRollback struct {
+	Rollback rollback  `json:"rollback"`
+	Since    time.Time `json:"since"`
+
+	// Failed is set once the rollback is failed and is being retried
+	Failed bool `json:"failed,omitempty"`
+}
+
+func (p pendingRollback) isDue(now time.Time) bool {
+	return p.Rollback.Delay > 0 && !now.Before(p.Since.Add(p.Rollback.Delay))
+}
+
+func (r rollback) String() string {
+	return strings.Join(append([]string{string(r.Action)}, r.Args...), " ")
+}
+
+func (r rollback) apply(ctx context.Context, c drivers.Cluster) error {
+	switch r.Action {
+	case rollbackActionSetConfig:
+		if err := r.expectArgs(3); err != nil {
+			return err
+		}
+		return c.SetConfig(ctx, r.Args[0], r.Args[1], r.Args[2])
+	case rollbackActionRemoveConfig:
+		if err := r.expectArgs(2); err != nil {
+			return err
+		}
+		return c.RemoveConfig(ctx, r.Args[0], r.Args[1])
+	case rollbackActionBlocklistRemove:
+		if err := r.expectArgs(1); err != nil {
+			return err
+		}
+		return c.BlocklistRemove(ctx, r.Args[0])
+	case rollbackActionUnsetFlag:
+		if err := r.expectArgs(1); err != nil {
+			return err
+		}
+		return c.UnsetFlag(ctx, ceph.Flag(r.Args[0]))
+	case rollbackActionStartDaemon:
+		if err := r.expectArgs(1); err != nil {
+			return err
+		}
+		return c.StartDaemon(ctx, r.Args[0])
+	case rollbackActionRestartDaemon:
+		if err := r.expectArgs(1); err != nil {
+			return err
+		}
+		return c.RestartDaemon(ctx, r.Args[0])
+	case rollbackActionSetZoneReadOnly:
+		if err := r.expectArgs(2); err != nil {
+			return err
+		}
+		readOnly, err := strconv.ParseBool(r.Args[1])
+		if err != nil {
+			return err
+		}
+		return c.SetZoneReadOnly(ctx, r.Args[0], readOnly)
+	case rollbackActionUnlockRBDImage:
+		if err := r.expectArgs(3); err != nil {
+			return err
+		}
+		return c.UnlockRBDImage(ctx, r.Args[0], r.Args[1], r.Args[2])
+	case rollbackActionSetAuthCaps:
+		// entity name followed by `<service> <cap>` pairs
+		if len(r.Args) == 0 || len(r.Args)%2 != 1 {
+			return fmt.Errorf("rollback action %s expects entity and caps pairs, got %d arguments", r.Action, len(r.Args))
+		}
+
+		caps := map[string]string{}
+		for i := 1; i < len(r.Args); i += 2 {
+			caps[r.Args[i]] = r.Args[i+1]
+		}
+		return c.SetAuthCaps(ctx, r.Args[0], caps)
+	}
+
+	return fmt.Errorf("unknown rollback action: %s", r.Action)
+}
+
+func (r rollback) expectArgs(n int) error {
+	if len(r.Args) != n {
+		return fmt.Errorf("rollback action %s expects %d arguments, got %d", r.Action, n, len(r.Args))
+	}
+	return nil
+}
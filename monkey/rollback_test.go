@@ -0,0 +1,143 @@
+package monkey
+
+import (
+	"errors"
+	"time"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+)
+
+func (s *cephTestSuite) TestRollbackApply() {
+	s.cluster.On("SetConfig", "osd.3", "osd_max_backfills", "3").Return(nil).Once()
+	s.cluster.On("RemoveConfig", "global", "osd_recovery_sleep").Return(nil).Once()
+
+	err := rollback{Action: rollbackActionSetConfig, Args: []string{"osd.3", "osd_max_backfills", "3"}}.apply(s.ctx, s.cluster)
+	s.Require().NoError(err)
+
+	err = rollback{Action: rollbackActionRemoveConfig, Args: []string{"global", "osd_recovery_sleep"}}.apply(s.ctx, s.cluster)
+	s.Require().NoError(err)
+
+	s.cluster.On("BlocklistRemove", "10.0.0.1:0/0").Return(nil).Once()
+	err = rollback{Action: rollbackActionBlocklistRemove, Args: []string{"10.0.0.1:0/0"}}.apply(s.ctx, s.cluster)
+	s.Require().NoError(err)
+
+	s.cluster.On("SetAuthCaps", "client.test", map[string]string{"mon": "allow r", "osd": "allow rw"}).Return(nil).Once()
+	err = rollback{Action: rollbackActionSetAuthCaps, Args: []string{"client.test", "mon", "allow r", "osd", "allow rw"}}.apply(s.ctx, s.cluster)
+	s.Require().NoError(err)
+
+	err = rollback{Action: rollbackActionSetAuthCaps, Args: []string{"client.test", "mon"}}.apply(s.ctx, s.cluster)
+	s.Require().Error(err)
+
+	s.cluster.On("UnsetFlag", ceph.FlagPause).Return(nil).Once()
+	err = rollback{Action: rollbackActionUnsetFlag, Args: []string{"pause"}}.apply(s.ctx, s.cluster)
+	s.Require().NoError(err)
+
+	s.cluster.On("RestartDaemon", "rgw.objects.ceph01.tqwbbd").Return(nil).Once()
+	err = rollback{Action: rollbackActionRestartDaemon, Args: []string{"rgw.objects.ceph01.tqwbbd"}}.apply(s.ctx, s.cluster)
+	s.Require().NoError(err)
+
+	s.cluster.On("SetZoneReadOnly", "default", false).Return(nil).Once()
+	err = rollback{Action: rollbackActionSetZoneReadOnly, Args: []string{"default", "false"}}.apply(s.ctx, s.cluster)
+	s.Require().NoError(err)
+
+	err = rollback{Action: rollbackActionSetZoneReadOnly, Args: []string{"default", "maybe"}}.apply(s.ctx, s.cluster)
+	s.Require().Error(err)
+
+	s.cluster.On("UnlockRBDImage", "pool", "image", "chaos-monkey-lock").Return(nil).Once()
+	err = rollback{Action: rollbackActionUnlockRBDImage, Args: []string{"pool", "image", "chaos-monkey-lock"}}.apply(s.ctx, s.cluster)
+	s.Require().NoError(err)
+
+	err = rollback{Action: rollbackActionRemoveConfig, Args: []string{"global"}}.apply(s.ctx, s.cluster)
+	s.Require().Error(err)
+
+	err = rollback{Action: "unknown"}.apply(s.ctx, s.cluster)
+	s.Require().Error(err)
+}
+
+func (s *cephTestSuite) TestPendingRollbackIsDue() {
+	now := time.Now()
+
+	s.Require().False(pendingRollback{Since: now.Add(-time.Hour)}.isDue(now))
+	s.Require().False(pendingRollback{Rollback: rollback{Delay: time.Minute}, Since: now.Add(-30 * time.Second)}.isDue(now))
+	s.Require().True(pendingRollback{Rollback: rollback{Delay: time.Minute}, Since: now.Add(-time.Minute)}.isDue(now))
+}
+
+func (s *cephTestSuite) TestDoDueRollbacks() {
+	now := time.Now()
+	stats := NewStats()
+	m := &monkey{
+		cluster: s.cluster,
+		stats:   stats,
+		printer: NewPrinter(),
+		rollbacks: []pendingRollback{
+			{Rollback: rollback{Action: rollbackActionRemoveConfig, Args: []string{"global", "osd_max_backfills"}}, Since: now},
+			{Rollback: rollback{Action: rollbackActionUnsetFlag, Args: []string{"pause"}, Delay: time.Minute, Stall: true}, Since: now.Add(-2 * time.Minute)},
+			{Rollback: rollback{Action: rollbackActionUnsetFlag, Args: []string{"noup"}, Delay: time.Hour}, Since: now},
+		},
+	}
+
+	s.cluster.On("UnsetFlag", ceph.FlagPause).Return(nil).Once()
+	m.doDueRollbacks(s.ctx)
+	s.Require().Len(m.rollbacks, 2)
+	s.Require().Equal(uint64(1), stats.Dump().StallsCountTotal)
+
+	s.cluster.On("UnsetFlag", ceph.FlagNoUp).Return(nil).Once()
+	s.cluster.On("RemoveConfig", "global", "osd_max_backfills").Return(nil).Once()
+	m.doRollbacks(s.ctx)
+	s.Require().Empty(m.rollbacks)
+	s.Require().Len(m.journal, 3)
+}
+
+func (s *cephTestSuite) TestDoDueRollbacksRetriesFailed() {
+	now := time.Now()
+	m := &monkey{
+		cluster: s.cluster,
+		stats:   NewStats(),
+		printer: NewPrinter(),
+		rollbacks: []pendingRollback{
+			{Rollback: rollback{Action: rollbackActionUnsetFlag, Args: []string{"pause"}, Delay: time.Minute, Stall: true}, Since: now.Add(-2 * time.Minute)},
+		},
+	}
+
+	s.cluster.On("UnsetFlag", ceph.FlagPause).Return(errors.New("timed out")).Twice()
+	m.doDueRollbacks(s.ctx)
+	m.doDueRollbacks(s.ctx)
+	s.Require().Len(m.rollbacks, 1)
+	s.Require().True(m.rollbacks[0].Failed)
+	s.Require().Zero(m.stats.Dump().StallsCountTotal)
+
+	// the failure is journaled once
+	s.Require().Len(m.journal, 1)
+	s.Require().Equal("failed to revert temporary change: unset-flag pause, retrying", m.journal[0].Entry)
+
+	s.cluster.On("UnsetFlag", ceph.FlagPause).Return(nil).Once()
+	m.doDueRollbacks(s.ctx)
+	s.Require().Empty(m.rollbacks)
+	s.Require().Equal(uint64(1), m.stats.Dump().StallsCountTotal)
+	s.Require().Equal("reverted temporary change: unset-flag pause", m.journal[1].Entry)
+}
+
+func (s *cephTestSuite) TestDoRollbacksRetriesFailed() {
+	now := time.Now()
+	m := &monkey{
+		cluster: s.cluster,
+		stats:   NewStats(),
+		printer: NewPrinter(),
+		rollbacks: []pendingRollback{
+			{Rollback: rollback{Action: rollbackActionUnsetFlag, Args: []string{"noup"}}, Since: now},
+			{Rollback: rollback{Action: rollbackActionUnsetFlag, Args: []string{"nodown"}}, Since: now},
+		},
+	}
+
+	s.cluster.On("UnsetFlag", ceph.FlagNoDown).Return(nil).Once()
+	s.cluster.On("UnsetFlag", ceph.FlagNoUp).Return(errors.New("timed out")).Once()
+	s.cluster.On("UnsetFlag", ceph.FlagNoUp).Return(nil).Once()
+
+	m.doRollbacks(s.ctx)
+	s.Require().Empty(m.rollbacks)
+	s.Require().Equal([]string{
+		"reverted temporary change: unset-flag nodown",
+		"failed to revert temporary change: unset-flag noup, retrying",
+		"reverted temporary change: unset-flag noup",
+	}, []string{m.journal[0].Entry, m.journal[1].Entry, m.journal[2].Entry})
+}
@@ -0,0 +1,341 @@
+package monkey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+// maxScenarioSteps limits the number of steps executed during the single
+// scenario run so the scenario looping with `goto` is over at some point
+const maxScenarioSteps = 100
+
+// Scenario is the scripted cascade of the fusses and actions
+type Scenario struct {
+	Name        string         `yaml:"name"`
+	Description string         `yaml:"description"`
+	Steps       []ScenarioStep `yaml:"steps"`
+}
+
+// ScenarioStep is performed once its wait is over and the condition holds.
+// The step with wait only is just a pause.
+type ScenarioStep struct {
+	// Label names the step to jump to with Goto
+	Label string `yaml:"label"`
+
+	// Wait is the delay since the previous step
+	Wait time.Duration `yaml:"wait"`
+
+	// When is checked right before the step, the step is skipped if the
+	// condition doesn't hold
+	When *ScenarioCondition `yaml:"when"`
+
+	// Fuss is the ID of the fuss from the catalogue to trigger
+	Fuss string `yaml:"fuss"`
+
+	// Action is the name of the parametrized scenario action to perform.
+	// Params values could refer the variables set by the previous actions
+	// as ${name}.
+	Action string            `yaml:"action"`
+	Params map[string]string `yaml:"params"`
+
+	// Goto is the label of the step to continue with once the step is
+	// performed
+	Goto string `yaml:"goto"`
+}
+
+// ScenarioCondition holds if all the set fields match the cluster health
+type ScenarioCondition struct {
+	HealthStatus string `yaml:"health_status"`
+	HealthCheck  string `yaml:"health_check"`
+}
+
+// LoadScenario reads and validates the scenario YAML file
+func LoadScenario(path string) (Scenario, error) {
+	fp, err := os.Open(path)
+	if err != nil {
+		return Scenario{}, err
+	}
+	defer func() { _ = fp.Close() }()
+
+	s := Scenario{}
+	dec := yaml.NewDecoder(fp)
+	dec.KnownFields(true)
+	if err := dec.Decode(&s); err != nil {
+		return Scenario{}, fmt.Errorf("error decoding scenario %s: %w", path, err)
+	}
+
+	if err := s.Validate(); err != nil {
+		return Scenario{}, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+func (s Scenario) Validate() error {
+	if s.Name == "" {
+		return errors.New("name must be set")
+	}
+
+	if len(s.Steps) == 0 {
+		return errors.New("at least one step must be set")
+	}
+
+	labels := map[string]bool{}
+	for _, step := range s.Steps {
+		if step.Label == "" {
+			continue
+		}
+
+		if labels[step.Label] {
+			return fmt.Errorf("duplicate label `%s`", step.Label)
+		}
+		labels[step.Label] = true
+	}
+
+	for i, step := range s.Steps {
+		if err := step.validate(labels); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (s ScenarioStep) validate(labels map[string]bool) error {
+	if s.Wait < 0 {
+		return errors.New("wait must not be negative")
+	}
+
+	switch {
+	case s.Fuss != "" && s.Action != "":
+		return errors.New("only one of fuss and action could be set")
+	case s.Fuss != "":
+		if _, ok := fussByID(s.Fuss); !ok {
+			return fmt.Errorf("unknown fuss `%s`", s.Fuss)
+		}
+	case s.Action != "":
+		a, ok := scenarioActions[s.Action]
+		if !ok {
+			return fmt.Errorf("unknown action `%s`", s.Action)
+		}
+
+		for _, p := range a.required {
+			if s.Params[p] == "" {
+				return fmt.Errorf("action `%s` requires `%s` param", s.Action, p)
+			}
+		}
+
+		if a.validate != nil {
+			if err := a.validate(s.Params); err != nil {
+				return fmt.Errorf("action `%s`: %w", s.Action, err)
+			}
+		}
+	case s.Wait == 0:
+		return errors.New("one of fuss, action or wait must be set")
+	}
+
+	if s.Goto != "" && !labels[s.Goto] {
+		return fmt.Errorf("unknown label `%s` in goto", s.Goto)
+	}
+
+	return nil
+}
+
+// fussID returns the ID of the fuss the step is allowed by in the policy
+func (s ScenarioStep) fussID() string {
+	if s.Action != "" {
+		return scenarioActions[s.Action].fussID
+	}
+	return s.Fuss
+}
+
+func (s ScenarioStep) String() string {
+	if s.Fuss != "" {
+		f, _ := fussByID(s.Fuss)
+		return f.name
+	}
+
+	keys := []string{}
+	for k := range s.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	params := []string{}
+	for _, k := range keys {
+		params = append(params, k+"="+s.Params[k])
+	}
+
+	return strings.TrimSpace(s.Action + " " + strings.Join(params, " "))
+}
+
+func (c *ScenarioCondition) holds(ctx context.Context, cl drivers.Cluster) (bool, error) {
+	if c == nil {
+		return true, nil
+	}
+
+	health, err := cl.GetHealth(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if c.HealthStatus != "" && health.Status != c.HealthStatus {
+		return false, nil
+	}
+
+	if c.HealthCheck != "" {
+		if _, ok := health.Checks[c.HealthCheck]; !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// scenarioRun is the state of the scenario being played. It's advanced from
+// the game loop so no locking is needed.
+type scenarioRun struct {
+	scenario Scenario
+	pc       int
+	due      time.Time
+	executed int
+
+	// vars are set by the actions and substituted into the params of the
+	// following steps
+	vars map[string]string
+}
+
+func newScenarioRun(s Scenario, now time.Time) *scenarioRun {
+	return &scenarioRun{
+		scenario: s,
+		due:      now.Add(s.Steps[0].Wait),
+		vars:     map[string]string{},
+	}
+}
+
+// startScenario picks the random scenario to be played
+func (m *monkey) startScenario() {
+	s := m.scenarios[m.rnd.Intn(len(m.scenarios))]
+	m.scenario = newScenarioRun(s, time.Now())
+
+	m.journal = append(m.journal, JournalEntry{
+		Timestamp: time.Now(),
+		Entry:     fmt.Sprintf("scenario %s started", s.Name),
+	})
+}
+
+// doDueScenarioSteps performs the steps of the current scenario which wait
+// is over
+func (m *monkey) doDueScenarioSteps(ctx context.Context) {
+	for m.scenario != nil && !time.Now().Before(m.scenario.due) {
+		run := m.scenario
+
+		if run.executed >= maxScenarioSteps {
+			m.finishScenario(fmt.Sprintf("scenario %s aborted after %d steps", run.scenario.Name, run.executed))
+			return
+		}
+
+		m.doScenarioStep(ctx, run)
+
+		if run.pc >= len(run.scenario.Steps) {
+			m.finishScenario(fmt.Sprintf("scenario %s finished", run.scenario.Name))
+			return
+		}
+
+		run.due = time.Now().Add(run.scenario.Steps[run.pc].Wait)
+	}
+}
+
+func (m *monkey) finishScenario(entry string) {
+	m.scenario = nil
+
+	m.journal = append(m.journal, JournalEntry{
+		Timestamp: time.Now(),
+		Entry:     entry,
+	})
+}
+
+func (m *monkey) doScenarioStep(ctx context.Context, run *scenarioRun) {
+	step := run.scenario.Steps[run.pc]
+	run.executed++
+	run.pc++
+
+	// the wait-only step does nothing but could still jump once it's waited
+	waitOnly := step.Fuss == "" && step.Action == ""
+
+	ok, err := step.When.holds(ctx, m.cluster)
+	if err != nil {
+		log.Debugf("error checking scenario step condition: %s", err)
+	}
+
+	if !ok {
+		if !waitOnly {
+			m.journal = append(m.journal, JournalEntry{
+				Timestamp: time.Now(),
+				Entry:     fmt.Sprintf("scenario %s: skipped %s", run.scenario.Name, step),
+			})
+		}
+		return
+	}
+
+	if step.Goto != "" {
+		for i, s := range run.scenario.Steps {
+			if s.Label == step.Goto {
+				run.pc = i
+				break
+			}
+		}
+	}
+
+	if waitOnly {
+		return
+	}
+
+	if step.Params != nil {
+		params := map[string]string{}
+		for k, v := range step.Params {
+			params[k] = os.Expand(v, func(name string) string { return run.vars[name] })
+		}
+		step.Params = params
+	}
+
+	entry := JournalEntry{
+		Timestamp: time.Now(),
+		Entry:     fmt.Sprintf("scenario %s: %s", run.scenario.Name, step),
+	}
+	m.journal = append(m.journal, entry)
+
+	if m.blind {
+		m.newIncident(step.fussID(), entry)
+	}
+
+	fussCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), fussTimeout)
+	defer cancel()
+
+	var rollbacks []rollback
+	if step.Fuss != "" {
+		f, _ := fussByID(step.Fuss)
+		rollbacks, err = f.fn(fussCtx, m.cluster, m.rnd)
+	} else {
+		rollbacks, err = scenarioActions[step.Action].fn(fussCtx, m.cluster, m.rnd, step.Params, run.vars)
+	}
+
+	m.addRollbacks(rollbacks...)
+	if err != nil {
+		log.Debugf("error performing scenario step `%s`: %s", step, err)
+		m.journal = append(m.journal, JournalEntry{
+			Timestamp: time.Now(),
+			Entry:     fmt.Sprintf("cluster operations are failing (during %s)", step),
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package monkey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/teran/go-collection/random"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+type scenarioActionFn func(ctx context.Context, c drivers.Cluster, rnd random.Random, params, vars map[string]string) ([]rollback, error)
+
+type scenarioAction struct {
+	fn       scenarioActionFn
+	required []string
+
+	// validate checks the params once the scenario is loaded, nil if any
+	// value is accepted
+	validate func(params map[string]string) error
+
+	// fussID is the fuss from the catalogue the action is allowed by in the
+	// policy
+	fussID string
+}
+
+// scenarioActions are the parametrized counterparts of the fusses so the
+// scenario could choose the target and share it between the steps
+var scenarioActions = map[string]scenarioAction{
+	"set-flag": {
+		fn:       setFlagAction,
+		required: []string{"flag"},
+		validate: validateFlagParam,
+		fussID:   "set-flag",
+	},
+	"unset-flag": {
+		fn:       unsetFlagAction,
+		required: []string{"flag"},
+		validate: validateFlagParam,
+		fussID:   "unset-flag",
+	},
+	"stop-osds": {
+		fn:       stopOSDsAction,
+		validate: validateCountParam,
+		fussID:   "stop-osd",
+	},
+	"drain-host": {
+		fn:       drainHostAction,
+		required: []string{"host"},
+		fussID:   "drain-host",
+	},
+	"set-config": {
+		fn:       setConfigAction,
+		required: []string{"who", "name", "value"},
+		fussID:   "set-config-option",
+	},
+}
+
+func validateFlagParam(params map[string]string) error {
+	if !slices.Contains(cephFlags, ceph.Flag(params["flag"])) {
+		return fmt.Errorf("unknown flag `%s`", params["flag"])
+	}
+	return nil
+}
+
+func validateCountParam(params map[string]string) error {
+	v, ok := params["count"]
+	if !ok {
+		return nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return fmt.Errorf("invalid count `%s`", v)
+	}
+	return nil
+}
+
+// setFlagAction sets the `flag`, the time-boxed flags are unset after the
+// random freeze window just like the ones set by the fuss
+func setFlagAction(ctx context.Context, c drivers.Cluster, rnd random.Random, params, _ map[string]string) ([]rollback, error) {
+	return setFlag(ctx, c, rnd, ceph.Flag(params["flag"]))
+}
+
+func unsetFlagAction(ctx context.Context, c drivers.Cluster, _ random.Random, params, _ map[string]string) ([]rollback, error) {
+	return nil, c.UnsetFlag(ctx, ceph.Flag(params["flag"]))
+}
+
+// stopOSDsAction stops `count` (1 by default) OSD daemons on the `host` or on
+// the random one. The host and the stopped OSDs are saved to `host` and
+// `osds` variables.
+func stopOSDsAction(ctx context.Context, c drivers.Cluster, rnd random.Random, params, vars map[string]string) ([]rollback, error) {
+	count := 1
+	if v, ok := params["count"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid count `%s`", v)
+		}
+		count = n
+	}
+
+	osds, err := c.GetOSDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byHost := map[string][]uint64{}
+	for _, osd := range osds {
+		byHost[osd.HostName] = append(byHost[osd.HostName], osd.ID)
+	}
+
+	host := params["host"]
+	if host == "" {
+		hosts := []string{}
+		for h := range byHost {
+			hosts = append(hosts, h)
+		}
+
+		if len(hosts) == 0 {
+			return nil, errors.New("no OSDs are present in the cluster")
+		}
+
+		slices.Sort(hosts)
+		host = hosts[rnd.Intn(len(hosts))]
+	}
+
+	ids := byHost[host]
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no OSDs are present on host %s", host)
+	}
+
+	stopped := []string{}
+	for _, i := range rnd.Perm(len(ids))[:min(count, len(ids))] {
+		if err := c.StopOSDDaemon(ctx, ids[i]); err != nil {
+			return nil, err
+		}
+		stopped = append(stopped, strconv.FormatUint(ids[i], 10))
+	}
+
+	vars["host"] = host
+	vars["osds"] = strings.Join(stopped, ",")
+
+	return nil, nil
+}
+
+func drainHostAction(ctx context.Context, c drivers.Cluster, _ random.Random, params, _ map[string]string) ([]rollback, error) {
+	return nil, c.DrainHost(ctx, params["host"])
+}
+
+func setConfigAction(ctx context.Context, c drivers.Cluster, _ random.Random, params, _ map[string]string) ([]rollback, error) {
+	return setConfig(ctx, c, params["who"], params["name"], params["value"])
+}
@@ -0,0 +1,270 @@
+package monkey
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+)
+
+const hostMaintenanceScenario = `name: host maintenance gone wrong
+description: the host is put to maintenance the wrong way
+steps:
+  - action: set-flag
+    params:
+      flag: noout
+  - action: stop-osds
+    params:
+      count: "2"
+  - wait: 60s
+  - action: drain-host
+    params:
+      host: ${host}
+`
+
+func (s *cephTestSuite) TestLoadScenario() {
+	path := filepath.Join(s.T().TempDir(), "scenario.yaml")
+	s.Require().NoError(os.WriteFile(path, []byte(hostMaintenanceScenario), 0o600))
+
+	sc, err := LoadScenario(path)
+	s.Require().NoError(err)
+	s.Require().Equal(Scenario{
+		Name:        "host maintenance gone wrong",
+		Description: "the host is put to maintenance the wrong way",
+		Steps: []ScenarioStep{
+			{Action: "set-flag", Params: map[string]string{"flag": "noout"}},
+			{Action: "stop-osds", Params: map[string]string{"count": "2"}},
+			{Wait: 60 * time.Second},
+			{Action: "drain-host", Params: map[string]string{"host": "${host}"}},
+		},
+	}, sc)
+}
+
+func (s *cephTestSuite) TestScenarioValidate() {
+	type testCase struct {
+		name     string
+		scenario Scenario
+		err      string
+	}
+
+	tcs := []testCase{
+		{
+			name:     "no name",
+			scenario: Scenario{Steps: []ScenarioStep{{Fuss: "set-flag"}}},
+			err:      "name must be set",
+		},
+		{
+			name:     "no steps",
+			scenario: Scenario{Name: "test"},
+			err:      "at least one step must be set",
+		},
+		{
+			name:     "unknown fuss",
+			scenario: Scenario{Name: "test", Steps: []ScenarioStep{{Fuss: "format-disks"}}},
+			err:      "step 1: unknown fuss `format-disks`",
+		},
+		{
+			name:     "unknown action",
+			scenario: Scenario{Name: "test", Steps: []ScenarioStep{{Action: "format-disks"}}},
+			err:      "step 1: unknown action `format-disks`",
+		},
+		{
+			name:     "missing param",
+			scenario: Scenario{Name: "test", Steps: []ScenarioStep{{Action: "drain-host"}}},
+			err:      "step 1: action `drain-host` requires `host` param",
+		},
+		{
+			name:     "unknown flag",
+			scenario: Scenario{Name: "test", Steps: []ScenarioStep{{Action: "set-flag", Params: map[string]string{"flag": "nothing"}}}},
+			err:      "step 1: action `set-flag`: unknown flag `nothing`",
+		},
+		{
+			name:     "invalid count",
+			scenario: Scenario{Name: "test", Steps: []ScenarioStep{{Action: "stop-osds", Params: map[string]string{"count": "0"}}}},
+			err:      "step 1: action `stop-osds`: invalid count `0`",
+		},
+		{
+			name:     "both fuss and action",
+			scenario: Scenario{Name: "test", Steps: []ScenarioStep{{Fuss: "set-flag", Action: "stop-osds"}}},
+			err:      "step 1: only one of fuss and action could be set",
+		},
+		{
+			name:     "empty step",
+			scenario: Scenario{Name: "test", Steps: []ScenarioStep{{Fuss: "set-flag"}, {}}},
+			err:      "step 2: one of fuss, action or wait must be set",
+		},
+		{
+			name:     "unknown label",
+			scenario: Scenario{Name: "test", Steps: []ScenarioStep{{Fuss: "set-flag", Goto: "start"}}},
+			err:      "step 1: unknown label `start` in goto",
+		},
+		{
+			name: "duplicate label",
+			scenario: Scenario{Name: "test", Steps: []ScenarioStep{
+				{Label: "start", Fuss: "set-flag"},
+				{Label: "start", Fuss: "unset-flag"},
+			}},
+			err: "duplicate label `start`",
+		},
+	}
+
+	for _, tc := range tcs {
+		s.Run(tc.name, func() {
+			s.Require().EqualError(tc.scenario.Validate(), tc.err)
+		})
+	}
+}
+
+func (s *cephTestSuite) TestScenarioHostMaintenance() {
+	m := s.newTestMonkey()
+	m.scenarios = []Scenario{{
+		Name: "host maintenance gone wrong",
+		Steps: []ScenarioStep{
+			{Action: "set-flag", Params: map[string]string{"flag": "noout"}},
+			{Action: "stop-osds", Params: map[string]string{"count": "2"}},
+			{Wait: time.Millisecond},
+			{Action: "drain-host", Params: map[string]string{"host": "${host}"}},
+		},
+	}}
+
+	s.rnd.On("Intn", 1).Return(0).Once()
+	s.cluster.On("SetFlag", ceph.FlagNoOut).Return(nil).Once()
+	s.cluster.On("GetOSDs").Return([]ceph.OSD{
+		{ID: 0, HostName: "ceph01"},
+		{ID: 1, HostName: "ceph02"},
+		{ID: 2, HostName: "ceph02"},
+		{ID: 3, HostName: "ceph02"},
+	}, nil).Once()
+	s.rnd.On("Intn", 2).Return(1).Once()
+	s.rnd.On("Perm", 3).Return([]int{2, 0, 1}).Once()
+	s.cluster.On("StopOSDDaemon", uint64(3)).Return(nil).Once()
+	s.cluster.On("StopOSDDaemon", uint64(1)).Return(nil).Once()
+
+	m.startScenario()
+	m.doDueScenarioSteps(s.ctx)
+	s.Require().NotNil(m.scenario)
+	s.Require().Equal(map[string]string{"host": "ceph02", "osds": "3,1"}, m.scenario.vars)
+
+	// the host is drained once the pause is over
+	s.cluster.On("DrainHost", "ceph02").Return(nil).Once()
+
+	time.Sleep(2 * time.Millisecond)
+	m.doDueScenarioSteps(s.ctx)
+	s.Require().Nil(m.scenario)
+
+	entries := []string{}
+	for _, j := range m.journal {
+		entries = append(entries, j.Entry)
+	}
+	s.Require().Equal([]string{
+		"scenario host maintenance gone wrong started",
+		"scenario host maintenance gone wrong: set-flag flag=noout",
+		"scenario host maintenance gone wrong: stop-osds count=2",
+		"scenario host maintenance gone wrong: drain-host host=ceph02",
+		"scenario host maintenance gone wrong finished",
+	}, entries)
+}
+
+func (s *cephTestSuite) TestScenarioSetTimeBoxedFlag() {
+	m := s.newTestMonkey()
+	m.scenario = newScenarioRun(Scenario{
+		Name: "freeze",
+		Steps: []ScenarioStep{
+			{Action: "set-flag", Params: map[string]string{"flag": "pause"}},
+		},
+	}, time.Now())
+
+	s.cluster.On("SetFlag", ceph.FlagPause).Return(nil).Once()
+	s.rnd.On("Int63n", int64(maxFreezeWindow-minFreezeWindow)).Return(int64(30 * time.Second)).Once()
+
+	m.doDueScenarioSteps(s.ctx)
+	s.Require().Nil(m.scenario)
+
+	// the cluster is never left paused by the scenario
+	s.Require().Len(m.rollbacks, 1)
+	s.Require().Equal(rollback{
+		Action: rollbackActionUnsetFlag,
+		Args:   []string{"pause"},
+		Delay:  time.Minute,
+		Stall:  true,
+	}, m.rollbacks[0].Rollback)
+}
+
+func (s *cephTestSuite) TestScenarioConditionAndGoto() {
+	m := s.newTestMonkey()
+	m.scenario = newScenarioRun(Scenario{
+		Name: "loop",
+		Steps: []ScenarioStep{
+			{Label: "start", Fuss: "set-flag", When: &ScenarioCondition{HealthStatus: "HEALTH_OK"}, Goto: "start"},
+		},
+	}, time.Now())
+
+	s.cluster.On("GetHealth").Return(ceph.Health{Status: "HEALTH_OK"}, nil).Times(maxScenarioSteps)
+	s.rnd.On("Intn", len(cephFlags)).Return(3).Times(maxScenarioSteps)
+	s.cluster.On("SetFlag", ceph.FlagNoOut).Return(nil).Times(maxScenarioSteps)
+
+	m.doDueScenarioSteps(s.ctx)
+	s.Require().Nil(m.scenario)
+	s.Require().Equal("scenario loop aborted after 100 steps", m.journal[len(m.journal)-1].Entry)
+}
+
+func (s *cephTestSuite) TestScenarioWaitOnlyGoto() {
+	m := s.newTestMonkey()
+	m.scenario = newScenarioRun(Scenario{
+		Name: "poll",
+		Steps: []ScenarioStep{
+			{Label: "start", Fuss: "set-flag"},
+			{Wait: time.Nanosecond, When: &ScenarioCondition{HealthStatus: "HEALTH_OK"}, Goto: "start"},
+		},
+	}, time.Now())
+
+	s.rnd.On("Intn", len(cephFlags)).Return(3).Twice()
+	s.cluster.On("SetFlag", ceph.FlagNoOut).Return(nil).Twice()
+	s.cluster.On("GetHealth").Return(ceph.Health{Status: "HEALTH_OK"}, nil).Once()
+	s.cluster.On("GetHealth").Return(ceph.Health{Status: "HEALTH_WARN"}, nil).Once()
+
+	// the wait-only step jumps back once and lets the scenario finish then
+	for m.scenario != nil {
+		time.Sleep(time.Millisecond)
+		m.doDueScenarioSteps(s.ctx)
+	}
+	s.Require().Equal("scenario poll finished", m.journal[len(m.journal)-1].Entry)
+}
+
+func (s *cephTestSuite) TestScenarioConditionNotHolds() {
+	m := s.newTestMonkey()
+	m.scenario = newScenarioRun(Scenario{
+		Name: "test",
+		Steps: []ScenarioStep{
+			{Fuss: "set-flag", When: &ScenarioCondition{HealthCheck: "OSD_DOWN"}},
+		},
+	}, time.Now())
+
+	s.cluster.On("GetHealth").Return(ceph.Health{
+		Status: "HEALTH_WARN",
+		Checks: map[string]ceph.HealthCheck{"OSDMAP_FLAGS": {}},
+	}, nil).Once()
+
+	m.doDueScenarioSteps(s.ctx)
+	s.Require().Nil(m.scenario)
+	s.Require().Equal("scenario test: skipped set random flag", m.journal[0].Entry)
+}
+
+func (s *cephTestSuite) TestSafetyPolicyCheckScenario() {
+	sc := Scenario{
+		Name: "test",
+		Steps: []ScenarioStep{
+			{Action: "set-flag", Params: map[string]string{"flag": "noout"}},
+			{Wait: time.Minute},
+			{Fuss: "drain-host"},
+		},
+	}
+
+	s.Require().NoError(SafetyPolicy{}.checkScenario(sc))
+	s.Require().NoError(SafetyPolicy{AllowedFusses: []string{"set-flag", "drain-host"}}.checkScenario(sc))
+	s.Require().EqualError(
+		SafetyPolicy{AllowedFusses: []string{"set-flag"}}.checkScenario(sc),
+		"scenario test: step `drain random host` requires `drain-host` fuss which is not allowed",
+	)
+}
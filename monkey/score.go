@@ -0,0 +1,32 @@
+package monkey
+
+const (
+	maxScore    = 100
+	hintPenalty = 10
+)
+
+// score is what the trainee earns for the game
+type score struct {
+	hintsUsed int
+
+	// incidents and correct are counted in the blind mode only
+	incidents int
+	correct   int
+}
+
+// points are the share of the incidents diagnosed correctly in the blind mode
+// or the full score otherwise, less the hints used
+func (s score) points() int {
+	base := maxScore
+	if s.incidents > 0 {
+		base = maxScore * s.correct / s.incidents
+	}
+	return max(base-s.hintsUsed*hintPenalty, 0)
+}
+
+func (m *monkey) printScore() {
+	m.printer.Printf("Score: %d of %d (%d hints used, -%d each)\n", m.score.points(), maxScore, m.score.hintsUsed, hintPenalty)
+	if m.blind {
+		m.printer.Printf("Incidents diagnosed correctly: %d of %d\n", m.score.correct, m.score.incidents)
+	}
+}
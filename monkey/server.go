@@ -0,0 +1,569 @@
+package monkey
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/teran/go-collection/random"
+
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+// subscriberBuffer is the number of the events the slow subscriber could lag
+// behind before it's dropped so it doesn't slow the game down
+const subscriberBuffer = 256
+
+//go:embed web
+var webFS embed.FS
+
+// GameConfig is the game started with the HTTP API
+type GameConfig struct {
+	// FSID acknowledges the cluster could be damaged the same way as
+	// --i-understand-this-destroys-data does for `run`
+	FSID string `json:"fsid"`
+
+	// Interval and Duration fall back to the level defaults if not set,
+	// example: 2m
+	Interval string `json:"fuss_interval"`
+	Duration string `json:"game_duration"`
+	Level    Level  `json:"level"`
+
+	// Seed of the random the fusses are picked with, random if not set. The
+	// string is accepted as well since JavaScript numbers lose int64
+	// precision.
+	Seed json.Number `json:"seed"`
+}
+
+func (c GameConfig) pacing() (time.Duration, time.Duration, []Option, error) {
+	if c.FSID == "" {
+		return 0, 0, nil, errors.New("fsid must be set")
+	}
+
+	opts := []Option{}
+
+	var interval, duration time.Duration
+	for _, v := range []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"fuss_interval", c.Interval, &interval},
+		{"game_duration", c.Duration, &duration},
+	} {
+		if v.value == "" {
+			continue
+		}
+
+		d, err := time.ParseDuration(v.value)
+		if err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid %s: %w", v.name, err)
+		}
+		*v.dst = d
+	}
+
+	if c.Level != "" {
+		lp, err := GetLevelProfile(c.Level)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+
+		if interval == 0 {
+			interval = lp.Interval
+		}
+
+		if duration == 0 {
+			duration = lp.Duration
+		}
+
+		opts = append(opts, WithLevel(lp))
+	}
+
+	if interval == 0 || duration == 0 {
+		return 0, 0, nil, errors.New("fuss_interval and game_duration are required unless level is set")
+	}
+
+	var seed int64
+	if c.Seed != "" {
+		v, err := c.Seed.Int64()
+		if err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid seed: %w", err)
+		}
+		seed = v
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	opts = append(opts, WithSeed(seed))
+
+	return interval, duration, opts, nil
+}
+
+// Server runs the games on the cluster one at a time with the HTTP API and
+// serves the web UI on top of it
+type Server struct {
+	cluster drivers.Cluster
+	rnd     random.Random
+	opts    []Option
+	mux     *http.ServeMux
+
+	mutex sync.Mutex
+	game  *serverGame
+}
+
+type serverGame struct {
+	monkey   *monkey
+	feed     *gameFeed
+	cancel   context.CancelFunc
+	controls chan controlCommand
+	done     chan struct{}
+}
+
+// NewServer creates the server playing the games on the cluster. The options
+// are applied to every game, e.g. the safety policy.
+func NewServer(cluster drivers.Cluster, rnd random.Random, opts ...Option) *Server {
+	s := &Server{
+		cluster: cluster,
+		rnd:     rnd,
+		opts:    opts,
+		mux:     http.NewServeMux(),
+	}
+
+	web, err := fs.Sub(webFS, "web")
+	if err != nil {
+		panic(err)
+	}
+
+	s.mux.Handle("GET /", http.FileServerFS(web))
+	s.mux.HandleFunc("GET /api/health", s.handleHealth)
+	s.mux.HandleFunc("POST /api/game", s.handleStart)
+	s.mux.HandleFunc("GET /api/game", s.handleStatus)
+	s.mux.HandleFunc("GET /api/game/events", s.handleEvents)
+	s.mux.HandleFunc("GET /api/game/stats", s.handleStats)
+	s.mux.HandleFunc("POST /api/game/pause", s.handleControl("pause"))
+	s.mux.HandleFunc("POST /api/game/resume", s.handleControl("resume"))
+	s.mux.HandleFunc("POST /api/game/abort", s.handleAbort)
+	s.mux.HandleFunc("GET /api/game/report", s.handleReport)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Close aborts the game in progress and waits for it to finish so the
+// temporary changes are reverted
+func (s *Server) Close() {
+	s.mutex.Lock()
+	g := s.game
+	s.mutex.Unlock()
+
+	if g != nil {
+		g.cancel()
+		<-g.done
+	}
+}
+
+// current returns the last game started, nil if none
+func (s *Server) current() *serverGame {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.game
+}
+
+func (g *serverGame) running() bool {
+	select {
+	case <-g.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (s *Server) handleStart(w http.ResponseWriter, r *http.Request) {
+	cfg := GameConfig{}
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("error decoding game config: %s", err))
+		return
+	}
+
+	interval, duration, opts, err := cfg.pacing()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.game != nil && s.game.running() {
+		writeError(w, http.StatusConflict, "the game is already in progress")
+		return
+	}
+
+	feed := newGameFeed()
+	controls := make(chan controlCommand)
+
+	opts = append(append([]Option{}, s.opts...), opts...)
+	opts = append(opts,
+		WithConfirmer(NewFSIDConfirmer(s.cluster, cfg.FSID)),
+		WithUI(feed),
+	)
+
+	m := newMonkey(s.cluster, s.rnd, feed, NewStats(), interval, duration, opts...)
+	m.controlC = controls
+
+	// the game outlives the request so it's only canceled with abort
+	ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+
+	g := &serverGame{
+		monkey:   m,
+		feed:     feed,
+		cancel:   cancel,
+		controls: controls,
+		done:     make(chan struct{}),
+	}
+	s.game = g
+
+	go func() {
+		defer close(g.done)
+		defer cancel()
+
+		err := m.Run(ctx)
+		if err != nil {
+			log.Warnf("error playing the game: %s", err)
+		}
+
+		// Run is over so the monkey state is safe to be read here
+		feed.end(m.journal, m.played, err)
+	}()
+
+	writeJSON(w, http.StatusCreated, s.status(g))
+}
+
+// gameStatus is the game summary returned by the API
+type gameStatus struct {
+	Pool string `json:"pool"`
+
+	// Seed is the string for the same reason GameConfig accepts it
+	Seed string `json:"seed"`
+
+	Running bool       `json:"running"`
+	Played  bool       `json:"played"`
+	State   *GameState `json:"state"`
+}
+
+func (s *Server) status(g *serverGame) gameStatus {
+	st := gameStatus{
+		Pool:    g.monkey.bgIOPoolName,
+		Seed:    strconv.FormatInt(g.monkey.seed, 10),
+		Running: g.running(),
+		State:   g.feed.lastState(),
+	}
+
+	if !st.Running {
+		st.Played = g.monkey.played
+	}
+
+	return st
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	g := s.current()
+	if g == nil {
+		writeError(w, http.StatusNotFound, "no game is started")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.status(g))
+}
+
+func (s *Server) handleStats(w http.ResponseWriter, _ *http.Request) {
+	g := s.current()
+	if g == nil {
+		writeError(w, http.StatusNotFound, "no game is started")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, g.monkey.stats.Dump())
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, fetchClusterState(r.Context(), s.cluster))
+}
+
+// handleControl passes the command to the game loop the same way the control
+// socket does and returns the reply
+func (s *Server) handleControl(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		g := s.current()
+		if g == nil || !g.running() {
+			writeError(w, http.StatusConflict, "no game is in progress")
+			return
+		}
+
+		out := &strings.Builder{}
+		cmd := controlCommand{
+			consoleCommand: consoleCommand{name: name},
+			out:            newWriterPrinter(out),
+			done:           make(chan struct{}),
+		}
+
+		select {
+		case g.controls <- cmd:
+		case <-g.done:
+			writeError(w, http.StatusConflict, "no game is in progress")
+			return
+		case <-r.Context().Done():
+			return
+		}
+
+		select {
+		case <-cmd.done:
+		case <-r.Context().Done():
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]string{"message": strings.TrimSpace(out.String())})
+	}
+}
+
+func (s *Server) handleAbort(w http.ResponseWriter, _ *http.Request) {
+	g := s.current()
+	if g == nil || !g.running() {
+		writeError(w, http.StatusConflict, "no game is in progress")
+		return
+	}
+
+	// the game is interrupted the same way as with SIGINT: the changes are
+	// reverted and the report is written
+	g.cancel()
+	<-g.done
+
+	writeJSON(w, http.StatusOK, s.status(g))
+}
+
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	g := s.current()
+	if g == nil || g.running() || !g.monkey.played {
+		writeError(w, http.StatusNotFound, "no report, the game is not played yet")
+		return
+	}
+
+	path := filepath.Join(g.monkey.reportDir, g.monkey.bgIOPoolName+".report.txt")
+	if _, err := os.Stat(path); err != nil {
+		writeError(w, http.StatusNotFound, "no report, it's not saved")
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(path)))
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	http.ServeFile(w, r, path)
+}
+
+// handleEvents streams the game with the server-sent events: the game output
+// and the journal entries since the game start, the live state every second
+// and the end of the game
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	g := s.current()
+	if g == nil {
+		writeError(w, http.StatusNotFound, "no game is started")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	events, unsubscribe := g.feed.subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+
+			data, err := json.Marshal(e.data)
+			if err != nil {
+				log.Warnf("error encoding %s event: %s", e.name, err)
+				continue
+			}
+
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.name, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Debugf("error writing response: %s", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
+
+type feedEvent struct {
+	name string
+	data any
+}
+
+// gameFeed is the UI of the game played with the server. It fans the game
+// out to the event stream subscribers.
+type gameFeed struct {
+	mutex sync.Mutex
+
+	// history is replayed to the subscribers joined late, the states are not
+	// kept except the last one
+	history []feedEvent
+	state   *GameState
+	journal int
+	subs    map[chan feedEvent]struct{}
+	ended   bool
+}
+
+func newGameFeed() *gameFeed {
+	return &gameFeed{
+		subs: map[chan feedEvent]struct{}{},
+	}
+}
+
+func (f *gameFeed) Println(a ...any) {
+	f.output(fmt.Sprintln(a...))
+}
+
+func (f *gameFeed) Printf(format string, a ...any) {
+	f.output(fmt.Sprintf(format, a...))
+}
+
+func (f *gameFeed) output(s string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for _, line := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+		f.publish(feedEvent{name: "output", data: line}, true)
+	}
+}
+
+func (f *gameFeed) Update(s GameState) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.publishJournal(s.Journal)
+	f.state = &s
+	f.publish(feedEvent{name: "state", data: s}, false)
+}
+
+// Close is no-op since the report is streamed as the game output as well
+func (f *gameFeed) Close() {}
+
+func (f *gameFeed) lastState() *GameState {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.state
+}
+
+// end publishes the rest of the journal and the result of the game and closes
+// the subscriptions
+func (f *gameFeed) end(journal []JournalEntry, played bool, err error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.publishJournal(journal)
+
+	result := map[string]any{"played": played}
+	if err != nil {
+		result["error"] = err.Error()
+	}
+	f.publish(feedEvent{name: "end", data: result}, true)
+
+	f.ended = true
+	for ch := range f.subs {
+		close(ch)
+		delete(f.subs, ch)
+	}
+}
+
+func (f *gameFeed) publishJournal(journal []JournalEntry) {
+	for _, j := range journal[min(f.journal, len(journal)):] {
+		f.publish(feedEvent{name: "journal", data: j}, true)
+	}
+	f.journal = max(f.journal, len(journal))
+}
+
+// publish is called with the mutex held. The subscriber which buffer is full
+// is dropped.
+func (f *gameFeed) publish(e feedEvent, keep bool) {
+	if keep {
+		f.history = append(f.history, e)
+	}
+
+	for ch := range f.subs {
+		select {
+		case ch <- e:
+		default:
+			close(ch)
+			delete(f.subs, ch)
+		}
+	}
+}
+
+// subscribe returns the channel replaying the history and streaming the
+// events till the end of the game
+func (f *gameFeed) subscribe() (<-chan feedEvent, func()) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	ch := make(chan feedEvent, len(f.history)+subscriberBuffer)
+	for _, e := range f.history {
+		ch <- e
+	}
+
+	if f.ended {
+		close(ch)
+		return ch, func() {}
+	}
+
+	f.subs[ch] = struct{}{}
+
+	return ch, func() {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+
+		if _, ok := f.subs[ch]; ok {
+			close(ch)
+			delete(f.subs, ch)
+		}
+	}
+}
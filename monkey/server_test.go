@@ -0,0 +1,226 @@
+package monkey
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+)
+
+func (s *cephTestSuite) newTestServer() *httptest.Server {
+	profile := DefaultWorkloadProfile()
+	profile.Mix = OpsMix{Read: 1}
+	profile.Concurrency = 1
+
+	dir := s.T().TempDir()
+	srv := NewServer(s.cluster, s.rnd,
+		WithSafetyPolicy(SafetyPolicy{AllowedFSIDs: []string{testFSID}}),
+		WithWorkloadProfile(profile),
+		WithLedgerDir(dir),
+		WithReportDir(dir),
+	)
+
+	ts := httptest.NewServer(srv)
+	s.T().Cleanup(func() {
+		srv.Close()
+		ts.Close()
+	})
+
+	return ts
+}
+
+// expectServerGame sets the expectations for the game played with the server
+// which also watches the cluster for the UI
+func (s *cephTestSuite) expectServerGame() {
+	s.rnd.On("Uint32").Return(uint32(1)).Times(2)
+	s.expectGame()
+	s.cluster.On("GetFSID").Return(testFSID, nil).Maybe()
+	s.cluster.On("GetHealth").Return(ceph.Health{Status: "HEALTH_OK"}, nil).Maybe()
+	s.cluster.On("ListPGs").Return([]ceph.PGStat{{PGID: "1.0", State: "active+clean"}}, nil).Maybe()
+	s.cluster.On("GetOSDs").Return([]ceph.OSD{{ID: 0, State: []string{"up"}, Reweight: 1}}, nil).Maybe()
+}
+
+func (s *cephTestSuite) request(method, url, body string) (int, string) {
+	req, err := http.NewRequestWithContext(s.ctx, method, url, strings.NewReader(body))
+	s.Require().NoError(err)
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+
+	return resp.StatusCode, string(data)
+}
+
+// readEvents reads the event stream till its end and returns the events data
+// by name
+func (s *cephTestSuite) readEvents(url string) map[string][]string {
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, url, nil)
+	s.Require().NoError(err)
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	defer func() { _ = resp.Body.Close() }()
+
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.Require().Equal("text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := map[string][]string{}
+	name := ""
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			events[name] = append(events[name], strings.TrimPrefix(line, "data: "))
+		}
+	}
+
+	return events
+}
+
+func (s *cephTestSuite) TestServerGame() {
+	ts := s.newTestServer()
+
+	code, body := s.request(http.MethodGet, ts.URL+"/api/game", "")
+	s.Require().Equal(http.StatusNotFound, code)
+	s.Require().JSONEq(`{"error": "no game is started"}`, body)
+
+	code, body = s.request(http.MethodPost, ts.URL+"/api/game", `{"fsid": "`+testFSID+`"}`)
+	s.Require().Equal(http.StatusBadRequest, code)
+	s.Require().JSONEq(`{"error": "fuss_interval and game_duration are required unless level is set"}`, body)
+
+	code, _ = s.request(http.MethodPost, ts.URL+"/api/game", `{"fsid": "`+testFSID+`", "unknown": true}`)
+	s.Require().Equal(http.StatusBadRequest, code)
+
+	code, body = s.request(http.MethodPost, ts.URL+"/api/game", `{"fsid": "`+testFSID+`", "level": "beginner", "seed": "4.2"}`)
+	s.Require().Equal(http.StatusBadRequest, code)
+	s.Require().Contains(body, "invalid seed")
+
+	s.expectServerGame()
+
+	code, body = s.request(http.MethodPost, ts.URL+"/api/game",
+		`{"fsid": "`+testFSID+`", "fuss_interval": "30s", "game_duration": "1500ms", "seed": 42}`)
+	s.Require().Equal(http.StatusCreated, code, body)
+	s.Require().Contains(body, `"pool":"chaos-monkey-1"`)
+	s.Require().Contains(body, `"seed":"42"`)
+	s.Require().Contains(body, `"running":true`)
+
+	code, body = s.request(http.MethodPost, ts.URL+"/api/game",
+		`{"fsid": "`+testFSID+`", "fuss_interval": "30s", "game_duration": "1500ms"}`)
+	s.Require().Equal(http.StatusConflict, code)
+	s.Require().JSONEq(`{"error": "the game is already in progress"}`, body)
+
+	events := s.readEvents(ts.URL + "/api/game/events")
+	s.Require().Contains(events["output"], `"Game is over! Go check your cluster if it's still alive :-)"`)
+	s.Require().NotEmpty(events["state"])
+	s.Require().Equal([]string{`{"played":true}`}, events["end"])
+
+	journal := []string{}
+	for _, data := range events["journal"] {
+		j := JournalEntry{}
+		s.Require().NoError(json.Unmarshal([]byte(data), &j))
+		journal = append(journal, j.Entry)
+	}
+	s.Require().Contains(journal, "the fusses are picked with the random seeded with 42")
+
+	// the late subscriber gets the whole game replayed
+	s.Require().Equal(events["journal"], s.readEvents(ts.URL + "/api/game/events")["journal"])
+
+	code, body = s.request(http.MethodGet, ts.URL+"/api/game", "")
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().Contains(body, `"running":false`)
+	s.Require().Contains(body, `"played":true`)
+
+	code, body = s.request(http.MethodGet, ts.URL+"/api/game/stats", "")
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().Contains(body, `"LostWritesTotal":0`)
+
+	code, body = s.request(http.MethodGet, ts.URL+"/api/game/report", "")
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().Contains(body, "Game is over!")
+
+	code, _ = s.request(http.MethodPost, ts.URL+"/api/game/pause", "")
+	s.Require().Equal(http.StatusConflict, code)
+}
+
+func (s *cephTestSuite) TestServerPauseAndAbort() {
+	ts := s.newTestServer()
+	s.expectServerGame()
+
+	// the seed beyond the JavaScript number precision is sent as the string
+	code, body := s.request(http.MethodPost, ts.URL+"/api/game",
+		`{"fsid": "`+testFSID+`", "fuss_interval": "30s", "game_duration": "1m", "seed": "9007199254740993"}`)
+	s.Require().Equal(http.StatusCreated, code)
+	s.Require().Contains(body, `"seed":"9007199254740993"`)
+
+	code, _ = s.request(http.MethodGet, ts.URL+"/api/game/report", "")
+	s.Require().Equal(http.StatusNotFound, code)
+
+	// the command waits for the game loop to be started
+	code, body = s.request(http.MethodPost, ts.URL+"/api/game/pause", "")
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().JSONEq(`{"message": "The game is paused by the instructor"}`, body)
+
+	code, body = s.request(http.MethodPost, ts.URL+"/api/game/abort", "")
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().Contains(body, `"running":false`)
+	s.Require().Contains(body, `"played":true`)
+
+	code, body = s.request(http.MethodGet, ts.URL+"/api/game/report", "")
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().Contains(body, "Game is interrupted!")
+	s.Require().Contains(body, "the game is paused by the instructor")
+}
+
+func (s *cephTestSuite) TestServerNotConfirmed() {
+	ts := s.newTestServer()
+
+	s.rnd.On("Uint32").Return(uint32(1)).Times(2)
+	s.cluster.On("GetFSID").Return(testFSID, nil)
+
+	code, _ := s.request(http.MethodPost, ts.URL+"/api/game",
+		`{"fsid": "6e7c2b4f-0c3f-11f0-9d3c-525400a1b2c3", "fuss_interval": "30s", "game_duration": "1m"}`)
+	s.Require().Equal(http.StatusCreated, code)
+
+	events := s.readEvents(ts.URL + "/api/game/events")
+	s.Require().Contains(events["output"], `"Ain't brave enough for this? No worries, get back later"`)
+	s.Require().Equal([]string{`{"played":false}`}, events["end"])
+
+	code, _ = s.request(http.MethodGet, ts.URL+"/api/game/report", "")
+	s.Require().Equal(http.StatusNotFound, code)
+}
+
+func (s *cephTestSuite) TestServerHealth() {
+	ts := s.newTestServer()
+
+	s.cluster.On("GetHealth").Return(ceph.Health{Status: "HEALTH_OK"}, nil).Once()
+	s.cluster.On("ListPGs").Return([]ceph.PGStat{{PGID: "1.0", State: "active+clean"}}, nil).Once()
+	s.cluster.On("GetOSDs").Return([]ceph.OSD{{ID: 0, State: []string{"up"}, Reweight: 1}}, nil).Once()
+
+	code, body := s.request(http.MethodGet, ts.URL+"/api/health", "")
+	s.Require().Equal(http.StatusOK, code)
+
+	state := ClusterState{}
+	s.Require().NoError(json.Unmarshal([]byte(body), &state))
+	s.Require().Equal("HEALTH_OK", state.Health)
+	s.Require().Equal(map[string]int{"active+clean": 1}, state.PGStates)
+	s.Require().Equal(1, state.OSDsIn)
+}
+
+func (s *cephTestSuite) TestServerWebUI() {
+	ts := s.newTestServer()
+
+	code, body := s.request(http.MethodGet, ts.URL+"/", "")
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().Contains(body, "<title>ceph-chaos-monkey</title>")
+	s.Require().Contains(body, `new EventSource("api/game/events")`)
+}
@@ -0,0 +1,325 @@
+package monkey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+const (
+	RunnerLocal = "local"
+	RunnerSSH   = "ssh"
+)
+
+// Trainee is the roster entry with the settings to connect to the trainee
+// cluster
+type Trainee struct {
+	Name string `yaml:"name"`
+
+	// FSID acknowledges the trainee cluster could be damaged the same way as
+	// --i-understand-this-destroys-data does for the single game
+	FSID string `yaml:"fsid"`
+
+	// Runner is either `local` (default) or `ssh` running the binaries on
+	// Host
+	Runner string `yaml:"runner"`
+	Host   string `yaml:"host"`
+
+	CephBinary         string `yaml:"ceph_binary"`
+	RadosBinary        string `yaml:"rados_binary"`
+	RadosGWAdminBinary string `yaml:"radosgw_admin_binary"`
+	RBDBinary          string `yaml:"rbd_binary"`
+
+	Conf    string `yaml:"conf"`
+	Keyring string `yaml:"keyring"`
+}
+
+type Roster struct {
+	Trainees []Trainee `yaml:"trainees"`
+}
+
+// LoadRoster reads and validates the roster YAML file
+func LoadRoster(path string) (Roster, error) {
+	fp, err := os.Open(path)
+	if err != nil {
+		return Roster{}, err
+	}
+	defer func() { _ = fp.Close() }()
+
+	r := Roster{}
+	dec := yaml.NewDecoder(fp)
+	dec.KnownFields(true)
+	if err := dec.Decode(&r); err != nil {
+		return Roster{}, fmt.Errorf("error decoding roster %s: %w", path, err)
+	}
+
+	if err := r.Validate(); err != nil {
+		return Roster{}, fmt.Errorf("invalid roster %s: %w", path, err)
+	}
+
+	return r, nil
+}
+
+func (r Roster) Validate() error {
+	if len(r.Trainees) == 0 {
+		return errors.New("at least one trainee must be set")
+	}
+
+	names := map[string]bool{}
+	for i, t := range r.Trainees {
+		if t.Name == "" {
+			return fmt.Errorf("trainee %d: name must be set", i+1)
+		}
+
+		if names[t.Name] {
+			return fmt.Errorf("duplicate trainee `%s`", t.Name)
+		}
+		names[t.Name] = true
+
+		if t.FSID == "" {
+			return fmt.Errorf("trainee %s: fsid must be set", t.Name)
+		}
+
+		switch t.Runner {
+		case "", RunnerLocal:
+		case RunnerSSH:
+			if t.Host == "" {
+				return fmt.Errorf("trainee %s: host must be set for ssh runner", t.Name)
+			}
+		default:
+			return fmt.Errorf("trainee %s: unknown runner `%s`", t.Name, t.Runner)
+		}
+	}
+
+	return nil
+}
+
+// CheckAcknowledged makes sure the comma separated list of the fsids passed
+// with --i-understand-this-destroys-data covers every trainee cluster: the
+// roster alone doesn't acknowledge the data could be destroyed
+func (r Roster) CheckAcknowledged(acknowledged string) error {
+	fsids := map[string]bool{}
+	for _, fsid := range strings.Split(acknowledged, ",") {
+		fsids[strings.ToLower(strings.TrimSpace(fsid))] = true
+	}
+
+	for _, t := range r.Trainees {
+		if !fsids[strings.ToLower(strings.TrimSpace(t.FSID))] {
+			return fmt.Errorf("trainee %s: fsid %s is not acknowledged with --i-understand-this-destroys-data", t.Name, t.FSID)
+		}
+	}
+
+	return nil
+}
+
+// Player is the trainee with the cluster connected
+type Player struct {
+	Name      string
+	Cluster   drivers.Cluster
+	Confirmer Confirmer
+}
+
+type Session interface {
+	Run(ctx context.Context) error
+}
+
+type session struct {
+	players  []Player
+	printer  Printer
+	seed     int64
+	interval time.Duration
+	duration time.Duration
+	opts     []Option
+}
+
+// NewSession creates the session playing the same seeded game on the cluster
+// of every player at the same time. The options are applied to every game.
+func NewSession(players []Player, printer Printer, seed int64, interval, duration time.Duration, opts ...Option) Session {
+	return &session{
+		players:  players,
+		printer:  printer,
+		seed:     seed,
+		interval: interval,
+		duration: duration,
+		opts:     opts,
+	}
+}
+
+type game struct {
+	player Player
+	monkey *monkey
+	stats  Stats
+}
+
+func (s *session) Run(ctx context.Context) error {
+	games := []game{}
+	for i, p := range s.players {
+		stats := NewStats()
+		opts := append([]Option{
+			WithConfirmer(p.Confirmer),
+			WithSeed(s.seed),
+		}, s.opts...)
+
+		// every game has its own random for the background IO since the
+		// global one is not safe to be shared
+		rnd := rand.New(rand.NewSource(time.Now().UnixNano() + int64(i)))
+
+		games = append(games, game{
+			player: p,
+			monkey: newMonkey(p.Cluster, rnd, newPrefixedPrinter(s.printer, p.Name), stats, s.interval, s.duration, opts...),
+			stats:  stats,
+		})
+	}
+
+	s.printer.Printf("Playing the game seeded with %d on %d clusters\n", s.seed, len(games))
+
+	wg := &sync.WaitGroup{}
+	for _, g := range games {
+		wg.Add(1)
+		go func(g game) {
+			defer wg.Done()
+			if err := g.monkey.Run(ctx); err != nil {
+				log.Warnf("error playing the game of %s: %s", g.player.Name, err)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	results := []sessionResult{}
+	for _, g := range games {
+		results = append(results, s.result(context.WithoutCancel(ctx), g))
+	}
+
+	s.printLeaderboard(results)
+
+	return nil
+}
+
+// sessionResult is the state the trainee left the cluster in by the end of
+// the game
+type sessionResult struct {
+	name   string
+	played bool
+
+	health         string
+	pgsTotal       int
+	pgsActiveClean int
+
+	stats MeasurementValue
+	score int
+}
+
+func (s *session) result(ctx context.Context, g game) sessionResult {
+	r := sessionResult{
+		name:   g.player.Name,
+		played: g.monkey.played,
+		stats:  g.stats.Dump(),
+		score:  g.monkey.score.points(),
+		health: "unknown",
+	}
+
+	if !r.played {
+		return r
+	}
+
+	if health, err := g.player.Cluster.GetHealth(ctx); err != nil {
+		log.Debugf("error getting health of %s cluster: %s", r.name, err)
+	} else {
+		r.health = health.Status
+	}
+
+	if pgs, err := g.player.Cluster.ListPGs(ctx); err != nil {
+		log.Debugf("error listing PGs of %s cluster: %s", r.name, err)
+	} else {
+		r.pgsTotal = len(pgs)
+		for _, pg := range pgs {
+			if pg.IsActiveClean() {
+				r.pgsActiveClean++
+			}
+		}
+	}
+
+	return r
+}
+
+func (r sessionResult) activeCleanPercent() float64 {
+	if r.pgsTotal == 0 {
+		return 0
+	}
+	return float64(r.pgsActiveClean) / float64(r.pgsTotal) * 100
+}
+
+func healthRank(status string) int {
+	switch status {
+	case "HEALTH_OK":
+		return 0
+	case "HEALTH_WARN":
+		return 1
+	case "HEALTH_ERR":
+		return 2
+	default:
+		return 3
+	}
+}
+
+// rankResults orders the trainees by the data kept first, then by how well
+// the cluster is recovered and how well it served the IO during the game
+func rankResults(results []sessionResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+
+		switch {
+		case a.played != b.played:
+			return a.played
+		case a.stats.LostWritesTotal != b.stats.LostWritesTotal:
+			return a.stats.LostWritesTotal < b.stats.LostWritesTotal
+		case healthRank(a.health) != healthRank(b.health):
+			return healthRank(a.health) < healthRank(b.health)
+		case a.activeCleanPercent() != b.activeCleanPercent():
+			return a.activeCleanPercent() > b.activeCleanPercent()
+		case a.stats.WritesSuccessPercent != b.stats.WritesSuccessPercent:
+			return a.stats.WritesSuccessPercent > b.stats.WritesSuccessPercent
+		case a.stats.ReadsSuccessPercent != b.stats.ReadsSuccessPercent:
+			return a.stats.ReadsSuccessPercent > b.stats.ReadsSuccessPercent
+		default:
+			return a.stats.StallsDurationTotal < b.stats.StallsDurationTotal
+		}
+	})
+}
+
+func (s *session) printLeaderboard(results []sessionResult) {
+	rankResults(results)
+
+	s.printer.Println()
+	s.printer.Println("Leaderboard:")
+	s.printer.Printf("%-4s %-16s %-12s %-14s %-10s %-10s %-10s %-8s %s\n",
+		"#", "Trainee", "Health", "active+clean", "Lost", "Writes", "Reads", "Stalls", "Score")
+
+	for i, r := range results {
+		if !r.played {
+			s.printer.Printf("%-4d %-16s not played\n", i+1, r.name)
+			continue
+		}
+
+		s.printer.Printf("%-4d %-16s %-12s %-14s %-10d %-10s %-10s %-8s %d\n",
+			i+1, r.name, r.health,
+			fmt.Sprintf("%.1f%%", r.activeCleanPercent()),
+			r.stats.LostWritesTotal,
+			fmt.Sprintf("%.2f%%", r.stats.WritesSuccessPercent*100),
+			fmt.Sprintf("%.2f%%", r.stats.ReadsSuccessPercent*100),
+			r.stats.StallsDurationTotal.Truncate(time.Second),
+			r.score,
+		)
+	}
+}
@@ -0,0 +1,197 @@
+package monkey
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+)
+
+// bufferPrinter collects the output of the games played at the same time
+type bufferPrinter struct {
+	mutex sync.Mutex
+	buf   strings.Builder
+}
+
+func (p *bufferPrinter) Println(a ...any) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	_, _ = fmt.Fprintln(&p.buf, a...)
+}
+
+func (p *bufferPrinter) Printf(format string, a ...any) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	_, _ = fmt.Fprintf(&p.buf, format, a...)
+}
+
+func (p *bufferPrinter) String() string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.buf.String()
+}
+
+const testRoster = `trainees:
+  - name: alice
+    fsid: 5d6b1a3e-0c3f-11f0-9d3c-525400a1b2c3
+    conf: /etc/ceph/alice.conf
+    keyring: /etc/ceph/alice.keyring
+  - name: bob
+    fsid: 6e7c2b4f-0c3f-11f0-9d3c-525400a1b2c3
+    runner: ssh
+    host: bob-lab
+`
+
+func (s *cephTestSuite) TestLoadRoster() {
+	path := filepath.Join(s.T().TempDir(), "roster.yaml")
+	s.Require().NoError(os.WriteFile(path, []byte(testRoster), 0o600))
+
+	r, err := LoadRoster(path)
+	s.Require().NoError(err)
+	s.Require().Equal(Roster{Trainees: []Trainee{
+		{Name: "alice", FSID: testFSID, Conf: "/etc/ceph/alice.conf", Keyring: "/etc/ceph/alice.keyring"},
+		{Name: "bob", FSID: "6e7c2b4f-0c3f-11f0-9d3c-525400a1b2c3", Runner: RunnerSSH, Host: "bob-lab"},
+	}}, r)
+}
+
+func (s *cephTestSuite) TestRosterValidate() {
+	type testCase struct {
+		name   string
+		roster Roster
+		err    string
+	}
+
+	tcs := []testCase{
+		{
+			name:   "no trainees",
+			roster: Roster{},
+			err:    "at least one trainee must be set",
+		},
+		{
+			name:   "no name",
+			roster: Roster{Trainees: []Trainee{{FSID: testFSID}}},
+			err:    "trainee 1: name must be set",
+		},
+		{
+			name:   "duplicate name",
+			roster: Roster{Trainees: []Trainee{{Name: "alice", FSID: testFSID}, {Name: "alice", FSID: testFSID}}},
+			err:    "duplicate trainee `alice`",
+		},
+		{
+			name:   "no fsid",
+			roster: Roster{Trainees: []Trainee{{Name: "alice"}}},
+			err:    "trainee alice: fsid must be set",
+		},
+		{
+			name:   "ssh without host",
+			roster: Roster{Trainees: []Trainee{{Name: "alice", FSID: testFSID, Runner: RunnerSSH}}},
+			err:    "trainee alice: host must be set for ssh runner",
+		},
+		{
+			name:   "unknown runner",
+			roster: Roster{Trainees: []Trainee{{Name: "alice", FSID: testFSID, Runner: "telnet"}}},
+			err:    "trainee alice: unknown runner `telnet`",
+		},
+	}
+
+	for _, tc := range tcs {
+		s.Run(tc.name, func() {
+			s.Require().EqualError(tc.roster.Validate(), tc.err)
+		})
+	}
+}
+
+func (s *cephTestSuite) TestRosterCheckAcknowledged() {
+	r := Roster{Trainees: []Trainee{
+		{Name: "alice", FSID: "5d6b1a3e-0c3f-11f0-9d3c-525400a1b2c3"},
+		{Name: "bob", FSID: "6e7c2b4f-0c3f-11f0-9d3c-525400a1b2c3"},
+	}}
+
+	s.Require().NoError(r.CheckAcknowledged("5d6b1a3e-0c3f-11f0-9d3c-525400a1b2c3, 6E7C2B4F-0C3F-11F0-9D3C-525400A1B2C3"))
+
+	err := r.CheckAcknowledged("5d6b1a3e-0c3f-11f0-9d3c-525400a1b2c3")
+	s.Require().Error(err)
+	s.Require().Contains(err.Error(), "trainee bob")
+
+	s.Require().Error(r.CheckAcknowledged(""))
+}
+
+func (s *cephTestSuite) TestRankResults() {
+	results := []sessionResult{
+		{name: "not-played"},
+		{name: "lost-data", played: true, health: "HEALTH_OK", pgsTotal: 1, pgsActiveClean: 1, stats: MeasurementValue{LostWritesTotal: 1}},
+		{name: "warn", played: true, health: "HEALTH_WARN", pgsTotal: 1, pgsActiveClean: 1},
+		{name: "degraded", played: true, health: "HEALTH_OK", pgsTotal: 2, pgsActiveClean: 1},
+		{name: "clean", played: true, health: "HEALTH_OK", pgsTotal: 2, pgsActiveClean: 2},
+	}
+
+	rankResults(results)
+
+	names := []string{}
+	for _, r := range results {
+		names = append(names, r.name)
+	}
+	s.Require().Equal([]string{"clean", "degraded", "warn", "lost-data", "not-played"}, names)
+}
+
+func (s *cephTestSuite) TestPrefixedPrinter() {
+	buf := &bufferPrinter{}
+	p := newPrefixedPrinter(buf, "alice")
+
+	p.Println("line 1\nline 2")
+	p.Printf("%d\n", 3)
+	p.Println()
+
+	s.Require().Equal("[alice] line 1\n[alice] line 2\n[alice] 3\n[alice] \n", buf.String())
+}
+
+func (s *cephTestSuite) TestSessionRun() {
+	profile := DefaultWorkloadProfile()
+	profile.Mix = OpsMix{Read: 1}
+	profile.Concurrency = 1
+
+	dir := s.T().TempDir()
+	osds := []ceph.OSD{{ID: 0, KbUsed: 1024, KbAvailable: 1024}}
+
+	s.cluster.On("GetFSID").Return(testFSID, nil).Once()
+	s.cluster.On("GetHealth").Return(ceph.Health{Status: "HEALTH_OK"}, nil).Once()
+	s.cluster.On("GetOSDs").Return(osds, nil).Twice()
+	s.cluster.On("GetPools").Return([]ceph.Pool{}, nil).Once()
+	s.cluster.On("CreateDefaultPool", mock.Anything).Return(nil).Once()
+	s.cluster.On("EnablePoolApplication", mock.Anything, "chaos-monkey").Return(nil).Once()
+	s.cluster.On("CreateAuth", mock.Anything, mock.Anything).Return(ceph.AuthEntity{}, errors.New("access denied")).Once()
+
+	// the cluster is checked for the leaderboard once the game is over
+	s.cluster.On("GetHealth").Return(ceph.Health{Status: "HEALTH_WARN"}, nil).Once()
+	s.cluster.On("ListPGs").Return([]ceph.PGStat{{PGID: "1.0", State: "active+clean"}}, nil).Once()
+
+	buf := &bufferPrinter{}
+	sess := NewSession([]Player{
+		{Name: "alice", Cluster: s.cluster, Confirmer: confirmerFunc(func(string) bool { return true })},
+		{Name: "bob", Cluster: s.cluster, Confirmer: confirmerFunc(func(string) bool { return false })},
+	}, buf, 42, 30*time.Second, 200*time.Millisecond,
+		WithSafetyPolicy(SafetyPolicy{AllowedFSIDs: []string{testFSID}}),
+		WithWorkloadProfile(profile),
+		WithLedgerDir(dir),
+		WithReportDir(dir),
+	)
+
+	// bob refuses the game after the policy check
+	s.cluster.On("GetFSID").Return(testFSID, nil).Once()
+
+	s.Require().NoError(sess.Run(s.ctx))
+
+	out := buf.String()
+	s.Require().Contains(out, "Playing the game seeded with 42 on 2 clusters")
+	s.Require().Contains(out, "[alice] Game is over!")
+	s.Require().Contains(out, "[bob] Ain't brave enough for this?")
+	s.Require().Regexp(`1 +alice +HEALTH_WARN +100\.0%`, out)
+	s.Require().Regexp(`2 +bob +not played`, out)
+}
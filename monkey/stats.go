@@ -16,6 +16,19 @@ type MeasurementValue struct {
 	ReadsCountTotal      uint64
 	ReadsErrorsTotal     uint64
 	ReadsSuccessPercent  float64
+	StallsCountTotal     uint64
+	StallsDurationTotal  time.Duration
+	LostWritesTotal      uint64
+
+	AvgMetadataOpsLatency     time.Duration
+	MetadataOpsCountTotal     uint64
+	MetadataOpsErrorsTotal    uint64
+	MetadataOpsSuccessPercent float64
+
+	AvgDeletesLatency     time.Duration
+	DeletesCountTotal     uint64
+	DeletesErrorsTotal    uint64
+	DeletesSuccessPercent float64
 }
 
 type Stats interface {
@@ -23,6 +36,10 @@ type Stats interface {
 
 	ObserveWrite(time.Duration, error)
 	ObserveRead(time.Duration, error)
+	ObserveStall(time.Duration)
+	ObserveLostWrite()
+	ObserveMetadata(time.Duration, error)
+	ObserveDelete(time.Duration, error)
 }
 
 type stats struct {
@@ -36,6 +53,19 @@ type stats struct {
 
 	readsCountTotal  uint64
 	readsErrorsTotal uint64
+
+	stallsCountTotal    uint64
+	stallsDurationTotal time.Duration
+
+	lostWritesTotal uint64
+
+	totalMetadataOpsLatency time.Duration
+	metadataOpsCountTotal   uint64
+	metadataOpsErrorsTotal  uint64
+
+	totalDeletesLatency time.Duration
+	deletesCountTotal   uint64
+	deletesErrorsTotal  uint64
 }
 
 func NewStats() Stats {
@@ -48,18 +78,47 @@ func (s *stats) Dump() MeasurementValue {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	return MeasurementValue{
-		AvgWritesLatency: s.totalWritesLatency / time.Duration(s.writesCountTotal),
-		AvgReadsLatency:  s.totalReadsLatency / time.Duration(s.readsCountTotal),
+	v := MeasurementValue{
+		WritesCountTotal:  s.writesCountTotal,
+		WritesErrorsTotal: s.writesErrorsTotal,
+
+		ReadsCountTotal:  s.readsCountTotal,
+		ReadsErrorsTotal: s.readsErrorsTotal,
+
+		StallsCountTotal:    s.stallsCountTotal,
+		StallsDurationTotal: s.stallsDurationTotal,
+
+		LostWritesTotal: s.lostWritesTotal,
+
+		MetadataOpsCountTotal:  s.metadataOpsCountTotal,
+		MetadataOpsErrorsTotal: s.metadataOpsErrorsTotal,
+
+		DeletesCountTotal:  s.deletesCountTotal,
+		DeletesErrorsTotal: s.deletesErrorsTotal,
+	}
+
+	// the game could be over before any IO is done
+	if s.writesCountTotal > 0 {
+		v.AvgWritesLatency = s.totalWritesLatency / time.Duration(s.writesCountTotal)
+		v.WritesSuccessPercent = 1.0 - (float64(s.writesErrorsTotal) / float64(s.writesCountTotal))
+	}
 
-		WritesCountTotal:     s.writesCountTotal,
-		WritesErrorsTotal:    s.writesErrorsTotal,
-		WritesSuccessPercent: 1.0 - (float64(s.writesErrorsTotal) / float64(s.writesCountTotal)),
+	if s.readsCountTotal > 0 {
+		v.AvgReadsLatency = s.totalReadsLatency / time.Duration(s.readsCountTotal)
+		v.ReadsSuccessPercent = 1.0 - (float64(s.readsErrorsTotal) / float64(s.readsCountTotal))
+	}
+
+	if s.metadataOpsCountTotal > 0 {
+		v.AvgMetadataOpsLatency = s.totalMetadataOpsLatency / time.Duration(s.metadataOpsCountTotal)
+		v.MetadataOpsSuccessPercent = 1.0 - (float64(s.metadataOpsErrorsTotal) / float64(s.metadataOpsCountTotal))
+	}
 
-		ReadsCountTotal:     s.readsCountTotal,
-		ReadsErrorsTotal:    s.readsErrorsTotal,
-		ReadsSuccessPercent: 1.0 - (float64(s.readsErrorsTotal) / float64(s.readsCountTotal)),
+	if s.deletesCountTotal > 0 {
+		v.AvgDeletesLatency = s.totalDeletesLatency / time.Duration(s.deletesCountTotal)
+		v.DeletesSuccessPercent = 1.0 - (float64(s.deletesErrorsTotal) / float64(s.deletesCountTotal))
 	}
+
+	return v
 }
 
 func (s *stats) ObserveRead(latency time.Duration, err error) {
@@ -83,3 +142,40 @@ func (s *stats) ObserveWrite(latency time.Duration, err error) {
 		s.writesErrorsTotal++
 	}
 }
+
+func (s *stats) ObserveStall(duration time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.stallsCountTotal++
+	s.stallsDurationTotal += duration
+}
+
+func (s *stats) ObserveLostWrite() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.lostWritesTotal++
+}
+
+func (s *stats) ObserveMetadata(latency time.Duration, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.totalMetadataOpsLatency += latency
+	s.metadataOpsCountTotal++
+	if err != nil {
+		s.metadataOpsErrorsTotal++
+	}
+}
+
+func (s *stats) ObserveDelete(latency time.Duration, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.totalDeletesLatency += latency
+	s.deletesCountTotal++
+	if err != nil {
+		s.deletesErrorsTotal++
+	}
+}
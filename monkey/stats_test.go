@@ -21,6 +21,23 @@ func TestStats(t *testing.T) {
 	stats.ObserveRead(150*time.Millisecond, nil)
 	stats.ObserveRead(250*time.Millisecond, errors.New("read error"))
 
+	// Test ObserveStall
+	stats.ObserveStall(30 * time.Second)
+	stats.ObserveStall(90 * time.Second)
+
+	// Test ObserveLostWrite
+	stats.ObserveLostWrite()
+
+	// Test ObserveMetadata
+	stats.ObserveMetadata(10*time.Millisecond, nil)
+	stats.ObserveMetadata(30*time.Millisecond, nil)
+	stats.ObserveMetadata(50*time.Millisecond, nil)
+	stats.ObserveMetadata(70*time.Millisecond, errors.New("metadata error"))
+
+	// Test ObserveDelete
+	stats.ObserveDelete(20*time.Millisecond, nil)
+	stats.ObserveDelete(40*time.Millisecond, errors.New("delete error"))
+
 	// Dump stats and validate
 	result := stats.Dump()
 
@@ -33,5 +50,24 @@ func TestStats(t *testing.T) {
 		ReadsCountTotal:      2,
 		ReadsErrorsTotal:     1,
 		ReadsSuccessPercent:  0.50,
+		StallsCountTotal:     2,
+		StallsDurationTotal:  2 * time.Minute,
+		LostWritesTotal:      1,
+
+		AvgMetadataOpsLatency:     40 * time.Millisecond,
+		MetadataOpsCountTotal:     4,
+		MetadataOpsErrorsTotal:    1,
+		MetadataOpsSuccessPercent: 0.75,
+
+		AvgDeletesLatency:     30 * time.Millisecond,
+		DeletesCountTotal:     2,
+		DeletesErrorsTotal:    1,
+		DeletesSuccessPercent: 0.50,
 	}, result)
 }
+
+func TestStatsEmpty(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(MeasurementValue{}, NewStats().Dump())
+}
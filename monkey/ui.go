@@ -0,0 +1,168 @@
+package monkey
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+const clusterRefreshInterval = 5 * time.Second
+
+// GameState is the live state of the game shown by the UI
+type GameState struct {
+	At time.Time `json:"at"`
+
+	Level   Level         `json:"level"`
+	Paused  bool          `json:"paused"`
+	Played  time.Duration `json:"played"`
+	Left    time.Duration `json:"left"`
+	NextIn  time.Duration `json:"next_in"`
+	Pending int           `json:"pending_rollbacks"`
+
+	Stats   MeasurementValue `json:"stats"`
+	Cluster *ClusterState    `json:"cluster"`
+
+	// Journal is the copy of the game journal so far
+	Journal []JournalEntry `json:"-"`
+}
+
+// ClusterState is the cluster summary refreshed in the background during the
+// game, nil until the first refresh
+type ClusterState struct {
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Health string `json:"health"`
+	// Checks are the active health checks as `CODE: summary` sorted by code
+	Checks []string `json:"checks"`
+
+	PGsTotal int `json:"pgs_total"`
+	// PGStates maps the PG states to the number of PGs in them
+	PGStates map[string]int `json:"pg_states"`
+
+	OSDsTotal int `json:"osds_total"`
+	OSDsUp    int `json:"osds_up"`
+	OSDsIn    int `json:"osds_in"`
+}
+
+// UI is the printer which also shows the live state of the game. All the game
+// output is printed through it so the plain printer is used if it's not set.
+type UI interface {
+	Printer
+
+	Update(GameState)
+
+	// Close is called once the game is over, the report is printed
+	// afterwards
+	Close()
+}
+
+// WithUI shows the live state of the game with ui, it's the game printer as
+// well
+func WithUI(ui UI) Option {
+	return func(m *monkey) {
+		m.ui = ui
+		m.printer = ui
+	}
+}
+
+// watchCluster refreshes the cluster state for the UI until ctx is done. It's
+// run besides the game loop so the slow cluster commands don't delay the game.
+func (m *monkey) watchCluster(ctx context.Context, state *atomic.Pointer[ClusterState]) {
+	ticker := time.NewTicker(clusterRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		s := fetchClusterState(ctx, m.cluster)
+		state.Store(&s)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fetchClusterState leaves the parts of the state failed to be fetched
+// empty, the cluster under the fuss could be barely responding
+func fetchClusterState(ctx context.Context, cluster drivers.Cluster) ClusterState {
+	s := ClusterState{
+		UpdatedAt: time.Now(),
+		Health:    "unknown",
+		PGStates:  map[string]int{},
+	}
+
+	if health, err := cluster.GetHealth(ctx); err != nil {
+		log.Debugf("error getting health: %s", err)
+	} else {
+		s.Health = health.Status
+		for code, check := range health.Checks {
+			s.Checks = append(s.Checks, fmt.Sprintf("%s: %s", code, check.Summary.Message))
+		}
+		sort.Strings(s.Checks)
+	}
+
+	if pgs, err := cluster.ListPGs(ctx); err != nil {
+		log.Debugf("error listing PGs: %s", err)
+	} else {
+		s.PGsTotal = len(pgs)
+		for _, pg := range pgs {
+			s.PGStates[pg.State]++
+		}
+	}
+
+	if osds, err := cluster.GetOSDs(ctx); err != nil {
+		log.Debugf("error getting OSDs: %s", err)
+	} else {
+		s.OSDsTotal = len(osds)
+		for _, osd := range osds {
+			for _, st := range osd.State {
+				if st == "up" {
+					s.OSDsUp++
+				}
+			}
+
+			// the OSD marked out has zero reweight
+			if osd.Reweight > 0 {
+				s.OSDsIn++
+			}
+		}
+	}
+
+	return s
+}
+
+// nextFussIn is the time till the next tick the fuss could be triggered at,
+// the ticks during the warmup are skipped
+func (m *monkey) nextFussIn(now time.Time) time.Duration {
+	next := m.nextTickAt
+	warmedUp := m.startedAt.Add(m.level.Warmup)
+	for next.Before(warmedUp) {
+		next = next.Add(m.interval)
+	}
+	return max(next.Sub(now), 0)
+}
+
+func (m *monkey) updateUI(cluster *ClusterState) {
+	now := time.Now()
+
+	m.ui.Update(GameState{
+		At:      now,
+		Level:   m.level.Level,
+		Paused:  m.paused,
+		Played:  m.gameElapsed(),
+		Left:    m.timeLeft(),
+		NextIn:  m.nextFussIn(now),
+		Pending: len(m.rollbacks),
+		Stats:   m.stats.Dump(),
+		Cluster: cluster,
+		Journal: slices.Clone(m.journal),
+	})
+}
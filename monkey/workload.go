@@ -0,0 +1,56 @@
+package monkey
+
+import (
+	"context"
+	"time"
+)
+
+// Workload is a background load running during the game along with the
+// RADOS one so the stats show how the fusses affect other Ceph interfaces
+type Workload interface {
+	Name() string
+	Run(ctx context.Context, stats Stats) error
+}
+
+type workloadRun struct {
+	workload Workload
+	stats    Stats
+}
+
+// doWithTimeout runs the operation with the timeout and reports its result to
+// the observer. The operations interrupted by the end of the game are not
+// reported.
+func doWithTimeout(ctx context.Context, timeout time.Duration, observe func(time.Duration, error), fn func(context.Context) error) error {
+	opCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(opCtx)
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	observe(time.Since(start), err)
+	return err
+}
+
+// runWorkloadOps performs the operations one at a time till ctx is done. op
+// returns false if it's failed or had nothing to do so the loop backs off
+// instead of spinning while the service is down.
+func runWorkloadOps(ctx context.Context, op func() bool) error {
+	backoff := &idleBackoff{}
+
+	for {
+		err := ctx.Err()
+		if err == nil {
+			err = backoff.wait(ctx, op())
+		}
+
+		if err != nil {
+			if err != context.DeadlineExceeded {
+				return err
+			}
+			return nil
+		}
+	}
+}
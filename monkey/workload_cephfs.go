@@ -0,0 +1,220 @@
+package monkey
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/teran/go-collection/random"
+)
+
+const (
+	cephFSWorkloadOpTimeout   = 30 * time.Second
+	cephFSWorkloadMaxFiles    = 256
+	cephFSWorkloadMaxFileSize = 1024 * 1024
+)
+
+var _ Workload = (*cephFSWorkload)(nil)
+
+type cephFSWorkload struct {
+	root string
+	rnd  random.Random
+
+	files []string
+
+	// inFlight is closed once the last operation returns
+	inFlight chan struct{}
+}
+
+// NewCephFSWorkload creates the workload performing file and metadata
+// operations within the directory which is supposed to be on the mounted
+// CephFS
+func NewCephFSWorkload(root string, rnd random.Random) Workload {
+	return &cephFSWorkload{
+		root: root,
+		rnd:  rnd,
+	}
+}
+
+func (w *cephFSWorkload) Name() string {
+	return "CephFS"
+}
+
+func (w *cephFSWorkload) Run(ctx context.Context, stats Stats) error {
+	var dir string
+
+	err := w.do(ctx, stats.ObserveMetadata, func() (err error) {
+		dir, err = os.MkdirTemp(w.root, artifactPrefix+"*")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return runWorkloadOps(ctx, func() bool {
+		switch w.rnd.Intn(4) {
+		case 0:
+			return w.write(ctx, dir, stats)
+		case 1:
+			return w.read(ctx, dir, stats)
+		case 2:
+			return w.metadata(ctx, dir, stats)
+		default:
+			return w.unlink(ctx, dir, stats)
+		}
+	})
+}
+
+func (w *cephFSWorkload) write(ctx context.Context, dir string, stats Stats) bool {
+	if len(w.files) >= cephFSWorkloadMaxFiles {
+		return w.unlink(ctx, dir, stats)
+	}
+
+	buf := make([]byte, w.rnd.Intn(cephFSWorkloadMaxFileSize)+1)
+	if _, err := w.rnd.Read(buf); err != nil {
+		return false
+	}
+
+	sum := sha256.Sum256(buf)
+	name := hex.EncodeToString(sum[:])
+
+	err := w.do(ctx, stats.ObserveWrite, func() error {
+		tmp := filepath.Join(dir, name+".tmp")
+		if err := os.WriteFile(tmp, buf, 0o644); err != nil {
+			return err
+		}
+		return os.Rename(tmp, filepath.Join(dir, name))
+	})
+
+	if err != nil {
+		return false
+	}
+
+	w.files = append(w.files, name)
+	return true
+}
+
+// read and unlink write a file instead if there's none so the workload is
+// never idle
+func (w *cephFSWorkload) read(ctx context.Context, dir string, stats Stats) bool {
+	if len(w.files) == 0 {
+		return w.write(ctx, dir, stats)
+	}
+
+	name := w.files[w.rnd.Intn(len(w.files))]
+
+	err := w.do(ctx, stats.ObserveRead, func() error {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != name {
+			return errors.New("checksum mismatch for file " + name)
+		}
+		return nil
+	})
+	return err == nil
+}
+
+func (w *cephFSWorkload) metadata(ctx context.Context, dir string, stats Stats) bool {
+	subdir := filepath.Join(dir, "dir-"+strconv.Itoa(w.rnd.Int()))
+
+	ops := []func() error{
+		func() error {
+			_, err := os.ReadDir(dir)
+			return err
+		},
+		func() error {
+			if err := os.Mkdir(subdir, 0o755); err != nil {
+				return err
+			}
+			return os.Remove(subdir)
+		},
+	}
+
+	if len(w.files) > 0 {
+		name := filepath.Join(dir, w.files[w.rnd.Intn(len(w.files))])
+
+		ops = append(ops,
+			func() error {
+				_, err := os.Stat(name)
+				return err
+			},
+			func() error {
+				if err := os.Rename(name, name+".renamed"); err != nil {
+					return err
+				}
+				return os.Rename(name+".renamed", name)
+			},
+		)
+	}
+
+	return w.do(ctx, stats.ObserveMetadata, ops[w.rnd.Intn(len(ops))]) == nil
+}
+
+func (w *cephFSWorkload) unlink(ctx context.Context, dir string, stats Stats) bool {
+	if len(w.files) == 0 {
+		return w.write(ctx, dir, stats)
+	}
+
+	idx := w.rnd.Intn(len(w.files))
+	name := w.files[idx]
+
+	err := w.do(ctx, stats.ObserveMetadata, func() error {
+		return os.Remove(filepath.Join(dir, name))
+	})
+
+	if err == nil || errors.Is(err, os.ErrNotExist) {
+		w.files = append(w.files[:idx], w.files[idx+1:]...)
+	}
+	return err == nil
+}
+
+// do runs the operation and reports its result to the observer. It gives up
+// on waiting for the operation after the timeout since the operations on
+// unavailable CephFS could hang for a long time. The operation given up on is
+// still waited for before the next one is started so the threads stuck in the
+// syscalls don't pile up. The operations interrupted by the end of the game
+// are not reported.
+func (w *cephFSWorkload) do(ctx context.Context, observe func(time.Duration, error), fn func() error) error {
+	if w.inFlight != nil {
+		select {
+		case <-w.inFlight:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx, cephFSWorkloadOpTimeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	w.inFlight = done
+
+	errCh := make(chan error, 1)
+	start := time.Now()
+	go func() {
+		defer close(done)
+		errCh <- fn()
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-opCtx.Done():
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		err = opCtx.Err()
+	}
+
+	observe(time.Since(start), err)
+	return err
+}
@@ -0,0 +1,95 @@
+package monkey
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teran/go-collection/random"
+)
+
+func TestCephFSWorkload(t *testing.T) {
+	r := require.New(t)
+
+	root := t.TempDir()
+	stats := NewStats()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	w := NewCephFSWorkload(root, random.GetRand())
+	r.Equal("CephFS", w.Name())
+	r.NoError(w.Run(ctx, stats))
+
+	s := stats.Dump()
+	r.NotZero(s.WritesCountTotal)
+	r.NotZero(s.MetadataOpsCountTotal)
+	r.Zero(s.WritesErrorsTotal)
+	r.Zero(s.ReadsErrorsTotal)
+	r.Zero(s.MetadataOpsErrorsTotal)
+
+	entries, err := os.ReadDir(root)
+	r.NoError(err)
+	r.Len(entries, 1)
+	r.True(entries[0].IsDir())
+}
+
+func TestCephFSWorkloadCorruptedFile(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	stats := NewStats()
+
+	w := &cephFSWorkload{root: dir, rnd: random.GetRand(), files: []string{"not-a-checksum"}}
+	r.NoError(os.WriteFile(filepath.Join(dir, "not-a-checksum"), []byte("data"), 0o644))
+
+	w.read(context.Background(), dir, stats)
+	r.Equal(uint64(1), stats.Dump().ReadsErrorsTotal)
+}
+
+func TestCephFSWorkloadUnavailable(t *testing.T) {
+	r := require.New(t)
+
+	stats := NewStats()
+
+	w := NewCephFSWorkload(filepath.Join(t.TempDir(), "not-mounted"), random.GetRand())
+	r.Error(w.Run(context.Background(), stats))
+	r.Equal(uint64(1), stats.Dump().MetadataOpsErrorsTotal)
+}
+
+func TestCephFSWorkloadOneOpInFlight(t *testing.T) {
+	r := require.New(t)
+
+	w := &cephFSWorkload{root: t.TempDir(), rnd: random.GetRand()}
+	observe := func(time.Duration, error) {}
+
+	// the first operation hangs and is given up on by the end of the game
+	release := make(chan struct{})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	r.ErrorIs(w.do(ctx, observe, func() error {
+		<-release
+		return nil
+	}), context.DeadlineExceeded)
+
+	started := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- w.do(context.Background(), observe, func() error {
+			close(started)
+			return nil
+		})
+	}()
+
+	select {
+	case <-started:
+		r.Fail("the operation is started while the previous one is in flight")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	r.NoError(<-errCh)
+}
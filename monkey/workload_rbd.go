@@ -0,0 +1,224 @@
+package monkey
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/teran/go-collection/random"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+	"github.com/teran/ceph-chaos-monkey/ceph/drivers"
+)
+
+const (
+	rbdWorkloadOpTimeout    = 30 * time.Second
+	rbdWorkloadImageSizeMiB = 16
+	rbdWorkloadBlockSize    = 64 * 1024
+	rbdWorkloadMaxSnapshots = 4
+
+	rbdWorkloadBaseImage    = artifactPrefix + "rbd-base"
+	rbdWorkloadBaseSnapshot = "base"
+	rbdWorkloadImage        = artifactPrefix + "rbd"
+)
+
+var _ Workload = (*rbdWorkload)(nil)
+
+type rbdWorkload struct {
+	cluster drivers.Cluster
+	pool    string
+	rnd     random.Random
+
+	// blocks holds checksums of the blocks written by offset
+	blocks    map[uint64]string
+	snapshots []string
+	snapSeq   int
+}
+
+// newRBDWorkload creates the workload writing and verifying blocks of the
+// image cloned in the pool so image-level fusses like flatten have effect
+func newRBDWorkload(cluster drivers.Cluster, pool string, rnd random.Random) Workload {
+	return &rbdWorkload{
+		cluster: cluster,
+		pool:    pool,
+		rnd:     rnd,
+		blocks:  map[uint64]string{},
+	}
+}
+
+func (w *rbdWorkload) Name() string {
+	return "RBD"
+}
+
+func (w *rbdWorkload) Run(ctx context.Context, stats Stats) error {
+	err := doWithTimeout(ctx, rbdWorkloadOpTimeout, stats.ObserveMetadata, w.setup)
+	if err != nil {
+		return err
+	}
+
+	return runWorkloadOps(ctx, func() bool {
+		switch w.rnd.Intn(3) {
+		case 0:
+			return w.write(ctx, stats)
+		case 1:
+			return w.read(ctx, stats)
+		default:
+			return w.snapshot(ctx, stats)
+		}
+	})
+}
+
+// setup creates the clone of the base image snapshot skipping the steps done
+// already since the images are left in the pool of the resumed game. The
+// blocks written before are not verified since their checksums are lost.
+func (w *rbdWorkload) setup(ctx context.Context) error {
+	images, err := w.cluster.ListRBDImages(ctx, w.pool)
+	if err != nil {
+		return err
+	}
+
+	if slices.Contains(images, rbdWorkloadImage) {
+		return w.adoptSnapshots(ctx)
+	}
+
+	if !slices.Contains(images, rbdWorkloadBaseImage) {
+		if err := w.cluster.CreateRBDImage(ctx, w.pool, rbdWorkloadBaseImage, rbdWorkloadImageSizeMiB); err != nil {
+			return err
+		}
+	}
+
+	snapshots, err := w.cluster.ListRBDSnapshots(ctx, w.pool, rbdWorkloadBaseImage)
+	if err != nil {
+		return err
+	}
+
+	idx := slices.IndexFunc(snapshots, func(s ceph.RBDSnapshot) bool {
+		return s.Name == rbdWorkloadBaseSnapshot
+	})
+	if idx < 0 {
+		if err := w.cluster.CreateRBDSnapshot(ctx, w.pool, rbdWorkloadBaseImage, rbdWorkloadBaseSnapshot); err != nil {
+			return err
+		}
+	}
+
+	if idx < 0 || !snapshots[idx].IsProtected() {
+		if err := w.cluster.ProtectRBDSnapshot(ctx, w.pool, rbdWorkloadBaseImage, rbdWorkloadBaseSnapshot); err != nil {
+			return err
+		}
+	}
+
+	return w.cluster.CloneRBDImage(ctx, w.pool, rbdWorkloadBaseImage, rbdWorkloadBaseSnapshot, rbdWorkloadImage)
+}
+
+// adoptSnapshots picks up the snapshots of the existing clone so they're
+// rotated and the new ones don't collide with them
+func (w *rbdWorkload) adoptSnapshots(ctx context.Context) error {
+	snapshots, err := w.cluster.ListRBDSnapshots(ctx, w.pool, rbdWorkloadImage)
+	if err != nil {
+		return err
+	}
+
+	seqs := []int{}
+	for _, s := range snapshots {
+		suffix, ok := strings.CutPrefix(s.Name, artifactPrefix)
+		if !ok {
+			continue
+		}
+
+		seq, err := strconv.Atoi(suffix)
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+	slices.Sort(seqs)
+
+	for _, seq := range seqs {
+		w.snapshots = append(w.snapshots, artifactPrefix+strconv.Itoa(seq))
+		w.snapSeq = seq
+	}
+	return nil
+}
+
+func (w *rbdWorkload) write(ctx context.Context, stats Stats) bool {
+	offset := uint64(w.rnd.Intn(rbdWorkloadImageSizeMiB*1024*1024/rbdWorkloadBlockSize)) * rbdWorkloadBlockSize
+
+	buf := make([]byte, rbdWorkloadBlockSize)
+	if _, err := w.rnd.Read(buf); err != nil {
+		return false
+	}
+
+	err := doWithTimeout(ctx, rbdWorkloadOpTimeout, stats.ObserveWrite, func(ctx context.Context) error {
+		return w.cluster.WriteRBDImage(ctx, w.pool, rbdWorkloadImage, offset, buf)
+	})
+	if err != nil {
+		// the block contents is unknown after the failed write
+		delete(w.blocks, offset)
+		return false
+	}
+
+	sum := sha256.Sum256(buf)
+	w.blocks[offset] = hex.EncodeToString(sum[:])
+	return true
+}
+
+// read exports the image and verifies all the blocks written so far, the
+// block is written instead if there's none
+func (w *rbdWorkload) read(ctx context.Context, stats Stats) bool {
+	if len(w.blocks) == 0 {
+		return w.write(ctx, stats)
+	}
+
+	err := doWithTimeout(ctx, rbdWorkloadOpTimeout, stats.ObserveRead, func(ctx context.Context) error {
+		data, err := w.cluster.ExportRBDImage(ctx, w.pool, rbdWorkloadImage)
+		if err != nil {
+			return err
+		}
+
+		for _, offset := range slices.Sorted(maps.Keys(w.blocks)) {
+			if uint64(len(data)) < offset+rbdWorkloadBlockSize {
+				return fmt.Errorf("block at offset %d is missing", offset)
+			}
+
+			sum := sha256.Sum256(data[offset : offset+rbdWorkloadBlockSize])
+			if hex.EncodeToString(sum[:]) != w.blocks[offset] {
+				return fmt.Errorf("checksum mismatch for block at offset %d", offset)
+			}
+		}
+		return nil
+	})
+	return err == nil
+}
+
+func (w *rbdWorkload) snapshot(ctx context.Context, stats Stats) bool {
+	if len(w.snapshots) >= rbdWorkloadMaxSnapshots {
+		name := w.snapshots[0]
+		// the snapshot is forgotten even if removal failed since it could be
+		// already removed by the fuss
+		w.snapshots = w.snapshots[1:]
+
+		err := doWithTimeout(ctx, rbdWorkloadOpTimeout, stats.ObserveMetadata, func(ctx context.Context) error {
+			return w.cluster.RemoveRBDSnapshot(ctx, w.pool, rbdWorkloadImage, name)
+		})
+		return err == nil
+	}
+
+	w.snapSeq++
+	name := artifactPrefix + strconv.Itoa(w.snapSeq)
+
+	err := doWithTimeout(ctx, rbdWorkloadOpTimeout, stats.ObserveMetadata, func(ctx context.Context) error {
+		return w.cluster.CreateRBDSnapshot(ctx, w.pool, rbdWorkloadImage, name)
+	})
+	if err != nil {
+		return false
+	}
+
+	w.snapshots = append(w.snapshots, name)
+	return true
+}
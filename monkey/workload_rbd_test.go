@@ -0,0 +1,133 @@
+package monkey
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/teran/go-collection/random"
+
+	"github.com/teran/ceph-chaos-monkey/ceph"
+)
+
+func (s *cephTestSuite) TestRBDWorkloadWriteAndRead() {
+	image := make([]byte, rbdWorkloadImageSizeMiB*1024*1024)
+	stats := NewStats()
+
+	w := newRBDWorkload(s.cluster, "pool", random.GetRand()).(*rbdWorkload)
+	s.Require().Equal("RBD", w.Name())
+
+	s.cluster.On("WriteRBDImage", "pool", rbdWorkloadImage, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		copy(image[args.Get(2).(uint64):], args.Get(3).([]byte))
+	}).Return(nil).Times(3)
+
+	for range 3 {
+		w.write(s.ctx, stats)
+	}
+	s.Require().NotEmpty(w.blocks)
+
+	s.cluster.On("ExportRBDImage", "pool", rbdWorkloadImage).Return(image, nil).Once()
+	w.read(s.ctx, stats)
+	s.Require().Equal(uint64(1), stats.Dump().ReadsCountTotal)
+	s.Require().Zero(stats.Dump().ReadsErrorsTotal)
+
+	for offset := range w.blocks {
+		image[offset] ^= 0xff
+	}
+
+	s.cluster.On("ExportRBDImage", "pool", rbdWorkloadImage).Return(image, nil).Once()
+	w.read(s.ctx, stats)
+	s.Require().Equal(uint64(1), stats.Dump().ReadsErrorsTotal)
+
+	s.cluster.On("ExportRBDImage", "pool", rbdWorkloadImage).Return([]byte{}, nil).Once()
+	w.read(s.ctx, stats)
+	s.Require().Equal(uint64(2), stats.Dump().ReadsErrorsTotal)
+}
+
+func (s *cephTestSuite) TestRBDWorkloadFailedWrite() {
+	stats := NewStats()
+
+	w := newRBDWorkload(s.cluster, "pool", s.rnd).(*rbdWorkload)
+	w.blocks[0] = "checksum"
+
+	s.rnd.On("Intn", rbdWorkloadImageSizeMiB*1024*1024/rbdWorkloadBlockSize).Return(0).Once()
+	s.rnd.On("Read").Return([]byte("data"), 4, nil).Once()
+	s.cluster.On("WriteRBDImage", "pool", rbdWorkloadImage, uint64(0), mock.Anything).Return(errors.New("test error")).Once()
+
+	w.write(s.ctx, stats)
+	s.Require().Empty(w.blocks)
+	s.Require().Equal(uint64(1), stats.Dump().WritesErrorsTotal)
+}
+
+func (s *cephTestSuite) TestRBDWorkloadSnapshots() {
+	stats := NewStats()
+
+	w := newRBDWorkload(s.cluster, "pool", s.rnd).(*rbdWorkload)
+
+	for i := 1; i <= rbdWorkloadMaxSnapshots; i++ {
+		s.cluster.On("CreateRBDSnapshot", "pool", rbdWorkloadImage, artifactPrefix+strconv.Itoa(i)).Return(nil).Once()
+		w.snapshot(s.ctx, stats)
+	}
+	s.Require().Len(w.snapshots, rbdWorkloadMaxSnapshots)
+
+	s.cluster.On("RemoveRBDSnapshot", "pool", rbdWorkloadImage, artifactPrefix+"1").Return(errors.New("not found")).Once()
+	w.snapshot(s.ctx, stats)
+	s.Require().Len(w.snapshots, rbdWorkloadMaxSnapshots-1)
+	s.Require().Equal(uint64(1), stats.Dump().MetadataOpsErrorsTotal)
+}
+
+func (s *cephTestSuite) TestRBDWorkloadSetupFailed() {
+	stats := NewStats()
+
+	w := newRBDWorkload(s.cluster, "pool", s.rnd)
+
+	s.cluster.On("ListRBDImages", "pool").Return([]string{}, nil).Once()
+	s.cluster.On("CreateRBDImage", "pool", rbdWorkloadBaseImage, uint64(rbdWorkloadImageSizeMiB)).Return(nil).Once()
+	s.cluster.On("ListRBDSnapshots", "pool", rbdWorkloadBaseImage).Return([]ceph.RBDSnapshot{}, nil).Once()
+	s.cluster.On("CreateRBDSnapshot", "pool", rbdWorkloadBaseImage, rbdWorkloadBaseSnapshot).Return(nil).Once()
+	s.cluster.On("ProtectRBDSnapshot", "pool", rbdWorkloadBaseImage, rbdWorkloadBaseSnapshot).Return(nil).Once()
+	s.cluster.On("CloneRBDImage", "pool", rbdWorkloadBaseImage, rbdWorkloadBaseSnapshot, rbdWorkloadImage).Return(errors.New("test error")).Once()
+
+	s.Require().Error(w.Run(s.ctx, stats))
+	s.Require().Equal(uint64(1), stats.Dump().MetadataOpsErrorsTotal)
+}
+
+func (s *cephTestSuite) TestRBDWorkloadSetupInterrupted() {
+	w := newRBDWorkload(s.cluster, "pool", s.rnd).(*rbdWorkload)
+
+	s.cluster.On("ListRBDImages", "pool").Return([]string{rbdWorkloadBaseImage}, nil).Once()
+	s.cluster.On("ListRBDSnapshots", "pool", rbdWorkloadBaseImage).Return([]ceph.RBDSnapshot{
+		{Name: rbdWorkloadBaseSnapshot, Protected: "false"},
+	}, nil).Once()
+	s.cluster.On("ProtectRBDSnapshot", "pool", rbdWorkloadBaseImage, rbdWorkloadBaseSnapshot).Return(nil).Once()
+	s.cluster.On("CloneRBDImage", "pool", rbdWorkloadBaseImage, rbdWorkloadBaseSnapshot, rbdWorkloadImage).Return(nil).Once()
+
+	s.Require().NoError(w.setup(s.ctx))
+}
+
+func (s *cephTestSuite) TestRBDWorkloadSetupResumed() {
+	stats := NewStats()
+
+	w := newRBDWorkload(s.cluster, "pool", s.rnd).(*rbdWorkload)
+
+	s.cluster.On("ListRBDImages", "pool").Return([]string{rbdWorkloadBaseImage, rbdWorkloadImage}, nil).Once()
+	s.cluster.On("ListRBDSnapshots", "pool", rbdWorkloadImage).Return([]ceph.RBDSnapshot{
+		{Name: artifactPrefix + "10"},
+		{Name: artifactPrefix + "9"},
+		{Name: "manual"},
+	}, nil).Once()
+
+	s.Require().NoError(w.setup(s.ctx))
+	s.Require().Equal([]string{artifactPrefix + "9", artifactPrefix + "10"}, w.snapshots)
+	s.Require().Empty(w.blocks)
+
+	s.cluster.On("CreateRBDSnapshot", "pool", rbdWorkloadImage, artifactPrefix+"11").Return(nil).Once()
+	s.Require().True(w.snapshot(s.ctx, stats))
+
+	// the block is written instead of verifying the blocks written before
+	s.rnd.On("Intn", rbdWorkloadImageSizeMiB*1024*1024/rbdWorkloadBlockSize).Return(0).Once()
+	s.rnd.On("Read").Return([]byte("data"), 4, nil).Once()
+	s.cluster.On("WriteRBDImage", "pool", rbdWorkloadImage, uint64(0), mock.Anything).Return(nil).Once()
+	s.Require().True(w.read(s.ctx, stats))
+	s.Require().Len(w.blocks, 1)
+}
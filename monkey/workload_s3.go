@@ -0,0 +1,142 @@
+package monkey
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/teran/go-collection/random"
+
+	"github.com/teran/ceph-chaos-monkey/s3"
+)
+
+const (
+	s3WorkloadOpTimeout     = 30 * time.Second
+	s3WorkloadMaxObjects    = 256
+	s3WorkloadMaxObjectSize = 1024 * 1024
+	s3WorkloadObjectsPrefix = artifactPrefix
+)
+
+var _ Workload = (*s3Workload)(nil)
+
+// S3WorkloadConfig is the RADOS Gateway bucket the S3 workload is run against
+type S3WorkloadConfig struct {
+	Endpoint  string `json:"endpoint"`
+	Region    string `json:"region"`
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
+	Bucket    string `json:"bucket"`
+}
+
+type s3Workload struct {
+	client s3.Client
+	bucket string
+	rnd    random.Random
+
+	objects []string
+}
+
+// NewS3Workload creates the workload putting, getting and deleting objects
+// in the bucket via S3 API to measure the object gateway availability
+func NewS3Workload(client s3.Client, bucket string, rnd random.Random) Workload {
+	return &s3Workload{
+		client: client,
+		bucket: bucket,
+		rnd:    rnd,
+	}
+}
+
+func (w *s3Workload) Name() string {
+	return "S3"
+}
+
+func (w *s3Workload) Run(ctx context.Context, stats Stats) error {
+	err := doWithTimeout(ctx, s3WorkloadOpTimeout, stats.ObserveMetadata, func(ctx context.Context) error {
+		return w.client.CreateBucket(ctx, w.bucket)
+	})
+	if err != nil {
+		return err
+	}
+
+	return runWorkloadOps(ctx, func() bool {
+		switch w.rnd.Intn(3) {
+		case 0:
+			return w.put(ctx, stats)
+		case 1:
+			return w.get(ctx, stats)
+		default:
+			return w.delete(ctx, stats)
+		}
+	})
+}
+
+func (w *s3Workload) put(ctx context.Context, stats Stats) bool {
+	if len(w.objects) >= s3WorkloadMaxObjects {
+		return w.delete(ctx, stats)
+	}
+
+	buf := make([]byte, w.rnd.Intn(s3WorkloadMaxObjectSize)+1)
+	if _, err := w.rnd.Read(buf); err != nil {
+		return false
+	}
+
+	sum := sha256.Sum256(buf)
+	key := s3WorkloadObjectsPrefix + hex.EncodeToString(sum[:])
+
+	// the op in flight is let to finish once the game is over so the objects
+	// tracked match the ones in the bucket
+	err := doWithTimeout(context.WithoutCancel(ctx), s3WorkloadOpTimeout, stats.ObserveWrite, func(ctx context.Context) error {
+		return w.client.PutObject(ctx, w.bucket, key, buf)
+	})
+	if err != nil {
+		return false
+	}
+
+	w.objects = append(w.objects, key)
+	return true
+}
+
+// get and delete put the object instead if there's none so the workload is
+// never idle
+func (w *s3Workload) get(ctx context.Context, stats Stats) bool {
+	if len(w.objects) == 0 {
+		return w.put(ctx, stats)
+	}
+
+	key := w.objects[w.rnd.Intn(len(w.objects))]
+
+	err := doWithTimeout(ctx, s3WorkloadOpTimeout, stats.ObserveRead, func(ctx context.Context) error {
+		data, err := w.client.GetObject(ctx, w.bucket, key)
+		if err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256(data)
+		if s3WorkloadObjectsPrefix+hex.EncodeToString(sum[:]) != key {
+			return errors.New("checksum mismatch for object " + key)
+		}
+		return nil
+	})
+	return err == nil
+}
+
+func (w *s3Workload) delete(ctx context.Context, stats Stats) bool {
+	if len(w.objects) == 0 {
+		return w.put(ctx, stats)
+	}
+
+	idx := w.rnd.Intn(len(w.objects))
+	key := w.objects[idx]
+
+	err := doWithTimeout(context.WithoutCancel(ctx), s3WorkloadOpTimeout, stats.ObserveDelete, func(ctx context.Context) error {
+		return w.client.DeleteObject(ctx, w.bucket, key)
+	})
+	if err != nil {
+		return false
+	}
+
+	w.objects = append(w.objects[:idx], w.objects[idx+1:]...)
+	return true
+}
@@ -0,0 +1,109 @@
+package monkey
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/teran/go-collection/random"
+
+	"github.com/teran/ceph-chaos-monkey/s3"
+	"github.com/teran/ceph-chaos-monkey/s3/fake"
+)
+
+func TestS3Workload(t *testing.T) {
+	r := require.New(t)
+
+	srv := fake.New("access-key")
+	defer srv.Close()
+
+	client, err := s3.New(srv.URL(), "us-east-1", "access-key", "secret-key")
+	r.NoError(err)
+
+	stats := NewStats()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	w := NewS3Workload(client, "bucket", random.GetRand())
+	r.Equal("S3", w.Name())
+	r.NoError(w.Run(ctx, stats))
+
+	s := stats.Dump()
+	r.NotZero(s.WritesCountTotal)
+	r.Zero(s.WritesErrorsTotal)
+	r.Zero(s.ReadsErrorsTotal)
+	r.Zero(s.MetadataOpsErrorsTotal)
+	r.Len(srv.Objects("bucket"), len(w.(*s3Workload).objects))
+}
+
+func TestS3WorkloadCorruptedObject(t *testing.T) {
+	r := require.New(t)
+
+	srv := fake.New("access-key")
+	defer srv.Close()
+
+	client, err := s3.New(srv.URL(), "us-east-1", "access-key", "secret-key")
+	r.NoError(err)
+
+	ctx := context.Background()
+	stats := NewStats()
+
+	w := &s3Workload{client: client, bucket: "bucket", rnd: random.GetRand()}
+	r.NoError(client.CreateBucket(ctx, "bucket"))
+
+	w.put(ctx, stats)
+	r.Len(w.objects, 1)
+
+	srv.Corrupt("bucket", w.objects[0], []byte("corrupted"))
+
+	w.get(ctx, stats)
+	r.Equal(uint64(1), stats.Dump().ReadsErrorsTotal)
+}
+
+func TestS3WorkloadUnavailable(t *testing.T) {
+	r := require.New(t)
+
+	srv := fake.New("access-key")
+	defer srv.Close()
+	srv.SetAvailable(false)
+
+	client, err := s3.New(srv.URL(), "us-east-1", "access-key", "secret-key")
+	r.NoError(err)
+
+	stats := NewStats()
+
+	w := NewS3Workload(client, "bucket", random.GetRand())
+	r.Error(w.Run(context.Background(), stats))
+	r.Equal(uint64(1), stats.Dump().MetadataOpsErrorsTotal)
+}
+
+func TestS3WorkloadBacksOffWhileUnavailable(t *testing.T) {
+	r := require.New(t)
+
+	srv := fake.New("access-key")
+	defer srv.Close()
+
+	client, err := s3.New(srv.URL(), "us-east-1", "access-key", "secret-key")
+	r.NoError(err)
+
+	stats := NewStats()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 400*time.Millisecond)
+	defer cancel()
+
+	// the gateway is stopped once the workload is started
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		srv.SetAvailable(false)
+	}()
+
+	w := NewS3Workload(client, "bucket", random.GetRand())
+	r.NoError(w.Run(ctx, stats))
+
+	s := stats.Dump()
+	errors := s.WritesErrorsTotal + s.ReadsErrorsTotal + s.DeletesErrorsTotal
+	r.NotZero(errors)
+	r.LessOrEqual(errors, uint64(10))
+}
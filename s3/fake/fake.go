@@ -0,0 +1,153 @@
+// Package fake implements in-memory S3 server to test the S3 clients against
+package fake
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// Server is the fake S3 server
+type Server struct {
+	mutex     *sync.RWMutex
+	server    *httptest.Server
+	accessKey string
+	available bool
+	buckets   map[string]map[string][]byte
+}
+
+// New starts the fake S3 server accepting requests signed with the access key
+func New(accessKey string) *Server {
+	s := &Server{
+		mutex:     &sync.RWMutex{},
+		accessKey: accessKey,
+		available: true,
+		buckets:   map[string]map[string][]byte{},
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+
+	return s
+}
+
+// URL returns the endpoint of the server
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Close shuts the server down
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// SetAvailable makes the server respond with 503 Service Unavailable to all
+// the requests when false is passed
+func (s *Server) SetAvailable(available bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.available = available
+}
+
+// Corrupt replaces the object data to emulate data corruption
+func (s *Server) Corrupt(bucket, key string, data []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if objects, ok := s.buckets[bucket]; ok {
+		if _, ok := objects[key]; ok {
+			objects[key] = data
+		}
+	}
+}
+
+// Objects returns the keys of objects in the bucket
+func (s *Server) Objects(bucket string) []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	keys := []string{}
+	for k := range s.buckets[bucket] {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.available {
+		writeError(w, http.StatusServiceUnavailable, "ServiceUnavailable")
+		return
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential="+s.accessKey+"/") {
+		writeError(w, http.StatusForbidden, "AccessDenied")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "IncompleteBody")
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+		writeError(w, http.StatusBadRequest, "XAmzContentSHA256Mismatch")
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if key == "" {
+		s.handleBucket(w, r, bucket)
+		return
+	}
+
+	objects, ok := s.buckets[bucket]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		objects[key] = body
+	case http.MethodGet:
+		data, ok := objects[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		_, _ = w.Write(data)
+	case http.MethodDelete:
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+func (s *Server) handleBucket(w http.ResponseWriter, r *http.Request, bucket string) {
+	if r.Method != http.MethodPut {
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+		return
+	}
+
+	if _, ok := s.buckets[bucket]; ok {
+		writeError(w, http.StatusConflict, "BucketAlreadyOwnedByYou")
+		return
+	}
+
+	s.buckets[bucket] = map[string][]byte{}
+}
+
+func writeError(w http.ResponseWriter, code int, s3Code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(code)
+	_, _ = io.WriteString(w, "<Error><Code>"+s3Code+"</Code></Error>")
+}
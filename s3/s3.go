@@ -0,0 +1,180 @@
+// Package s3 implements the minimal S3 client sufficient to run the
+// background workload against RADOS Gateway without pulling the whole SDK
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	amzDateFormat = "20060102T150405Z"
+	dateFormat    = "20060102"
+)
+
+// Client is the S3 client
+type Client interface {
+	CreateBucket(ctx context.Context, bucket string) error
+	PutObject(ctx context.Context, bucket, key string, data []byte) error
+	GetObject(ctx context.Context, bucket, key string) ([]byte, error)
+	DeleteObject(ctx context.Context, bucket, key string) error
+}
+
+// Error is returned on non-successful responses
+type Error struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("unexpected status code %d: %s", e.StatusCode, e.Body)
+}
+
+var _ Client = (*client)(nil)
+
+type client struct {
+	endpoint  *url.URL
+	region    string
+	accessKey string
+	secretKey string
+
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// New creates the client using path-style requests to the endpoint signed
+// with AWS Signature Version 4
+func New(endpoint, region, accessKey, secretKey string) (Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("endpoint must be an absolute URL, got `%s`", endpoint)
+	}
+
+	return &client{
+		endpoint:   u,
+		region:     region,
+		accessKey:  accessKey,
+		secretKey:  secretKey,
+		httpClient: &http.Client{},
+		now:        time.Now,
+	}, nil
+}
+
+func (c *client) CreateBucket(ctx context.Context, bucket string) error {
+	_, err := c.do(ctx, http.MethodPut, bucket, "", nil)
+	if e, ok := err.(*Error); ok && e.StatusCode == http.StatusConflict && strings.Contains(e.Body, "BucketAlreadyOwnedByYou") {
+		return nil
+	}
+	return err
+}
+
+func (c *client) PutObject(ctx context.Context, bucket, key string, data []byte) error {
+	_, err := c.do(ctx, http.MethodPut, bucket, key, data)
+	return err
+}
+
+func (c *client) GetObject(ctx context.Context, bucket, key string) ([]byte, error) {
+	return c.do(ctx, http.MethodGet, bucket, key, nil)
+}
+
+func (c *client) DeleteObject(ctx context.Context, bucket, key string) error {
+	_, err := c.do(ctx, http.MethodDelete, bucket, key, nil)
+	return err
+}
+
+func (c *client) do(ctx context.Context, method, bucket, key string, body []byte) ([]byte, error) {
+	u := *c.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+
+	c.sign(req, body)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &Error{StatusCode: resp.StatusCode, Body: string(data)}
+	}
+
+	return data, nil
+}
+
+func (c *client) sign(req *http.Request, body []byte) {
+	now := c.now().UTC()
+	payloadHash := hashHex(body)
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", now.Format(amzDateFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + now.Format(amzDateFormat),
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{now.Format(dateFormat), c.region, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		now.Format(amzDateFormat),
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.secretKey), now.Format(dateFormat))
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, scope, signedHeaders, hex.EncodeToString(hmacSHA256(key, stringToSign)),
+	))
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}